@@ -4,8 +4,10 @@ go 1.22
 
 require (
 	github.com/fogleman/gg v1.3.0
+	github.com/godbus/dbus/v5 v5.1.0
 	github.com/shirou/gopsutil/v3 v3.24.1
 	go.bug.st/serial v1.6.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
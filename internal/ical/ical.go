@@ -0,0 +1,317 @@
+// Package ical reads the events of iCalendar (.ics) files, as exported by
+// calendar applications, and expands their recurrences.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is an event, or one occurrence of a recurring event.
+type Event struct {
+	UID      string
+	Summary  string
+	Location string
+	Start    time.Time
+	End      time.Time
+	AllDay   bool // Start and End are midnights in the calendar's location
+
+	rule         *rule
+	exdates      map[int64]bool // Start times of excluded occurrences, Unix seconds
+	recurrenceID time.Time      // Occurrence of a recurring event this one replaces
+	cancelled    bool
+}
+
+// Calendar is a parsed calendar.
+type Calendar struct {
+	Events []Event
+
+	loc *time.Location // Location occurrences are returned in
+}
+
+// ParseFile parses the .ics file at path.
+func ParseFile(path string, loc *time.Location) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f, loc)
+}
+
+// Parse parses an iCalendar stream. Floating times and all-day dates are
+// taken to be in loc, as are times in zones the tz database doesn't know,
+// such as Windows zone names. Cancelled events are dropped.
+func Parse(r io.Reader, loc *time.Location) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &Calendar{loc: loc}
+	var ev *Event
+	for n, line := range lines {
+		name, params, value, ok := splitLine(line)
+		if !ok {
+			return nil, fmt.Errorf("line %d: malformed content line %q", n+1, line)
+		}
+		if name == "BEGIN" && value == "VEVENT" {
+			ev = &Event{}
+			continue
+		}
+		if ev == nil {
+			continue
+		}
+
+		switch name {
+		case "END":
+			if value != "VEVENT" {
+				continue
+			}
+			if ev.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q has no DTSTART", n+1, ev.Summary)
+			}
+			if ev.End.IsZero() {
+				ev.End = ev.Start
+				if ev.AllDay {
+					ev.End = ev.Start.AddDate(0, 0, 1)
+				}
+			}
+			// Cancelled overrides are kept to remove their occurrence
+			if !ev.cancelled || !ev.recurrenceID.IsZero() {
+				cal.Events = append(cal.Events, *ev)
+			}
+			ev = nil
+		case "UID":
+			ev.UID = value
+		case "SUMMARY":
+			ev.Summary = unescape(value)
+		case "LOCATION":
+			ev.Location = unescape(value)
+		case "STATUS":
+			ev.cancelled = value == "CANCELLED"
+		case "DTSTART":
+			ev.Start, ev.AllDay, err = parseTime(value, params, loc)
+		case "DTEND":
+			ev.End, _, err = parseTime(value, params, loc)
+		case "DURATION":
+			var d duration
+			if d, err = parseDuration(value); err == nil {
+				ev.End = d.addTo(ev.Start)
+			}
+		case "RRULE":
+			ev.rule, err = parseRule(value, loc)
+		case "EXDATE":
+			if ev.exdates == nil {
+				ev.exdates = make(map[int64]bool)
+			}
+			for _, v := range strings.Split(value, ",") {
+				var t time.Time
+				if t, _, err = parseTime(v, params, loc); err != nil {
+					break
+				}
+				ev.exdates[t.Unix()] = true
+			}
+		case "RECURRENCE-ID":
+			ev.recurrenceID, _, err = parseTime(value, params, loc)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", n+1, name, err)
+		}
+	}
+	return cal, nil
+}
+
+// unfold reads the content lines of r, joining continuation lines, which
+// start with a space or tab.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		switch {
+		case line == "":
+		case (line[0] == ' ' || line[0] == '\t') && len(lines) > 0:
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return lines, sc.Err()
+}
+
+// splitLine splits a content line, NAME;PARAM=VALUE:value, into its name,
+// parameters and value. Parameter values may be quoted to contain ':' or
+// ';'.
+func splitLine(line string) (name string, params map[string]string, value string, ok bool) {
+	colon, quoted := -1, false
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+	parts := strings.Split(line[:colon], ";")
+	params = make(map[string]string)
+	for _, p := range parts[1:] {
+		if k, v, found := strings.Cut(p, "="); found {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+// unescape decodes a TEXT value.
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// parseTime parses a DATE or DATE-TIME value, reporting whether it was a
+// date. UTC times are returned in loc.
+func parseTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t.In(loc), false, err
+	}
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// duration is an iCalendar duration. Days and weeks are kept apart from
+// the time part so that they add calendar days across DST changes.
+type duration struct {
+	days int
+	d    time.Duration
+}
+
+func (d duration) addTo(t time.Time) time.Time {
+	return t.AddDate(0, 0, d.days).Add(d.d)
+}
+
+// parseDuration parses a DURATION value such as PT30M, P1D or -P1W.
+func parseDuration(s string) (duration, error) {
+	var d duration
+	orig := s
+	sign := 1
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") {
+		return d, fmt.Errorf("invalid duration %q", orig)
+	}
+	s = s[1:]
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' {
+			inTime, s = true, s[1:]
+			continue
+		}
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			return d, fmt.Errorf("invalid duration %q", orig)
+		}
+		n, _ := strconv.Atoi(s[:i])
+		switch unit := s[i]; {
+		case unit == 'W' && !inTime:
+			d.days += 7 * n
+		case unit == 'D' && !inTime:
+			d.days += n
+		case unit == 'H' && inTime:
+			d.d += time.Duration(n) * time.Hour
+		case unit == 'M' && inTime:
+			d.d += time.Duration(n) * time.Minute
+		case unit == 'S' && inTime:
+			d.d += time.Duration(n) * time.Second
+		default:
+			return d, fmt.Errorf("invalid duration %q", orig)
+		}
+		s = s[i+1:]
+	}
+	d.days *= sign
+	d.d *= time.Duration(sign)
+	return d, nil
+}
+
+// Between returns the occurrences of events that overlap [from, to),
+// ordered by start time, with their times in the location the calendar was
+// parsed with. Recurrences are expanded in the event's own zone, so they
+// keep their wall-clock time there across DST changes. Occurrences moved
+// or cancelled by RECURRENCE-ID overrides are replaced by the overrides.
+func (c *Calendar) Between(from, to time.Time) []Event {
+	overridden := make(map[string]bool)
+	for _, ev := range c.Events {
+		if !ev.recurrenceID.IsZero() {
+			overridden[overrideKey(ev.UID, ev.recurrenceID)] = true
+		}
+	}
+
+	var events []Event
+	for _, ev := range c.Events {
+		if ev.rule == nil {
+			if !ev.cancelled && ev.Start.Before(to) && overlapsFrom(ev, from) {
+				events = append(events, ev)
+			}
+			continue
+		}
+		length := ev.End.Sub(ev.Start)
+		ev.rule.each(ev.Start, to, func(start time.Time) {
+			if ev.exdates[start.Unix()] || overridden[overrideKey(ev.UID, start)] {
+				return
+			}
+			occ := ev
+			occ.Start = start
+			occ.End = start.Add(length)
+			if ev.AllDay {
+				occ.End = start.AddDate(0, 0, int(length.Hours()/24+0.5))
+			}
+			if overlapsFrom(occ, from) {
+				occ.rule, occ.exdates = nil, nil
+				events = append(events, occ)
+			}
+		})
+	}
+	for i := range events {
+		if c.loc != nil {
+			events[i].Start, events[i].End = events[i].Start.In(c.loc), events[i].End.In(c.loc)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events
+}
+
+// overlapsFrom reports whether ev ends after from; zero-length events
+// overlap if they start at or after it.
+func overlapsFrom(ev Event, from time.Time) bool {
+	return ev.End.After(from) || (ev.End.Equal(ev.Start) && !ev.Start.Before(from))
+}
+
+func overrideKey(uid string, t time.Time) string {
+	return uid + "@" + strconv.FormatInt(t.Unix(), 10)
+}
@@ -0,0 +1,179 @@
+package ical
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//EN
+BEGIN:VTIMEZONE
+TZID:Europe/Berlin
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:standup
+SUMMARY:Standup
+DTSTART;TZID=Europe/Berlin:20261001T093000
+DTEND;TZID=Europe/Berlin:20261001T094500
+RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR
+EXDATE;TZID=Europe/Berlin:20261016T093000
+END:VEVENT
+BEGIN:VEVENT
+UID:standup
+RECURRENCE-ID;TZID=Europe/Berlin:20261019T093000
+SUMMARY:Standup (moved)
+DTSTART;TZID=Europe/Berlin:20261019T110000
+DTEND;TZID=Europe/Berlin:20261019T111500
+END:VEVENT
+BEGIN:VEVENT
+UID:standup
+RECURRENCE-ID;TZID=Europe/Berlin:20261021T093000
+STATUS:CANCELLED
+DTSTART;TZID=Europe/Berlin:20261021T093000
+END:VEVENT
+BEGIN:VEVENT
+UID:review
+SUMMARY:Design review\, round 2 with a long title that the exporter folded
+  over two lines
+LOCATION:Room 3
+DTSTART:20261019T130000Z
+DURATION:PT1H30M
+END:VEVENT
+BEGIN:VEVENT
+UID:offsite
+SUMMARY:Offsite
+DTSTART;VALUE=DATE:20261019
+DTEND;VALUE=DATE:20261021
+END:VEVENT
+BEGIN:VEVENT
+UID:retro
+SUMMARY:Retro
+DTSTART:20260130T150000
+DTEND:20260130T160000
+RRULE:FREQ=MONTHLY;BYDAY=-1FR
+END:VEVENT
+BEGIN:VEVENT
+UID:onboarding
+SUMMARY:Onboarding
+DTSTART:20261012T140000
+DTEND:20261012T150000
+RRULE:FREQ=DAILY;COUNT=5
+END:VEVENT
+BEGIN:VEVENT
+UID:lunch
+SUMMARY:Lunch
+STATUS:CANCELLED
+DTSTART:20261019T120000
+DTEND:20261019T130000
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestBetween(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	cal, err := Parse(strings.NewReader(strings.ReplaceAll(testCalendar, "\n", "\r\n")), berlin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, hh, mm int) time.Time { return time.Date(2026, 10, day, hh, mm, 0, 0, berlin) }
+
+	type occ struct {
+		Summary    string
+		Start, End time.Time
+	}
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []occ
+	}{
+		{"monday", at(19, 0, 0), at(20, 0, 0), []occ{
+			{"Offsite", at(19, 0, 0), at(21, 0, 0)},
+			{"Standup (moved)", at(19, 11, 0), at(19, 11, 15)},
+			{"Design review, round 2 with a long title that the exporter folded over two lines", at(19, 15, 0), at(19, 16, 30)},
+		}},
+		{"excluded friday", at(16, 0, 0), at(17, 0, 0), []occ{
+			{"Onboarding", at(16, 14, 0), at(16, 15, 0)},
+		}},
+		{"count ends", at(17, 0, 0), at(18, 0, 0), nil},
+		{"cancelled occurrence", at(21, 0, 0), at(22, 0, 0), nil},
+		{"last friday", at(30, 0, 0), at(31, 0, 0), []occ{
+			{"Standup", at(30, 9, 30), at(30, 9, 45)},
+			{"Retro", at(30, 15, 0), at(30, 16, 0)},
+		}},
+		{"ongoing", at(20, 9, 40), at(20, 10, 0), []occ{
+			{"Offsite", at(19, 0, 0), at(21, 0, 0)},
+			{"Standup", at(20, 9, 30), at(20, 9, 45)},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []occ
+			for _, ev := range cal.Between(tt.from, tt.to) {
+				got = append(got, occ{ev.Summary, ev.Start, ev.End})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Between() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBetweenInOtherZone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	cal, err := Parse(strings.NewReader(testCalendar), newYork)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Berlin leaves summer time on 25 October, New York on 1 November, so
+	// the standup moves from 03:30 to 04:30 in New York between them
+	tests := []struct {
+		day  int
+		want time.Time
+	}{
+		{20, time.Date(2026, 10, 20, 3, 30, 0, 0, newYork)},
+		{27, time.Date(2026, 10, 27, 4, 30, 0, 0, newYork)},
+	}
+	for _, tt := range tests {
+		from := time.Date(2026, 10, tt.day, 0, 0, 0, 0, newYork)
+		var starts []time.Time
+		for _, ev := range cal.Between(from, from.AddDate(0, 0, 1)) {
+			if ev.Summary == "Standup" {
+				starts = append(starts, ev.Start)
+			}
+		}
+		if len(starts) != 1 || starts[0] != tt.want {
+			t.Errorf("standup on %d October at %v, want %v", tt.day, starts, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		ics  string
+		want string
+	}{
+		{"no start", "BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n", "no DTSTART"},
+		{"bad line", "BEGIN:VEVENT\nSUMMARY\nEND:VEVENT\n", "line 2"},
+		{"bad rule", "BEGIN:VEVENT\nDTSTART:20261019T100000\nRRULE:FREQ=HOURLY\nEND:VEVENT\n", `"HOURLY"`},
+		{"bad duration", "BEGIN:VEVENT\nDTSTART:20261019T100000\nDURATION:1H\nEND:VEVENT\n", `"1H"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.ics), time.UTC)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want containing %s", err, tt.want)
+			}
+		})
+	}
+}
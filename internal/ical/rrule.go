@@ -0,0 +1,207 @@
+package ical
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds the expansion of a rule, against rules whose
+// occurrences never match.
+const maxPeriods = 100000
+
+// rule is a recurrence rule. FREQ, INTERVAL, COUNT, UNTIL, BYDAY and
+// BYMONTHDAY are supported, which covers what calendar applications write
+// for repeating meetings; other parts are ignored.
+type rule struct {
+	freq       string // DAILY, WEEKLY, MONTHLY or YEARLY
+	interval   int
+	count      int       // 0 for unlimited
+	until      time.Time // Zero for unlimited
+	byDay      []weekdayNum
+	byMonthDay []int
+}
+
+// weekdayNum is a BYDAY entry such as MO, or 2TU and -1FR for the second
+// Tuesday and last Friday of a month.
+type weekdayNum struct {
+	n   int
+	day time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseRule parses an RRULE value.
+func parseRule(value string, loc *time.Location) (*rule, error) {
+	r := &rule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		var err error
+		switch k {
+		case "FREQ":
+			r.freq = v
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(v)
+			if err == nil && r.interval < 1 {
+				err = fmt.Errorf("invalid interval %d", r.interval)
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(v)
+		case "UNTIL":
+			r.until, _, err = parseTime(v, nil, loc)
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				day, ok := weekdays[d[max(len(d)-2, 0):]]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %q", d)
+				}
+				wn := weekdayNum{day: day}
+				if len(d) > 2 {
+					if wn.n, err = strconv.Atoi(d[:len(d)-2]); err != nil {
+						return nil, fmt.Errorf("invalid BYDAY %q", d)
+					}
+				}
+				r.byDay = append(r.byDay, wn)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(v, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", d)
+				}
+				r.byMonthDay = append(r.byMonthDay, n)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return nil, fmt.Errorf("unsupported FREQ %q", r.freq)
+	}
+	return r, nil
+}
+
+// each calls fn with the start of each occurrence of an event starting at
+// start, in order, until an occurrence would start at or after to.
+func (r *rule) each(start, to time.Time, fn func(time.Time)) {
+	n := 0
+	for p := 0; p < maxPeriods; p++ {
+		for _, t := range r.period(start, p) {
+			if t.Before(start) {
+				continue
+			}
+			if !t.Before(to) || (!r.until.IsZero() && t.After(r.until)) {
+				return
+			}
+			if n++; r.count > 0 && n > r.count {
+				return
+			}
+			fn(t)
+		}
+	}
+}
+
+// period returns the candidate occurrences of the p-th period (day, week,
+// month or year) after start, in order.
+func (r *rule) period(start time.Time, p int) []time.Time {
+	y, m, d := start.Date()
+	hh, mm, ss := start.Clock()
+	loc := start.Location()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hh, mm, ss, 0, loc)
+	}
+
+	switch r.freq {
+	case "DAILY":
+		t := at(y, m, d+p*r.interval)
+		if len(r.byDay) > 0 && !r.onDay(t.Weekday()) {
+			return nil
+		}
+		return []time.Time{t}
+
+	case "WEEKLY":
+		// Weeks start on Monday
+		monday := d - (int(start.Weekday())+6)%7 + 7*p*r.interval
+		if len(r.byDay) == 0 {
+			return []time.Time{at(y, m, d+7*p*r.interval)}
+		}
+		var times []time.Time
+		for i := 0; i < 7; i++ {
+			if t := at(y, m, monday+i); r.onDay(t.Weekday()) {
+				times = append(times, t)
+			}
+		}
+		return times
+
+	case "MONTHLY":
+		first := at(y, m+time.Month(p*r.interval), 1)
+		fy, fm, _ := first.Date()
+		days := daysIn(fy, fm)
+		var mdays []int
+		switch {
+		case len(r.byDay) > 0:
+			for _, wn := range r.byDay {
+				// Days of the month on wn's weekday
+				var matches []int
+				for md := 1 + (int(wn.day)-int(first.Weekday())+7)%7; md <= days; md += 7 {
+					matches = append(matches, md)
+				}
+				switch {
+				case wn.n == 0:
+					mdays = append(mdays, matches...)
+				case wn.n > 0 && wn.n <= len(matches):
+					mdays = append(mdays, matches[wn.n-1])
+				case wn.n < 0 && -wn.n <= len(matches):
+					mdays = append(mdays, matches[len(matches)+wn.n])
+				}
+			}
+		case len(r.byMonthDay) > 0:
+			for _, md := range r.byMonthDay {
+				if md < 0 {
+					md += days + 1
+				}
+				if md >= 1 && md <= days {
+					mdays = append(mdays, md)
+				}
+			}
+		case d <= days:
+			mdays = []int{d}
+		}
+		sort.Ints(mdays)
+		times := make([]time.Time, len(mdays))
+		for i, md := range mdays {
+			times[i] = at(fy, fm, md)
+		}
+		return times
+
+	case "YEARLY":
+		// Skip years without the date, i.e. 29 February
+		if t := at(y+p*r.interval, m, d); t.Day() == d {
+			return []time.Time{t}
+		}
+	}
+	return nil
+}
+
+// onDay reports whether the rule's BYDAY includes day.
+func (r *rule) onDay(day time.Weekday) bool {
+	for _, wn := range r.byDay {
+		if wn.day == day {
+			return true
+		}
+	}
+	return false
+}
+
+// daysIn returns the number of days in a month.
+func daysIn(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
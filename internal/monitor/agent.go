@@ -10,18 +10,22 @@ import (
 	"github.com/aleksclark/go-turing-smart-screen/pkg/agentstat"
 )
 
-// Status colors for indicator circles.
-var statusColors = map[string]color.Color{
-	"idle":     color.RGBA{100, 100, 100, 255}, // Gray
-	"thinking": color.RGBA{255, 255, 0, 255},   // Yellow
-	"working":  color.RGBA{0, 255, 0, 255},     // Green
-	"waiting":  color.RGBA{0, 150, 255, 255},   // Blue
-	"error":    color.RGBA{255, 0, 0, 255},     // Red
-	"done":     color.RGBA{0, 255, 150, 255},   // Teal
-	"paused":   color.RGBA{255, 150, 0, 255},   // Orange
+func init() {
+	Register("agent", func() AgentOptions { return AgentOptions{Rows: 5} },
+		func(p Params, opts AgentOptions) (Monitor, error) {
+			if opts.Rows < 1 {
+				return nil, fmt.Errorf("rows: must be at least 1")
+			}
+			m := NewAgentMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+			m.setRows(opts.Rows)
+			return m, nil
+		})
 }
 
-var defaultStatusColor = color.RGBA{80, 80, 80, 255}
+// AgentOptions are the config options for the "agent" monitor.
+type AgentOptions struct {
+	Rows int `json:"rows"` // Agents shown at once
+}
 
 // AgentMonitor displays coding agent status.
 type AgentMonitor struct {
@@ -29,6 +33,7 @@ type AgentMonitor struct {
 	rowHeight   int
 	numRows     int
 	lastCleanup time.Time
+	tasks       []*Marquee
 }
 
 // NewAgentMonitor creates a new agent monitor.
@@ -40,15 +45,23 @@ func NewAgentMonitor(screen lcd.Screen, brightness int, interval time.Duration,
 
 	base := NewBase(Config{
 		Screen:   screen,
-		Colors:   DefaultColors(),
+		Theme:    DefaultTheme(),
 		Fonts:    fonts,
 		Interval: interval,
 		Logger:   logger,
 	})
 
-	return &AgentMonitor{
-		Base:    base,
-		numRows: 5,
+	m := &AgentMonitor{Base: base}
+	m.setRows(5)
+	return m
+}
+
+// setRows sets the number of agent rows.
+func (m *AgentMonitor) setRows(n int) {
+	m.numRows = n
+	m.tasks = nil
+	for i := 0; i < n; i++ {
+		m.tasks = append(m.tasks, NewMarquee(4, 40, 3))
 	}
 }
 
@@ -77,7 +90,7 @@ func (m *AgentMonitor) Run() error {
 	for m.Running() {
 		select {
 		case <-ticker.C:
-			if err := m.update(); err != nil {
+			if err := m.Tick(m.update); err != nil {
 				m.Logger().Error("update failed", "error", err)
 			}
 		}
@@ -102,7 +115,7 @@ func (m *AgentMonitor) setupLayout() {
 
 func (m *AgentMonitor) drawStatic() {
 	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
-	r := NewRenderer(dc, m.Colors(), m.fonts)
+	r := NewRenderer(dc, m.Theme(), m.fonts)
 
 	// Header separator
 	r.DrawLine(0, 32, float64(m.Width()))
@@ -128,7 +141,7 @@ func (m *AgentMonitor) update() error {
 	}
 
 	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
-	r := NewRenderer(dc, m.Colors(), m.fonts)
+	r := NewRenderer(dc, m.Theme(), m.fonts)
 
 	var updates []Region
 
@@ -184,12 +197,18 @@ func (m *AgentMonitor) update() error {
 				agent.CostUSD, agent.Stale)
 
 			if m.Changed(key, hash) {
-				m.renderAgentRow(r, reg, &agent)
+				m.renderAgentRow(r, reg, &agent, m.tasks[i])
 				updates = append(updates, reg)
+			} else if m.tasks[i].Tick() {
+				// Scroll long tasks without redrawing the rest of the row
+				taskReg := m.taskRegion(reg, &agent)
+				r.DrawMarquee(taskReg, m.tasks[i], m.fonts.Small, m.rowTextColor(&agent))
+				updates = append(updates, taskReg)
 			}
 		} else {
 			key := fmt.Sprintf("agent_%d", i)
 			if m.Changed(key, "empty") {
+				m.tasks[i].SetText("")
 				r.Clear(reg)
 				updates = append(updates, reg)
 			}
@@ -210,29 +229,40 @@ func (m *AgentMonitor) update() error {
 	return nil
 }
 
-func (m *AgentMonitor) renderAgentRow(r *Renderer, reg Region, agent *agentstat.Status) {
-	r.Clear(reg)
-
+// rowTextColor returns the primary text color for an agent row.
+func (m *AgentMonitor) rowTextColor(agent *agentstat.Status) color.Color {
 	// Dim colors for stale agents
-	textColor := m.Colors().Text
 	if agent.Stale {
-		textColor = m.Colors().TextDim
+		return m.Colors().TextDim
+	}
+	return m.Colors().Text
+}
+
+// taskRegion returns the region of the task line within an agent row.
+func (m *AgentMonitor) taskRegion(reg Region, agent *agentstat.Status) Region {
+	right := m.Width() - 5
+	if agent.Stale {
+		// Leave room for the age indicator
+		right -= 85
 	}
+	return Region{reg.X + 35, reg.Y + 16, right - (reg.X + 35), 16}
+}
+
+func (m *AgentMonitor) renderAgentRow(r *Renderer, reg Region, agent *agentstat.Status, task *Marquee) {
+	r.Clear(reg)
+
+	textColor := m.rowTextColor(agent)
 
 	// Status indicator circle
 	circleRadius := 8.0
 	circleX := float64(reg.X + 15)
 	circleY := float64(reg.Y) + float64(reg.H)/2
 
-	statusColor, ok := statusColors[agent.Status]
-	if !ok {
-		statusColor = defaultStatusColor
-	}
+	statusColor := m.Theme().StatusColor(agent.Status)
 	if agent.Stale {
 		// Dim the color
-		if c, ok := statusColor.(color.RGBA); ok {
-			statusColor = color.RGBA{c.R / 2, c.G / 2, c.B / 2, c.A}
-		}
+		r, g, b, a := statusColor.RGBA()
+		statusColor = color.RGBA64{uint16(r / 2), uint16(g / 2), uint16(b / 2), uint16(a)}
 	}
 	r.DrawCircle(circleX, circleY, circleRadius, statusColor)
 
@@ -240,16 +270,11 @@ func (m *AgentMonitor) renderAgentRow(r *Renderer, reg Region, agent *agentstat.
 	xText := float64(reg.X + 35)
 	title := agent.Agent
 	if agent.Project != "" {
-		proj := agent.Project
-		if len(proj) > 20 {
-			proj = proj[:20]
-		}
+		proj := r.TruncateText(agent.Project, 160, m.fonts.Normal, EllipsisMiddle)
 		title = fmt.Sprintf("%s • %s", agent.Agent, proj)
 	}
-	if len(title) > 35 {
-		title = title[:35]
-	}
-	r.DrawText(xText, float64(reg.Y), title, m.fonts.Normal, textColor)
+	titleW := float64(m.Width()-5-100-10) - xText
+	r.DrawTextFit(xText, float64(reg.Y), titleW, title, m.fonts.Normal, textColor, EllipsisEnd)
 
 	// Model (top right)
 	if agent.Model != "" {
@@ -258,27 +283,22 @@ func (m *AgentMonitor) renderAgentRow(r *Renderer, reg Region, agent *agentstat.
 		for _, prefix := range []string{"claude-", "gpt-", "-20250514"} {
 			model = removePrefix(model, prefix)
 		}
-		if len(model) > 20 {
-			model = model[:20]
-		}
-		r.DrawTextRight(float64(m.Width()-5-100), float64(reg.Y), 100, model, m.fonts.Small, m.Colors().TextDim)
+		r.DrawTextRightFit(float64(m.Width()-5-100), float64(reg.Y), 100, model, m.fonts.Small, m.Colors().TextDim, EllipsisEnd)
 	}
 
-	// Current task (middle row)
+	// Current task (middle row), scrolled by update when it overflows
+	task.SetText(agent.Task)
 	if agent.Task != "" {
-		task := agent.Task
-		if len(task) > 50 {
-			task = task[:50]
-		}
-		r.DrawText(xText, float64(reg.Y+16), task, m.fonts.Small, textColor)
+		r.DrawMarquee(m.taskRegion(reg, agent), task, m.fonts.Small, textColor)
 	}
 
 	// Tools info (bottom left)
+	toolsW := 180 - 5 - xText
 	if agent.Tools != nil {
 		if agent.Tools.Active != "" {
-			r.DrawText(xText, float64(reg.Y+30), "▶ "+agent.Tools.Active, m.fonts.Small, m.Colors().Header)
+			r.DrawTextFit(xText, float64(reg.Y+30), toolsW, "▶ "+agent.Tools.Active, m.fonts.Small, m.Colors().Header, EllipsisEnd)
 		} else if len(agent.Tools.Recent) > 0 {
-			r.DrawText(xText, float64(reg.Y+30), "◦ "+agent.Tools.Recent[len(agent.Tools.Recent)-1], m.fonts.Small, m.Colors().TextDim)
+			r.DrawTextFit(xText, float64(reg.Y+30), toolsW, "◦ "+agent.Tools.Recent[len(agent.Tools.Recent)-1], m.fonts.Small, m.Colors().TextDim, EllipsisEnd)
 		}
 	}
 
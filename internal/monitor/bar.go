@@ -0,0 +1,210 @@
+package monitor
+
+import (
+	"image/color"
+	"sort"
+)
+
+// Stop is a point on a Scale: fill percentages at or above At use Color.
+type Stop struct {
+	At    float64 // Percentage of the bar's range, 0-100
+	Color color.Color
+}
+
+// Scale maps a bar's fill percentage to a color. Stops may be given in any
+// order, so a scale can run from good to bad (CPU usage) or from bad to good
+// (battery charge).
+type Scale struct {
+	Stops []Stop
+}
+
+// NewScale creates a scale from stops in any order.
+func NewScale(stops ...Stop) Scale {
+	s := Scale{Stops: append([]Stop(nil), stops...)}
+	sort.SliceStable(s.Stops, func(i, j int) bool { return s.Stops[i].At < s.Stops[j].At })
+	return s
+}
+
+// Scale returns the theme's ascending bar scale, where high values are bad.
+func (t Theme) Scale() Scale {
+	return NewScale(
+		Stop{0, t.Colors.BarLow},
+		Stop{t.Thresholds.Medium, t.Colors.BarMed},
+		Stop{t.Thresholds.High, t.Colors.BarHigh},
+	)
+}
+
+// DescendingScale returns the theme's scale mirrored so that low values are
+// bad, e.g. for battery charge or free space.
+func (t Theme) DescendingScale() Scale {
+	return NewScale(
+		Stop{0, t.Colors.BarHigh},
+		Stop{100 - t.Thresholds.High, t.Colors.BarMed},
+		Stop{100 - t.Thresholds.Medium, t.Colors.BarLow},
+	)
+}
+
+// Color returns the color of the last stop at or below pct.
+func (s Scale) Color(pct float64) color.Color {
+	if len(s.Stops) == 0 {
+		return nil
+	}
+	c := s.Stops[0].Color
+	for _, st := range s.Stops {
+		if pct < st.At {
+			break
+		}
+		c = st.Color
+	}
+	return c
+}
+
+// Blend returns the color at pct interpolated between the surrounding stops.
+func (s Scale) Blend(pct float64) color.Color {
+	if len(s.Stops) == 0 {
+		return nil
+	}
+	if pct <= s.Stops[0].At {
+		return s.Stops[0].Color
+	}
+	for i := 1; i < len(s.Stops); i++ {
+		lo, hi := s.Stops[i-1], s.Stops[i]
+		if pct < hi.At {
+			return lerpColor(lo.Color, hi.Color, (pct-lo.At)/(hi.At-lo.At))
+		}
+	}
+	return s.Stops[len(s.Stops)-1].Color
+}
+
+// lerpColor linearly interpolates between two colors.
+func lerpColor(a, b color.Color, t float64) color.Color {
+	ca := color.NRGBAModel.Convert(a).(color.NRGBA)
+	cb := color.NRGBAModel.Convert(b).(color.NRGBA)
+	mix := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5) }
+	return color.NRGBA{mix(ca.R, cb.R), mix(ca.G, cb.G), mix(ca.B, cb.B), mix(ca.A, cb.A)}
+}
+
+// BarFill selects how the filled part of a bar is painted.
+type BarFill int
+
+const (
+	FillSolid     BarFill = iota // One color chosen by the fill level
+	FillGradient                 // Each column colored by its own position
+	FillSegmented                // Discrete "LED" segments
+)
+
+// BarStyle configures DrawBarStyled.
+type BarStyle struct {
+	Scale    *Scale // Nil uses the theme's ascending scale
+	Fill     BarFill
+	Segments int // Segment count for FillSegmented, default 10
+	Border   bool
+}
+
+// BarSegment is one part of a stacked bar.
+type BarSegment struct {
+	Value float64
+	Color color.Color
+}
+
+// drawBarFrame draws the bar background and optional border.
+func (r *Renderer) drawBarFrame(reg Region, border bool) {
+	r.dc.SetColor(r.colors.BarBG)
+	r.dc.DrawRectangle(float64(reg.X), float64(reg.Y), float64(reg.W), float64(reg.H))
+	r.dc.Fill()
+
+	if border {
+		r.dc.SetColor(r.colors.Border)
+		r.dc.DrawRectangle(float64(reg.X), float64(reg.Y), float64(reg.W), float64(reg.H))
+		r.dc.Stroke()
+	}
+}
+
+// DrawBarStyled draws a progress bar colored by a scale.
+func (r *Renderer) DrawBarStyled(reg Region, value, min, max float64, style BarStyle) {
+	r.drawBarFrame(reg, style.Border)
+
+	scale := r.theme.Scale()
+	if style.Scale != nil {
+		scale = *style.Scale
+	}
+
+	pct := 0.0
+	if max > min {
+		pct = (value - min) / (max - min)
+	}
+	if pct > 1 {
+		pct = 1
+	}
+	innerX, innerY := float64(reg.X+1), float64(reg.Y+1)
+	innerW, innerH := float64(reg.W-2), float64(reg.H-2)
+
+	switch style.Fill {
+	case FillGradient:
+		if pct <= 0 {
+			return
+		}
+		cols := int(innerW*pct + 0.5)
+		for i := 0; i < cols; i++ {
+			r.dc.SetColor(scale.Blend(float64(i) / innerW * 100))
+			r.dc.DrawRectangle(innerX+float64(i), innerY, 1, innerH)
+			r.dc.Fill()
+		}
+
+	case FillSegmented:
+		n := style.Segments
+		if n <= 0 {
+			n = 10
+		}
+		gap := 2.0
+		segW := (innerW - gap*float64(n-1)) / float64(n)
+		lit := int(pct*float64(n) + 0.5)
+		for i := 0; i < n; i++ {
+			x := innerX + float64(i)*(segW+gap)
+			c := r.colors.Border
+			if i < lit {
+				c = scale.Color((float64(i) + 0.5) / float64(n) * 100)
+			}
+			r.dc.SetColor(c)
+			r.dc.DrawRectangle(x, innerY, segW, innerH)
+			r.dc.Fill()
+		}
+
+	default:
+		if pct <= 0 {
+			return
+		}
+		r.dc.SetColor(scale.Color(pct * 100))
+		r.dc.DrawRectangle(innerX, innerY, innerW*pct, innerH)
+		r.dc.Fill()
+	}
+}
+
+// DrawStackedBar draws consecutive segments filling a bar whose full width
+// represents max, e.g. used, buffers and cached memory.
+func (r *Renderer) DrawStackedBar(reg Region, segments []BarSegment, max float64, border bool) {
+	r.drawBarFrame(reg, border)
+	if max <= 0 {
+		return
+	}
+
+	innerW := float64(reg.W - 2)
+	x := float64(reg.X + 1)
+	end := x + innerW
+	for _, seg := range segments {
+		if seg.Value <= 0 {
+			continue
+		}
+		w := innerW * seg.Value / max
+		if x+w > end {
+			w = end - x
+		}
+		if w <= 0 {
+			break
+		}
+		r.dc.SetColor(seg.Color)
+		r.dc.DrawRectangle(x, float64(reg.Y+1), w, float64(reg.H-2))
+		r.dc.Fill()
+		x += w
+	}
+}
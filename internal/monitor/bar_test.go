@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestScaleColor(t *testing.T) {
+	th := DefaultTheme()
+	asc := th.Scale()
+	desc := th.DescendingScale()
+	c := th.Colors
+
+	tests := []struct {
+		name  string
+		scale Scale
+		pct   float64
+		want  color.Color
+	}{
+		{"ascending low", asc, 10, c.BarLow},
+		{"ascending at medium", asc, 50, c.BarMed},
+		{"ascending high", asc, 95, c.BarHigh},
+		{"descending empty", desc, 5, c.BarHigh},
+		{"descending medium", desc, 30, c.BarMed},
+		{"descending full", desc, 90, c.BarLow},
+		{"unsorted stops", NewScale(Stop{40, c.BarHigh}, Stop{0, c.BarLow}), 45, c.BarHigh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scale.Color(tt.pct); got != tt.want {
+				t.Errorf("Color(%v) = %v, want %v", tt.pct, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaleBlend(t *testing.T) {
+	s := NewScale(Stop{0, color.RGBA{0, 0, 0, 255}}, Stop{100, color.RGBA{200, 100, 0, 255}})
+	got := color.NRGBAModel.Convert(s.Blend(50)).(color.NRGBA)
+	if got != (color.NRGBA{100, 50, 0, 255}) {
+		t.Errorf("Blend(50) = %v, want {100 50 0 255}", got)
+	}
+	if got := s.Blend(150); got != s.Stops[1].Color {
+		t.Errorf("Blend(150) = %v, want last stop", got)
+	}
+}
+
+func TestDrawStackedBar(t *testing.T) {
+	r := newTestRenderer(110, 10)
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	r.DrawStackedBar(Region{0, 0, 102, 10}, []BarSegment{{25, red}, {50, blue}}, 100, false)
+
+	img := r.dc.Image().(*image.RGBA)
+	if got := img.RGBAAt(10, 5); got != red {
+		t.Errorf("pixel in first segment = %v, want red", got)
+	}
+	if got := img.RGBAAt(50, 5); got != blue {
+		t.Errorf("pixel in second segment = %v, want blue", got)
+	}
+	if got := img.RGBAAt(90, 5); got != DefaultColors().BarBG {
+		t.Errorf("pixel past segments = %v, want bar background", got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"image/draw"
 	"log/slog"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/fogleman/gg"
@@ -114,15 +115,29 @@ type Monitor interface {
 	Stop()
 }
 
+// Pauser is implemented by monitors that can stop sampling while hidden.
+type Pauser interface {
+	Pause()
+	Resume()
+}
+
+// Redrawer is implemented by monitors that can repaint their whole screen,
+// e.g. after another monitor has drawn over it.
+type Redrawer interface {
+	RequestRedraw()
+}
+
 // Base provides common functionality for monitors.
 type Base struct {
 	screen   lcd.Screen
 	width    int
 	height   int
-	colors   Colors
+	theme    Theme
 	fontPath string
 	fonts    FontConfig
-	running  bool
+	running  atomic.Bool
+	paused   atomic.Bool
+	redraw   atomic.Bool
 	interval time.Duration
 	logger   *slog.Logger
 	
@@ -136,7 +151,7 @@ type Base struct {
 // Config holds base monitor configuration.
 type Config struct {
 	Screen   lcd.Screen
-	Colors   Colors
+	Theme    Theme
 	Fonts    FontConfig
 	Interval time.Duration
 	Logger   *slog.Logger
@@ -155,7 +170,7 @@ func NewBase(cfg Config) *Base {
 		screen:   cfg.Screen,
 		width:    w,
 		height:   h,
-		colors:   cfg.Colors,
+		theme:    cfg.Theme,
 		fonts:    cfg.Fonts,
 		fontPath: cfg.Fonts.Path,
 		interval: cfg.Interval,
@@ -172,7 +187,13 @@ func (b *Base) Width() int { return b.width }
 func (b *Base) Height() int { return b.height }
 
 // Colors returns the color palette.
-func (b *Base) Colors() Colors { return b.colors }
+func (b *Base) Colors() Colors { return b.theme.Colors }
+
+// Theme returns the color theme.
+func (b *Base) Theme() Theme { return b.theme }
+
+// SetTheme replaces the color theme. It must be called before Run.
+func (b *Base) SetTheme(t Theme) { b.theme = t }
 
 // Logger returns the logger.
 func (b *Base) Logger() *slog.Logger { return b.logger }
@@ -181,10 +202,37 @@ func (b *Base) Logger() *slog.Logger { return b.logger }
 func (b *Base) Interval() time.Duration { return b.interval }
 
 // Running returns whether the monitor is running.
-func (b *Base) Running() bool { return b.running }
+func (b *Base) Running() bool { return b.running.Load() }
 
 // SetRunning sets the running state.
-func (b *Base) SetRunning(r bool) { b.running = r }
+func (b *Base) SetRunning(r bool) { b.running.Store(r) }
+
+// Pause stops sampling and drawing until Resume is called.
+func (b *Base) Pause() { b.paused.Store(true) }
+
+// Resume restarts sampling after Pause.
+func (b *Base) Resume() { b.paused.Store(false) }
+
+// Paused returns whether the monitor is paused.
+func (b *Base) Paused() bool { return b.paused.Load() }
+
+// RequestRedraw asks for the whole buffer to be sent on the next tick.
+func (b *Base) RequestRedraw() { b.redraw.Store(true) }
+
+// Tick runs one refresh cycle: it skips update while paused and sends the
+// full buffer after update if a redraw was requested.
+func (b *Base) Tick(update func() error) error {
+	if b.Paused() {
+		return nil
+	}
+	if err := update(); err != nil {
+		return err
+	}
+	if b.redraw.CompareAndSwap(true, false) {
+		return b.DrawFullBuffer()
+	}
+	return nil
+}
 
 // Screen returns the LCD screen.
 func (b *Base) Screen() lcd.Screen { return b.screen }
@@ -194,7 +242,7 @@ func (b *Base) Buffer() *image.RGBA { return b.buffer }
 
 // ClearBuffer fills the buffer with background color.
 func (b *Base) ClearBuffer() {
-	draw.Draw(b.buffer, b.buffer.Bounds(), &image.Uniform{b.colors.BG}, image.Point{}, draw.Src)
+	draw.Draw(b.buffer, b.buffer.Bounds(), &image.Uniform{b.theme.Colors.BG}, image.Point{}, draw.Src)
 }
 
 // DrawFullBuffer sends the entire buffer to the display.
@@ -208,6 +256,20 @@ func (b *Base) DrawRegion(r Region) error {
 	return b.screen.DrawImage(sub, r.X, r.Y)
 }
 
+// ScrollLeft shifts the contents of a buffer region left by dx pixels. The
+// rightmost dx columns keep their old contents for the caller to redraw.
+func (b *Base) ScrollLeft(r Region, dx int) {
+	if dx <= 0 || dx >= r.W {
+		return
+	}
+	for y := r.Y; y < r.Y+r.H; y++ {
+		dst := b.buffer.PixOffset(r.X, y)
+		src := b.buffer.PixOffset(r.X+dx, y)
+		end := b.buffer.PixOffset(r.X+r.W, y)
+		copy(b.buffer.Pix[dst:], b.buffer.Pix[src:end])
+	}
+}
+
 // Changed checks if a value changed and updates cache.
 func (b *Base) Changed(key string, value any) bool {
 	if prev, ok := b.cache[key]; ok && prev == value {
@@ -217,6 +279,18 @@ func (b *Base) Changed(key string, value any) bool {
 	return true
 }
 
+// ResetChanged forgets all cached values so everything is redrawn.
+func (b *Base) ResetChanged() {
+	b.cache = make(map[string]any)
+}
+
+// Forget drops the cached values of keys so they count as changed.
+func (b *Base) Forget(keys ...string) {
+	for _, k := range keys {
+		delete(b.cache, k)
+	}
+}
+
 // ChangedFloat checks if a float value changed beyond threshold.
 func (b *Base) ChangedFloat(key string, value, threshold float64) bool {
 	if prev, ok := b.cache[key].(float64); ok {
@@ -247,13 +321,14 @@ func (b *Base) NewContext(r Region) *gg.Context {
 // Renderer provides high-level drawing operations.
 type Renderer struct {
 	dc     *gg.Context
+	theme  Theme
 	colors Colors
 	fonts  FontConfig
 }
 
 // NewRenderer creates a renderer for a context.
-func NewRenderer(dc *gg.Context, colors Colors, fonts FontConfig) *Renderer {
-	return &Renderer{dc: dc, colors: colors, fonts: fonts}
+func NewRenderer(dc *gg.Context, theme Theme, fonts FontConfig) *Renderer {
+	return &Renderer{dc: dc, theme: theme, colors: theme.Colors, fonts: fonts}
 }
 
 // Clear fills a region with background color.
@@ -282,45 +357,9 @@ func (r *Renderer) DrawTextRight(x, y, width float64, text string, fontSize floa
 	r.dc.DrawString(text, x+width-tw, y+fontSize)
 }
 
-// DrawBar draws a progress bar.
+// DrawBar draws a progress bar colored by the theme's thresholds.
 func (r *Renderer) DrawBar(reg Region, value, min, max float64, showBorder bool) {
-	// Background
-	r.dc.SetColor(r.colors.BarBG)
-	r.dc.DrawRectangle(float64(reg.X), float64(reg.Y), float64(reg.W), float64(reg.H))
-	r.dc.Fill()
-	
-	// Border
-	if showBorder {
-		r.dc.SetColor(r.colors.Border)
-		r.dc.DrawRectangle(float64(reg.X), float64(reg.Y), float64(reg.W), float64(reg.H))
-		r.dc.Stroke()
-	}
-	
-	if value <= min {
-		return
-	}
-	
-	// Calculate fill
-	pct := (value - min) / (max - min)
-	if pct > 1 {
-		pct = 1
-	}
-	fillW := float64(reg.W-2) * pct
-	
-	// Color based on percentage
-	var c color.Color
-	switch {
-	case pct < 0.5:
-		c = r.colors.BarLow
-	case pct < 0.8:
-		c = r.colors.BarMed
-	default:
-		c = r.colors.BarHigh
-	}
-	
-	r.dc.SetColor(c)
-	r.dc.DrawRectangle(float64(reg.X+1), float64(reg.Y+1), fillW, float64(reg.H-2))
-	r.dc.Fill()
+	r.DrawBarStyled(reg, value, min, max, BarStyle{Border: showBorder})
 }
 
 // DrawLine draws a horizontal line.
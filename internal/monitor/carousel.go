@@ -0,0 +1,369 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/pkg/agentstat"
+)
+
+func init() {
+	Register("carousel", func() CarouselOptions { return CarouselOptions{Interval: Duration(15 * time.Second)} },
+		func(p Params, opts CarouselOptions) (Monitor, error) {
+			if len(opts.Pages) == 0 {
+				return nil, errors.New("pages: at least one page is required")
+			}
+			c := NewCarousel(CarouselConfig{
+				Screen:   p.Screen,
+				Interval: time.Duration(opts.Interval),
+				Logger:   p.Logger,
+			})
+			for i, pg := range opts.Pages {
+				name := pg.Name
+				if name == "" {
+					name = pg.Monitor
+				}
+				var err error
+				c.AddPage(name, time.Duration(pg.Duration), func(s lcd.Screen) Monitor {
+					pp := p
+					pp.Screen = s
+					var m Monitor
+					m, err = pg.Spec.Build(pp)
+					return m
+				})
+				if err != nil {
+					return nil, fmt.Errorf("pages[%d]: %w", i, err)
+				}
+			}
+			if opts.OnAgentWaiting != "" {
+				c.AddTrigger(AgentWaitingTrigger(opts.OnAgentWaiting))
+			}
+			return c, nil
+		})
+}
+
+// CarouselOptions are the config options for the "carousel" monitor.
+type CarouselOptions struct {
+	Interval       Duration       `json:"interval"`                   // Default time per page
+	OnAgentWaiting string         `json:"on_agent_waiting,omitempty"` // Page to show when an agent starts waiting
+	Pages          []CarouselPage `json:"pages"`
+}
+
+// CarouselPage configures one carousel page.
+type CarouselPage struct {
+	Name     string   `json:"name,omitempty"` // Defaults to the monitor name
+	Duration Duration `json:"duration,omitempty"`
+	Spec
+}
+
+// Trigger is polled by a Carousel on every tick and returns the name of a
+// page to switch to when an event of interest has happened.
+type Trigger func() (page string, ok bool)
+
+// CarouselConfig holds carousel configuration.
+type CarouselConfig struct {
+	Screen   lcd.Screen
+	Interval time.Duration // Default time each page is shown, 0 disables rotation
+	Poll     time.Duration // How often triggers and rotation are checked
+	Logger   *slog.Logger
+}
+
+// Carousel shows several monitors on one screen, one page at a time. Pages
+// switch on a timer or when a Trigger fires. Hidden pages are paused so they
+// stop sampling, and a page is fully redrawn when it is shown.
+type Carousel struct {
+	screen   lcd.Screen
+	interval time.Duration
+	poll     time.Duration
+	logger   *slog.Logger
+
+	mu       sync.Mutex // Serializes drawing with page switches
+	pages    []*carouselPage
+	triggers []Trigger
+	current  int
+	shownAt  time.Time
+
+	paused   atomic.Bool
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// carouselPage is one monitor hosted by a carousel.
+type carouselPage struct {
+	name     string
+	duration time.Duration
+	monitor  Monitor
+	screen   *pageScreen
+}
+
+// pageScreen is the screen handed to a carousel page. It forwards drawing to
+// the physical screen only while its page is shown.
+type pageScreen struct {
+	lcd.Screen
+	c      *Carousel
+	active bool // Guarded by c.mu
+}
+
+func (s *pageScreen) DrawImage(img image.Image, x, y int) error {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	if !s.active || s.c.paused.Load() {
+		return nil
+	}
+	return s.Screen.DrawImage(img, x, y)
+}
+
+// Close is a no-op: the carousel owns the physical screen.
+func (s *pageScreen) Close() error { return nil }
+
+func (s *pageScreen) ScreenOn() error  { return nil }
+func (s *pageScreen) ScreenOff() error { return nil }
+
+// NewCarousel creates an empty carousel.
+func NewCarousel(cfg CarouselConfig) *Carousel {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.Poll <= 0 {
+		cfg.Poll = time.Second
+	}
+	return &Carousel{
+		screen:   cfg.Screen,
+		interval: cfg.Interval,
+		poll:     cfg.Poll,
+		logger:   cfg.Logger,
+		stop:     make(chan struct{}),
+	}
+}
+
+// AddPage adds a page. build is called with the screen the page's monitor
+// must draw to. A duration of 0 uses the carousel interval.
+// Pages must be added before Run.
+func (c *Carousel) AddPage(name string, duration time.Duration, build func(screen lcd.Screen) Monitor) {
+	s := &pageScreen{Screen: c.screen, c: c, active: len(c.pages) == 0}
+	c.pages = append(c.pages, &carouselPage{
+		name:     name,
+		duration: duration,
+		monitor:  build(s),
+		screen:   s,
+	})
+}
+
+// AddTrigger adds an event trigger. Triggers must be added before Run.
+func (c *Carousel) AddTrigger(t Trigger) {
+	c.triggers = append(c.triggers, t)
+}
+
+// Name returns the monitor name.
+func (c *Carousel) Name() string { return "Carousel" }
+
+// Current returns the name of the page being shown.
+func (c *Carousel) Current() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pages) == 0 {
+		return ""
+	}
+	return c.pages[c.current].name
+}
+
+// Run starts every page and rotates between them until Stop is called.
+func (c *Carousel) Run() error {
+	if len(c.pages) == 0 {
+		return errors.New("carousel has no pages")
+	}
+	select {
+	case <-c.stop:
+		return nil // Stopped before Run
+	default:
+	}
+
+	// Hidden pages start paused; their initial draw is discarded
+	for i, p := range c.pages {
+		if i != c.current {
+			pause(p.monitor)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range c.pages {
+		wg.Add(1)
+		go func(p *carouselPage) {
+			defer wg.Done()
+			if err := p.monitor.Run(); err != nil {
+				c.logger.Error("page failed", "page", p.name, "error", err)
+			}
+		}(p)
+	}
+	c.mu.Lock()
+	c.shownAt = time.Now()
+	c.mu.Unlock()
+
+	c.logger.Info("started", "monitor", c.Name(), "pages", len(c.pages))
+
+	ticker := time.NewTicker(c.poll)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			c.check()
+		case <-c.stop:
+			break loop
+		}
+	}
+
+	for _, p := range c.pages {
+		p.monitor.Stop()
+	}
+	wg.Wait()
+	return nil
+}
+
+// check fires triggers and advances the rotation.
+func (c *Carousel) check() {
+	if c.paused.Load() {
+		return
+	}
+	for _, t := range c.triggers {
+		if name, ok := t(); ok {
+			if err := c.Show(name); err != nil {
+				c.logger.Warn("trigger failed", "error", err)
+			}
+			return
+		}
+	}
+
+	c.mu.Lock()
+	d := c.pages[c.current].duration
+	if d == 0 {
+		d = c.interval
+	}
+	due := d > 0 && time.Since(c.shownAt) >= d
+	c.mu.Unlock()
+
+	if due {
+		c.Next()
+	}
+}
+
+// Stop stops the carousel and all its pages.
+func (c *Carousel) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// Next shows the page after the current one.
+func (c *Carousel) Next() {
+	c.mu.Lock()
+	i := (c.current + 1) % len(c.pages)
+	c.mu.Unlock()
+	c.switchTo(i)
+}
+
+// Show switches to the named page. Showing the current page restarts its
+// display time.
+func (c *Carousel) Show(name string) error {
+	for i, p := range c.pages {
+		if p.name == name {
+			c.switchTo(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown page %q", name)
+}
+
+func (c *Carousel) switchTo(i int) {
+	c.mu.Lock()
+	c.shownAt = time.Now()
+	if i == c.current {
+		c.mu.Unlock()
+		return
+	}
+	old := c.pages[c.current]
+	next := c.pages[i]
+	old.screen.active = false
+	next.screen.active = true
+	c.current = i
+	c.mu.Unlock()
+
+	pause(old.monitor)
+	resume(next.monitor)
+	if r, ok := next.monitor.(Redrawer); ok {
+		r.RequestRedraw()
+	}
+	c.logger.Debug("page switched", "from", old.name, "to", next.name)
+}
+
+// visible returns the monitor of the page being shown.
+func (c *Carousel) visible() Monitor {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pages[c.current].monitor
+}
+
+// Pause pauses the visible page and stops rotation.
+func (c *Carousel) Pause() {
+	c.paused.Store(true)
+	pause(c.visible())
+}
+
+// Resume restarts the visible page and rotation after Pause.
+func (c *Carousel) Resume() {
+	c.mu.Lock()
+	c.shownAt = time.Now()
+	c.mu.Unlock()
+	c.paused.Store(false)
+	resume(c.visible())
+}
+
+// RequestRedraw asks the visible page to repaint the whole screen.
+func (c *Carousel) RequestRedraw() {
+	if r, ok := c.visible().(Redrawer); ok {
+		r.RequestRedraw()
+	}
+}
+
+func pause(m Monitor) {
+	if p, ok := m.(Pauser); ok {
+		p.Pause()
+	}
+}
+
+func resume(m Monitor) {
+	if p, ok := m.(Pauser); ok {
+		p.Resume()
+	}
+}
+
+// AgentWaitingTrigger returns a trigger that switches to page when an agent
+// enters the "waiting" status.
+func AgentWaitingTrigger(page string) Trigger {
+	waiting := make(map[string]bool)
+	return func() (string, bool) {
+		agents, err := agentstat.ReadAll(agentstat.StaleThreshold)
+		if err != nil {
+			return "", false
+		}
+		fired := false
+		now := make(map[string]bool)
+		for _, a := range agents {
+			if a.Status != "waiting" {
+				continue
+			}
+			key := a.Agent + "/" + a.Instance
+			now[key] = true
+			if !waiting[key] {
+				fired = true
+			}
+		}
+		waiting = now
+		return page, fired
+	}
+}
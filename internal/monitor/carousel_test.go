@@ -0,0 +1,155 @@
+package monitor
+
+import (
+	"image"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+)
+
+// recordingScreen is a simulated screen that counts draws.
+type recordingScreen struct {
+	*lcd.SimulatedDisplay
+	mu    sync.Mutex
+	draws []image.Rectangle
+}
+
+func newRecordingScreen(w, h int) *recordingScreen {
+	return &recordingScreen{SimulatedDisplay: lcd.NewSimulated(w, h)}
+}
+
+func (s *recordingScreen) DrawImage(img image.Image, x, y int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := img.Bounds()
+	s.draws = append(s.draws, image.Rect(x, y, x+b.Dx(), y+b.Dy()))
+	return nil
+}
+
+func (s *recordingScreen) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.draws)
+}
+
+// fakeMonitor draws its whole buffer on every tick.
+type fakeMonitor struct {
+	*Base
+	name  string
+	ticks atomic.Int32
+}
+
+func newFakeMonitor(name string, screen lcd.Screen) *fakeMonitor {
+	return &fakeMonitor{
+		name: name,
+		Base: NewBase(Config{Screen: screen, Theme: DefaultTheme(), Interval: 5 * time.Millisecond}),
+	}
+}
+
+func (m *fakeMonitor) Name() string { return m.name }
+func (m *fakeMonitor) Stop()        { m.SetRunning(false) }
+
+func (m *fakeMonitor) Run() error {
+	m.SetRunning(true)
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+	for m.Running() {
+		<-ticker.C
+		m.Tick(func() error {
+			m.ticks.Add(1)
+			return m.DrawFullBuffer()
+		})
+	}
+	return nil
+}
+
+func TestCarouselSwitching(t *testing.T) {
+	screen := newRecordingScreen(40, 30)
+	c := NewCarousel(CarouselConfig{Screen: screen, Poll: time.Hour})
+
+	var pages []*fakeMonitor
+	for _, name := range []string{"cpu", "agents"} {
+		c.AddPage(name, 0, func(s lcd.Screen) Monitor {
+			m := newFakeMonitor(name, s)
+			pages = append(pages, m)
+			return m
+		})
+	}
+
+	done := make(chan error)
+	go func() { done <- c.Run() }()
+	defer func() {
+		c.Stop()
+		<-done
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	if !pages[1].Paused() {
+		t.Error("hidden page is not paused")
+	}
+	if pages[1].ticks.Load() != 0 {
+		t.Errorf("hidden page sampled %d times", pages[1].ticks.Load())
+	}
+	if pages[0].ticks.Load() == 0 {
+		t.Error("visible page never sampled")
+	}
+
+	if err := c.Show("agents"); err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+	if c.Current() != "agents" {
+		t.Errorf("Current() = %q, want agents", c.Current())
+	}
+	if !pages[0].Paused() || pages[1].Paused() {
+		t.Error("pause state not swapped on switch")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if pages[1].ticks.Load() == 0 {
+		t.Error("shown page never sampled")
+	}
+
+	if err := c.Show("ram"); err == nil {
+		t.Error("Show() of unknown page returned no error")
+	}
+}
+
+func TestCarouselStopBeforeRun(t *testing.T) {
+	c := NewCarousel(CarouselConfig{Screen: newRecordingScreen(40, 30), Poll: time.Hour})
+	c.AddPage("cpu", 0, func(s lcd.Screen) Monitor { return newFakeMonitor("cpu", s) })
+	c.Stop()
+	c.Stop() // Repeated stops are harmless
+	done := make(chan error)
+	go func() { done <- c.Run() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() after Stop() did not return")
+	}
+}
+
+func TestPageScreenDropsHiddenDraws(t *testing.T) {
+	screen := newRecordingScreen(40, 30)
+	c := NewCarousel(CarouselConfig{Screen: screen})
+
+	var screens []lcd.Screen
+	for _, name := range []string{"a", "b"} {
+		c.AddPage(name, 0, func(s lcd.Screen) Monitor {
+			screens = append(screens, s)
+			return newFakeMonitor(name, s)
+		})
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+
+	screens[1].DrawImage(img, 0, 0)
+	if n := screen.count(); n != 0 {
+		t.Errorf("hidden page drew %d times", n)
+	}
+	screens[0].DrawImage(img, 0, 0)
+	if n := screen.count(); n != 1 {
+		t.Errorf("visible page drew %d times, want 1", n)
+	}
+}
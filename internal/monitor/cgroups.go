@@ -0,0 +1,252 @@
+package monitor
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func init() {
+	Register("cgroups", func() CgroupOptions { return CgroupOptions{Sort: "cpu"} },
+		func(p Params, opts CgroupOptions) (Monitor, error) {
+			if opts.Sort != "cpu" && opts.Sort != "memory" {
+				return nil, fmt.Errorf("sort: unknown value %q (want cpu or memory)", opts.Sort)
+			}
+			for _, k := range opts.Kinds {
+				if !contains(cgroupKinds, k) {
+					return nil, fmt.Errorf("kinds: unknown kind %q (want container, service or user)", k)
+				}
+			}
+			for _, globs := range [][]string{opts.Include, opts.Exclude} {
+				for _, g := range globs {
+					if _, err := path.Match(g, ""); err != nil {
+						return nil, fmt.Errorf("pattern %q: %w", g, err)
+					}
+				}
+			}
+			m := NewCgroupMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+			m.sortBy, m.kinds, m.include, m.exclude = opts.Sort, opts.Kinds, opts.Include, opts.Exclude
+			return m, nil
+		})
+}
+
+// CgroupOptions are the config options for the "cgroups" monitor.
+type CgroupOptions struct {
+	Sort    string   `json:"sort"`              // cpu or memory
+	Kinds   []string `json:"kinds,omitempty"`   // Kinds to show: container, service, user; empty shows all
+	Include []string `json:"include,omitempty"` // Cgroup path globs to show; empty shows all
+	Exclude []string `json:"exclude,omitempty"` // Cgroup path globs to hide
+}
+
+// cgroupKinds are the names of the kinds of cgroup shown.
+var cgroupKinds = []string{"container", "service", "user"}
+
+const cgroupRowHeight = 36
+
+// CgroupMonitor displays the CPU and memory use of containers, services
+// and user slices.
+type CgroupMonitor struct {
+	*Base
+	sortBy  string
+	kinds   []string
+	include []string
+	exclude []string
+
+	sampler *sysinfo.CgroupSampler
+	numRows int
+
+	// Readers, replaceable in tests
+	readCgroups func() ([]sysinfo.CgroupStats, error)
+	now         func() time.Time
+}
+
+// NewCgroupMonitor creates a new cgroup monitor.
+func NewCgroupMonitor(screen lcd.Screen, brightness int, interval time.Duration, logger *slog.Logger) *CgroupMonitor {
+	base := NewBase(Config{
+		Screen:   screen,
+		Theme:    DefaultTheme(),
+		Fonts:    DefaultFontConfig(),
+		Interval: interval,
+		Logger:   logger,
+	})
+
+	return &CgroupMonitor{
+		Base:        base,
+		sortBy:      "cpu",
+		sampler:     sysinfo.NewCgroupSampler(),
+		readCgroups: sysinfo.GetCgroups,
+		now:         time.Now,
+	}
+}
+
+// Name returns the monitor name.
+func (m *CgroupMonitor) Name() string { return "Cgroups" }
+
+// Run starts the cgroup monitor loop.
+func (m *CgroupMonitor) Run() error {
+	m.SetRunning(true)
+
+	// Calculate layout
+	m.setupLayout()
+
+	// Initial draw
+	m.ClearBuffer()
+	m.drawStatic()
+	if err := m.DrawFullBuffer(); err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	m.Logger().Info("started", "monitor", m.Name())
+
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+
+	for m.Running() {
+		select {
+		case <-ticker.C:
+			if err := m.Tick(m.update); err != nil {
+				m.Logger().Error("update failed", "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the monitor.
+func (m *CgroupMonitor) Stop() {
+	m.SetRunning(false)
+}
+
+func (m *CgroupMonitor) setupLayout() {
+	m.numRows = (m.Height() - 40) / cgroupRowHeight
+}
+
+// rowRegion returns the region of row i.
+func (m *CgroupMonitor) rowRegion(i int) Region {
+	return Region{0, 40 + i*cgroupRowHeight, m.Width(), cgroupRowHeight - 2}
+}
+
+func (m *CgroupMonitor) drawStatic() {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	r.DrawText(5, 8, "Cgroups", m.fonts.Large, m.Colors().Header)
+	r.DrawLine(0, 34, float64(m.Width()))
+}
+
+// shownCgroups filters groups by kind and path and sorts them, busiest
+// first.
+func (m *CgroupMonitor) shownCgroups(rates []sysinfo.CgroupRate) []sysinfo.CgroupRate {
+	var shown []sysinfo.CgroupRate
+	for _, g := range rates {
+		if len(m.kinds) > 0 && !contains(m.kinds, g.Kind.String()) {
+			continue
+		}
+		if len(m.include) > 0 && !matchAny(m.include, g.Path) {
+			continue
+		}
+		if matchAny(m.exclude, g.Path) {
+			continue
+		}
+		shown = append(shown, g)
+	}
+	sort.SliceStable(shown, func(i, j int) bool {
+		if m.sortBy == "memory" || shown[i].CPUPercent == shown[j].CPUPercent {
+			return shown[i].MemCurrent > shown[j].MemCurrent
+		}
+		return shown[i].CPUPercent > shown[j].CPUPercent
+	})
+	return shown
+}
+
+func (m *CgroupMonitor) update() error {
+	stats, err := m.readCgroups()
+	if err != nil {
+		return err
+	}
+	shown := m.shownCgroups(m.sampler.Sample(stats, m.now()))
+
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	var updates []Region
+
+	// Totals of the shown groups
+	var cpu float64
+	var mem uint64
+	for _, g := range shown {
+		cpu += g.CPUPercent
+		mem += g.MemCurrent
+	}
+	summary := fmt.Sprintf("%d · %.0f%% · %s", len(shown), cpu, sysinfo.FormatBytes(mem))
+	if m.Changed("summary", summary) {
+		reg := Region{130, 8, m.Width() - 135, 24}
+		r.Clear(reg)
+		r.DrawTextRightFit(float64(reg.X), float64(reg.Y)+2, float64(reg.W), summary, m.fonts.Normal, m.Colors().TextDim, EllipsisEnd)
+		updates = append(updates, reg)
+	}
+
+	// Group rows
+	for i := 0; i < m.numRows; i++ {
+		reg := m.rowRegion(i)
+		key := fmt.Sprintf("cgroup_%d", i)
+		if i >= len(shown) {
+			if m.Changed(key, "") {
+				r.Clear(reg)
+				updates = append(updates, reg)
+			}
+			continue
+		}
+		g := shown[i]
+		if m.Changed(key, fmt.Sprint(g.Name, int(g.CPUPercent), int(g.ThrottledPct), g.CPUMax,
+			sysinfo.FormatBytes(g.MemCurrent), g.MemMax)) {
+			m.renderRow(r, reg, g)
+			updates = append(updates, reg)
+		}
+	}
+
+	// Push updates to display
+	for _, reg := range updates {
+		if err := m.DrawRegion(reg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderRow draws a cgroup's name, CPU use and memory, with its kind,
+// limits and throttling underneath.
+func (m *CgroupMonitor) renderRow(r *Renderer, reg Region, g sysinfo.CgroupRate) {
+	r.Clear(reg)
+	colors := m.Colors()
+	y := float64(reg.Y)
+
+	r.DrawTextFit(5, y, 215, g.Name, m.fonts.Normal, colors.Text, EllipsisMiddle)
+	r.DrawTextRight(220, y, 80, fmt.Sprintf("%.0f%%", g.CPUPercent), m.fonts.Normal, colors.Text)
+	mem := sysinfo.FormatBytes(g.MemCurrent)
+	if g.MemMax > 0 {
+		mem += " / " + sysinfo.FormatBytes(g.MemMax)
+	}
+	r.DrawTextRightFit(305, y, float64(reg.W-310), mem, m.fonts.Normal, colors.Text, EllipsisEnd)
+
+	detail := g.Kind.String()
+	if g.CPUMax > 0 {
+		detail += fmt.Sprintf(" · max %.1f CPU", g.CPUMax)
+	}
+	r.DrawTextFit(5, y+18, 215, detail, m.fonts.Small-2, colors.TextDim, EllipsisEnd)
+	if g.ThrottledPct > 0 {
+		r.DrawTextRight(220, y+18, 80, fmt.Sprintf("thr %.0f%%", g.ThrottledPct), m.fonts.Small-2,
+			m.Theme().Scale().Color(g.ThrottledPct*2)) // Throttling half the periods is already severe
+	}
+	if g.MemMax > 0 {
+		pct := float64(g.MemCurrent) / float64(g.MemMax) * 100
+		r.DrawBarStyled(Region{315, reg.Y + 20, reg.W - 320, 10}, pct, 0, 100, BarStyle{Border: true})
+	}
+}
@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func TestShownCgroups(t *testing.T) {
+	rates := []sysinfo.CgroupRate{
+		{CgroupStats: sysinfo.CgroupStats{Path: "init.scope", Name: "init.scope", MemCurrent: 10 << 20}},
+		{CgroupStats: sysinfo.CgroupStats{Path: "system.slice/docker-x.scope", Name: "docker: db", Kind: sysinfo.CgroupContainer, MemCurrent: 2 << 30}, CPUPercent: 40},
+		{CgroupStats: sysinfo.CgroupStats{Path: "system.slice/nginx.service", Name: "nginx", MemCurrent: 50 << 20}, CPUPercent: 90},
+		{CgroupStats: sysinfo.CgroupStats{Path: "user.slice/user-1000.slice", Name: "user alice", Kind: sysinfo.CgroupUser, MemCurrent: 4 << 30}},
+	}
+
+	tests := []struct {
+		name string
+		opts CgroupOptions
+		want []string
+	}{
+		{"cpu", CgroupOptions{Sort: "cpu"}, []string{"nginx", "docker: db", "user alice", "init.scope"}},
+		{"memory", CgroupOptions{Sort: "memory"}, []string{"user alice", "docker: db", "nginx", "init.scope"}},
+		{"kinds", CgroupOptions{Sort: "cpu", Kinds: []string{"container", "user"}}, []string{"docker: db", "user alice"}},
+		{"include", CgroupOptions{Sort: "cpu", Include: []string{"system.slice/*"}, Exclude: []string{"*/nginx.service"}}, []string{"docker: db"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewCgroupMonitor(lcd.NewSimulated(320, 480), 50, time.Second, nil)
+			m.sortBy, m.kinds, m.include, m.exclude = tt.opts.Sort, tt.opts.Kinds, tt.opts.Include, tt.opts.Exclude
+			var got []string
+			for _, g := range m.shownCgroups(rates) {
+				got = append(got, g.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shown = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,674 @@
+package monitor
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	_ "time/tzdata" // Zones work on systems without a tz database
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/ical"
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+)
+
+func init() {
+	Register("clock", func() ClockOptions { return ClockOptions{Face: "digital"} },
+		func(p Params, opts ClockOptions) (Monitor, error) {
+			m := NewClockMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+			if err := m.configure(opts); err != nil {
+				return nil, err
+			}
+			return m, nil
+		})
+}
+
+// ClockOptions are the config options for the "clock" monitor.
+type ClockOptions struct {
+	Face       string          `json:"face"`                 // digital or analog
+	Timezone   string          `json:"timezone,omitempty"`   // Zone of the main clock, e.g. Europe/Berlin; default local
+	Hour12     bool            `json:"hour12,omitempty"`     // 12-hour times with AM/PM
+	Zones      []ClockZone     `json:"zones,omitempty"`      // Other zones shown beside the clock
+	Calendar   string          `json:"calendar,omitempty"`   // .ics file whose events today are listed
+	Countdowns []CountdownSpec `json:"countdowns,omitempty"` // Timers counting down to a time
+}
+
+// ClockZone is a time zone shown beside the main clock.
+type ClockZone struct {
+	Name string `json:"name"` // Label, default the zone's city
+	TZ   string `json:"tz"`   // IANA zone name, e.g. Asia/Tokyo
+}
+
+// CountdownSpec is a countdown to a daily time or a moment.
+type CountdownSpec struct {
+	Name string   `json:"name"`
+	At   string   `json:"at"`             // "09:30" daily, or "2026-12-24 18:00" once
+	Days []string `json:"days,omitempty"` // For daily times, the days they fall on, e.g. mon, tue; default all
+}
+
+const (
+	maxClockZones  = 5
+	maxCountdowns  = 3
+	clockTopHeight = 136
+	eventRowH      = 24
+	calendarReload = time.Minute // How often the calendar file is checked for changes
+)
+
+// clockZone is a resolved ClockZone.
+type clockZone struct {
+	name string
+	loc  *time.Location
+}
+
+// countdown is a parsed CountdownSpec.
+type countdown struct {
+	name   string
+	daily  bool
+	hour   int
+	minute int
+	days   [7]bool   // Daily: the weekdays it falls on
+	at     time.Time // Once: the moment
+}
+
+// ClockMonitor displays the time with other zones, today's calendar and
+// countdowns.
+type ClockMonitor struct {
+	*Base
+	analog     bool
+	hour12     bool
+	loc        *time.Location
+	zones      []clockZone
+	calPath    string
+	countdowns []countdown
+
+	cal        *ical.Calendar
+	calErr     error
+	calMod     time.Time
+	calChecked time.Time
+
+	secondsReg Region // Redrawn every tick: the seconds, or the analog dial
+	timeReg    Region
+	paneW      int // Width of the calendar pane; countdowns take the rest
+
+	// Replaceable in tests
+	now func() time.Time
+}
+
+// NewClockMonitor creates a new clock monitor with a digital face in the
+// local time zone.
+func NewClockMonitor(screen lcd.Screen, brightness int, interval time.Duration, logger *slog.Logger) *ClockMonitor {
+	base := NewBase(Config{
+		Screen:   screen,
+		Theme:    DefaultTheme(),
+		Fonts:    DefaultFontConfig(),
+		Interval: interval,
+		Logger:   logger,
+	})
+
+	return &ClockMonitor{
+		Base: base,
+		loc:  time.Local,
+		now:  time.Now,
+	}
+}
+
+// configure applies options, resolving zones and parsing countdowns.
+func (m *ClockMonitor) configure(opts ClockOptions) error {
+	switch opts.Face {
+	case "digital":
+	case "analog":
+		m.analog = true
+	default:
+		return fmt.Errorf("face: unknown face %q (want digital or analog)", opts.Face)
+	}
+	m.hour12 = opts.Hour12
+
+	if opts.Timezone != "" {
+		loc, err := time.LoadLocation(opts.Timezone)
+		if err != nil {
+			return fmt.Errorf("timezone: %w", err)
+		}
+		m.loc = loc
+	}
+
+	if len(opts.Zones) > maxClockZones {
+		return fmt.Errorf("zones: at most %d zones fit", maxClockZones)
+	}
+	for i, z := range opts.Zones {
+		loc, err := time.LoadLocation(z.TZ)
+		if err != nil {
+			return fmt.Errorf("zones[%d]: %w", i, err)
+		}
+		name := z.Name
+		if name == "" {
+			name = strings.ReplaceAll(filepath.Base(z.TZ), "_", " ")
+		}
+		m.zones = append(m.zones, clockZone{name, loc})
+	}
+
+	if len(opts.Countdowns) > maxCountdowns {
+		return fmt.Errorf("countdowns: at most %d countdowns fit", maxCountdowns)
+	}
+	for i, spec := range opts.Countdowns {
+		c, err := parseCountdown(spec, m.loc)
+		if err != nil {
+			return fmt.Errorf("countdowns[%d]: %w", i, err)
+		}
+		m.countdowns = append(m.countdowns, c)
+	}
+
+	m.calPath = opts.Calendar
+	if rest, ok := strings.CutPrefix(m.calPath, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("calendar: %w", err)
+		}
+		m.calPath = filepath.Join(home, rest)
+	}
+	return nil
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseCountdown parses a countdown to a daily time ("09:30") or to a
+// moment ("2026-12-24 18:00" or RFC 3339) in loc.
+func parseCountdown(spec CountdownSpec, loc *time.Location) (countdown, error) {
+	c := countdown{name: spec.Name}
+	if t, err := time.Parse("15:04", spec.At); err == nil {
+		c.daily, c.hour, c.minute = true, t.Hour(), t.Minute()
+		for _, d := range spec.Days {
+			wd, ok := weekdayNames[strings.ToLower(d)]
+			if !ok {
+				return c, fmt.Errorf("days: unknown day %q", d)
+			}
+			c.days[wd] = true
+		}
+		if len(spec.Days) == 0 {
+			c.days = [7]bool{true, true, true, true, true, true, true}
+		}
+		return c, nil
+	}
+	if len(spec.Days) > 0 {
+		return c, fmt.Errorf("days: only apply to daily times")
+	}
+	for _, layout := range []string{"2006-01-02 15:04", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, spec.At, loc); err == nil {
+			c.at = t
+			return c, nil
+		}
+	}
+	return c, fmt.Errorf("at: %q is not HH:MM, YYYY-MM-DD HH:MM or RFC 3339", spec.At)
+}
+
+// next returns when the countdown next ends after now, or for a moment
+// that has passed, the moment.
+func (c countdown) next(now time.Time) time.Time {
+	if !c.daily {
+		return c.at
+	}
+	y, mo, d := now.Date()
+	for i := 0; i < 8; i++ {
+		t := time.Date(y, mo, d+i, c.hour, c.minute, 0, 0, now.Location())
+		if t.After(now) && c.days[t.Weekday()] {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Name returns the monitor name.
+func (m *ClockMonitor) Name() string { return "Clock" }
+
+// Run starts the clock loop, ticking every second.
+func (m *ClockMonitor) Run() error {
+	m.SetRunning(true)
+
+	// Calculate layout
+	m.setupLayout()
+
+	// Initial draw
+	m.ClearBuffer()
+	m.drawStatic()
+	if err := m.DrawFullBuffer(); err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	m.Logger().Info("started", "monitor", m.Name())
+
+	// Tick just after each second starts, so none is skipped or shown twice
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second + 20*time.Millisecond)))
+	ticker := time.NewTicker(min(m.Interval(), time.Second))
+	defer ticker.Stop()
+
+	for m.Running() {
+		select {
+		case <-ticker.C:
+			if err := m.Tick(m.update); err != nil {
+				m.Logger().Error("update failed", "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the monitor.
+func (m *ClockMonitor) Stop() {
+	m.SetRunning(false)
+}
+
+// setupLayout places the face at the top left, zones at the top right, and
+// below them the calendar pane with countdowns to its right.
+func (m *ClockMonitor) setupLayout() {
+	if m.analog {
+		m.secondsReg = Region{4, 4, 128, 128}
+		m.timeReg = Region{140, 10, 155, 120}
+	} else {
+		// Seconds follow the hours and minutes, whose width is fixed as
+		// the font is monospaced
+		dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+		r := NewRenderer(dc, m.Theme(), m.fonts)
+		w := int(r.MeasureText("00:00", 72)) + 14
+		m.timeReg = Region{0, 0, w, clockTopHeight - 4}
+		m.secondsReg = Region{w, 14, 64, 74}
+	}
+
+	m.paneW = m.Width()
+	if len(m.countdowns) > 0 {
+		m.paneW = m.Width() - 170
+	}
+}
+
+// zoneRegion returns the region of zone row i.
+func (m *ClockMonitor) zoneRegion(i int) Region {
+	return Region{295, 10 + i*24, m.Width() - 295, 22}
+}
+
+// eventRegion returns the region of calendar row i.
+func (m *ClockMonitor) eventRegion(i int) Region {
+	return Region{0, clockTopHeight + 28 + i*eventRowH, m.paneW, eventRowH - 2}
+}
+
+// eventRows returns the number of calendar rows that fit.
+func (m *ClockMonitor) eventRows() int {
+	return (m.Height() - 5 - (clockTopHeight + 28)) / eventRowH
+}
+
+// countdownRegion returns the region of countdown i.
+func (m *ClockMonitor) countdownRegion(i int) Region {
+	return Region{m.paneW + 5, clockTopHeight + 8 + i*58, m.Width() - m.paneW - 10, 54}
+}
+
+// paneRegion returns the region of the calendar pane below its title.
+func (m *ClockMonitor) paneRegion() Region {
+	return Region{0, clockTopHeight + 28, m.paneW, m.Height() - clockTopHeight - 28}
+}
+
+func (m *ClockMonitor) drawStatic() {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	r.DrawLine(0, clockTopHeight, float64(m.Width()))
+	if len(m.countdowns) > 0 {
+		x := float64(m.paneW)
+		r.dc.SetColor(m.Colors().Border)
+		r.dc.DrawLine(x, clockTopHeight, x, float64(m.Height()))
+		r.dc.Stroke()
+	}
+}
+
+func (m *ClockMonitor) update() error {
+	now := m.now().In(m.loc)
+
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	var updates []Region
+
+	// Seconds, or the analog dial with its second hand
+	if m.Changed("second", now.Truncate(time.Second)) {
+		r.Clear(m.secondsReg)
+		if m.analog {
+			reg := m.secondsReg
+			radius := float64(reg.W)/2 - 2
+			r.DrawAnalogClock(float64(reg.X)+float64(reg.W)/2, float64(reg.Y)+float64(reg.H)/2, radius, now)
+		} else {
+			if m.hour12 {
+				r.DrawText(float64(m.secondsReg.X), float64(m.secondsReg.Y), now.Format("PM"), m.fonts.Normal, m.Colors().TextDim)
+			}
+			r.DrawText(float64(m.secondsReg.X), float64(m.secondsReg.Y)+30, now.Format("05"), 36, m.Colors().TextDim)
+		}
+		updates = append(updates, m.secondsReg)
+	}
+
+	// Everything else changes by the minute
+	if m.Changed("minute", now.Truncate(time.Minute)) {
+		m.renderTime(r, now)
+		updates = append(updates, m.timeReg)
+
+		for i, z := range m.zones {
+			reg := m.zoneRegion(i)
+			m.renderZone(r, reg, z, now)
+			updates = append(updates, reg)
+		}
+
+		if m.calPath != "" {
+			updates = append(updates, m.updateCalendar(r, now)...)
+		} else if m.Changed("month", now.Format("2006-01-02")) {
+			reg := Region{0, clockTopHeight + 2, m.paneW, m.Height() - clockTopHeight - 2}
+			m.renderMonth(r, reg, now)
+			updates = append(updates, reg)
+		}
+	}
+
+	// Countdowns
+	for i, c := range m.countdowns {
+		reg := m.countdownRegion(i)
+		end := c.next(now)
+		left := formatCountdown(end.Sub(now))
+		if m.Changed(fmt.Sprintf("countdown_%d", i), left) {
+			m.renderCountdown(r, reg, c, now, end, left)
+			updates = append(updates, reg)
+		}
+	}
+
+	// Push updates to display
+	for _, reg := range updates {
+		if err := m.DrawRegion(reg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clockFormat returns the layout for hours and minutes.
+func (m *ClockMonitor) clockFormat() string {
+	if m.hour12 {
+		return "03:04"
+	}
+	return "15:04"
+}
+
+// renderTime draws the hours, minutes and date.
+func (m *ClockMonitor) renderTime(r *Renderer, now time.Time) {
+	reg := m.timeReg
+	r.Clear(reg)
+	colors := m.Colors()
+	x, y := float64(reg.X)+8, float64(reg.Y)
+
+	if m.analog {
+		hm := now.Format(m.clockFormat())
+		r.DrawText(x, y, hm, 32, colors.Text)
+		if m.hour12 {
+			r.DrawText(x+r.MeasureText(hm, 32)+4, y+14, now.Format("PM"), m.fonts.Small, colors.TextDim)
+		}
+		r.DrawText(x, y+54, now.Format("Monday"), m.fonts.Large, colors.Header)
+		r.DrawTextFit(x, y+84, float64(reg.W-8), now.Format("2 January 2006"), m.fonts.Normal, colors.TextDim, EllipsisEnd)
+		return
+	}
+	r.DrawText(x, y, now.Format(m.clockFormat()), 72, colors.Text)
+	r.DrawText(x+2, y+100, now.Format("Monday 2 January 2006"), m.fonts.Normal, colors.Header)
+}
+
+// renderZone draws a zone's time, marked +1 or -1 when its date differs
+// from the main clock's.
+func (m *ClockMonitor) renderZone(r *Renderer, reg Region, z clockZone, now time.Time) {
+	r.Clear(reg)
+	colors := m.Colors()
+	t := now.In(z.loc)
+
+	text := t.Format(m.clockFormat())
+	if m.hour12 {
+		text += t.Format("PM")
+	}
+	zy, zm, zd := t.Date()
+	ny, nm, nd := now.Date()
+	switch days := time.Date(zy, zm, zd, 0, 0, 0, 0, time.UTC).Sub(time.Date(ny, nm, nd, 0, 0, 0, 0, time.UTC)) / (24 * time.Hour); {
+	case days > 0:
+		text += fmt.Sprintf(" +%d", days)
+	case days < 0:
+		text += fmt.Sprintf(" %d", days)
+	default:
+		text += "   "
+	}
+
+	valueW := r.MeasureText(text, m.fonts.Normal)
+	r.DrawTextFit(float64(reg.X), float64(reg.Y)+2, float64(reg.W)-valueW-10, z.name, m.fonts.Small, colors.TextDim, EllipsisEnd)
+	r.DrawTextRight(float64(reg.X), float64(reg.Y), float64(reg.W-5), text, m.fonts.Normal, colors.Text)
+}
+
+// loadCalendar reparses the calendar file when it changed, checking at
+// most every calendarReload.
+func (m *ClockMonitor) loadCalendar(now time.Time) {
+	if m.cal != nil && now.Sub(m.calChecked) < calendarReload {
+		return
+	}
+	m.calChecked = now
+	st, err := os.Stat(m.calPath)
+	if err != nil {
+		m.cal, m.calErr = nil, err
+		return
+	}
+	if m.cal != nil && st.ModTime().Equal(m.calMod) {
+		return
+	}
+	cal, err := ical.ParseFile(m.calPath, m.loc)
+	if err != nil {
+		m.Logger().Warn("calendar unreadable", "path", m.calPath, "error", err)
+		m.cal, m.calErr = nil, err
+		return
+	}
+	m.cal, m.calErr, m.calMod = cal, nil, st.ModTime()
+}
+
+// updateCalendar lists the events left today: the ongoing ones, then
+// those to come.
+func (m *ClockMonitor) updateCalendar(r *Renderer, now time.Time) []Region {
+	m.loadCalendar(now)
+	colors := m.Colors()
+	var updates []Region
+
+	var events []ical.Event
+	if m.cal != nil {
+		y, mo, d := now.Date()
+		events = m.cal.Between(now, time.Date(y, mo, d+1, 0, 0, 0, 0, m.loc))
+	}
+
+	title := "Today"
+	switch {
+	case m.calErr != nil:
+		title = "Calendar unavailable"
+	case len(events) > 0:
+		title = fmt.Sprintf("Today · %d left", len(events))
+	}
+	if m.Changed("calendar_title", title) {
+		reg := Region{0, clockTopHeight + 2, m.paneW, 24}
+		r.Clear(reg)
+		r.DrawText(5, float64(reg.Y)+4, title, m.fonts.Small, colors.Header)
+		updates = append(updates, reg)
+	}
+
+	// Errors and empty days take the pane, replacing the rows
+	message := ""
+	if m.calErr != nil {
+		message = m.calErr.Error()
+	} else if len(events) == 0 {
+		message = "Nothing else today"
+	}
+	if message != "" {
+		if m.Changed("calendar_message", message) {
+			reg := m.paneRegion()
+			r.Clear(reg)
+			r.DrawWrapped(5, float64(reg.Y)+4, float64(reg.W-10), message, m.fonts.Small, m.fonts.Small+4, 6, colors.TextDim)
+			updates = append(updates, reg)
+			for i := 0; i < m.eventRows(); i++ {
+				m.Forget(fmt.Sprintf("event_%d", i))
+			}
+		}
+		return updates
+	}
+	m.Forget("calendar_message")
+
+	for i := 0; i < m.eventRows(); i++ {
+		reg := m.eventRegion(i)
+		key := fmt.Sprintf("event_%d", i)
+		if i >= len(events) {
+			if m.Changed(key, "") {
+				r.Clear(reg)
+				updates = append(updates, reg)
+			}
+			continue
+		}
+		ev := events[i]
+		when := ev.Start.Format(m.clockFormat())
+		if m.hour12 {
+			when = ev.Start.Format("3:04PM")
+		}
+		whenColor := colors.TextDim
+		switch {
+		case ev.AllDay:
+			when = "all day"
+		case !ev.Start.After(now):
+			when, whenColor = "now", colors.BarMed
+		}
+		if m.Changed(key, when+ev.Summary) {
+			r.Clear(reg)
+			r.DrawText(5, float64(reg.Y)+2, when, m.fonts.Small, whenColor)
+			r.DrawTextFit(80, float64(reg.Y)+2, float64(reg.W-85), ev.Summary, m.fonts.Small, colors.Text, EllipsisEnd)
+			updates = append(updates, reg)
+		}
+	}
+	return updates
+}
+
+// renderMonth draws the current month with today highlighted, in place of
+// the calendar when no file is configured.
+func (m *ClockMonitor) renderMonth(r *Renderer, reg Region, now time.Time) {
+	r.Clear(reg)
+	colors := m.Colors()
+	y, mo, d := now.Date()
+	first := time.Date(y, mo, 1, 0, 0, 0, 0, m.loc)
+	days := time.Date(y, mo+1, 0, 0, 0, 0, 0, m.loc).Day()
+
+	cellW := float64(min(reg.W-10, 420)) / 7
+	x0 := float64(reg.X) + (float64(reg.W)-cellW*7)/2
+	top := float64(reg.Y) + 4
+	r.DrawText(x0+4, top, now.Format("January 2006"), m.fonts.Small, colors.Header)
+	for i, name := range []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"} {
+		r.DrawTextRight(x0+float64(i)*cellW, top+22, cellW-8, name, m.fonts.Small, colors.TextDim)
+	}
+
+	offset := (int(first.Weekday()) + 6) % 7 // Weeks start on Monday
+	rowH := min(22.0, (float64(reg.Y+reg.H)-top-48)/6)
+	for day := 1; day <= days; day++ {
+		cell := offset + day - 1
+		cx := x0 + float64(cell%7)*cellW
+		cy := top + 44 + float64(cell/7)*rowH
+		c := colors.Text
+		if day == d {
+			r.dc.SetColor(colors.BarBG)
+			r.dc.DrawRectangle(cx+2, cy, cellW-4, rowH)
+			r.dc.Fill()
+			c = colors.Header
+		}
+		r.DrawTextRight(cx, cy+2, cellW-8, fmt.Sprint(day), m.fonts.Small, c)
+	}
+}
+
+// renderCountdown draws a countdown's name, end and time left, turning
+// yellow in its last quarter hour and red in its last five minutes.
+func (m *ClockMonitor) renderCountdown(r *Renderer, reg Region, c countdown, now, end time.Time, left string) {
+	r.Clear(reg)
+	d := end.Sub(now)
+	colors := m.Colors()
+	x, y, w := float64(reg.X), float64(reg.Y), float64(reg.W)
+
+	color := colors.Text
+	switch {
+	case d <= 0:
+		color = colors.TextDim
+	case d < 5*time.Minute:
+		color = colors.BarHigh
+	case d < 15*time.Minute:
+		color = colors.BarMed
+	}
+	at := end.Format(m.clockFormat())
+	switch {
+	case sameDay(end, now):
+	case d > 0 && d < 6*24*time.Hour:
+		at = end.Format("Mon ") + at
+	default:
+		at = end.Format("2 Jan")
+	}
+	atW := r.MeasureText(at, m.fonts.Small)
+	r.DrawTextFit(x, y+2, w-atW-10, c.name, m.fonts.Small, colors.TextDim, EllipsisEnd)
+	r.DrawTextRight(x, y+2, w-5, at, m.fonts.Small, colors.TextDim)
+	r.DrawText(x, y+24, left, m.fonts.Large+4, color)
+}
+
+// sameDay reports whether a and b fall on the same date.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// formatCountdown formats the time left: days and hours, hours and
+// minutes, minutes, and seconds in the last minute.
+func formatCountdown(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "done"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd %dh", int(d.Hours())/24, int(d.Hours())%24)
+}
+
+// DrawAnalogClock draws a clock face with hour, minute and second hands.
+func (r *Renderer) DrawAnalogClock(cx, cy, radius float64, t time.Time) {
+	dc := r.dc
+	defer dc.SetLineWidth(1)
+	dc.SetLineCapRound()
+
+	hand := func(turns, length, width float64) {
+		a := turns * 2 * math.Pi
+		dc.SetLineWidth(width)
+		dc.DrawLine(cx, cy, cx+math.Sin(a)*length, cy-math.Cos(a)*length)
+		dc.Stroke()
+	}
+
+	dc.SetColor(r.colors.Border)
+	dc.SetLineWidth(2)
+	dc.DrawCircle(cx, cy, radius)
+	dc.Stroke()
+	for i := 0; i < 12; i++ {
+		a := float64(i) / 12 * 2 * math.Pi
+		inner, width := radius*0.88, 2.0
+		if i%3 == 0 {
+			inner, width = radius*0.78, 3
+		}
+		dc.SetLineWidth(width)
+		dc.DrawLine(cx+math.Sin(a)*inner, cy-math.Cos(a)*inner, cx+math.Sin(a)*radius*0.96, cy-math.Cos(a)*radius*0.96)
+		dc.Stroke()
+	}
+
+	h, mi, s := t.Clock()
+	dc.SetColor(r.colors.Text)
+	hand((float64(h%12)+float64(mi)/60)/12, radius*0.5, 5)
+	hand((float64(mi)+float64(s)/60)/60, radius*0.75, 3)
+	dc.SetColor(r.colors.BarHigh)
+	hand(float64(s)/60, radius*0.86, 1.5)
+	dc.DrawCircle(cx, cy, 3)
+	dc.Fill()
+}
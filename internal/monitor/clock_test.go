@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFormatCountdown(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{-time.Minute, "done"},
+		{42 * time.Second, "42s"},
+		{25*time.Minute + 30*time.Second, "25m"},
+		{2*time.Hour + 5*time.Minute, "2h 05m"},
+		{47 * time.Hour, "47h 00m"},
+		{66*24*time.Hour + 9*time.Hour + 30*time.Minute, "66d 9h"},
+	}
+	for _, tt := range tests {
+		if got := formatCountdown(tt.d); got != tt.want {
+			t.Errorf("formatCountdown(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestCountdownNext(t *testing.T) {
+	standup, err := parseCountdown(CountdownSpec{Name: "Standup", At: "09:30", Days: []string{"mon", "tue", "wed", "thu", "fri"}}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, hh, mm int) time.Time { return time.Date(2026, 10, day, hh, mm, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"before, monday", at(19, 9, 0), at(19, 9, 30)},
+		{"at the time", at(19, 9, 30), at(20, 9, 30)},
+		{"friday evening", at(23, 18, 0), at(26, 9, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := standup.next(tt.now); !got.Equal(tt.want) {
+				t.Errorf("next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, spec := range []CountdownSpec{
+		{At: "9.30"},
+		{At: "09:30", Days: []string{"someday"}},
+		{At: "2026-12-24 18:00", Days: []string{"mon"}},
+	} {
+		if _, err := parseCountdown(spec, time.UTC); err == nil {
+			t.Errorf("parseCountdown(%+v) returned no error", spec)
+		}
+	}
+}
+
+func TestClockMonitorUpdate(t *testing.T) {
+	dir := t.TempDir()
+	ics := filepath.Join(dir, "work.ics")
+	if err := os.WriteFile(ics, []byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:Standup\nDTSTART:20261019T093000Z\nDTEND:20261019T094500Z\nEND:VEVENT\nBEGIN:VEVENT\nSUMMARY:Yesterday\nDTSTART:20261018T093000Z\nEND:VEVENT\nEND:VCALENDAR\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	screen := newRecordingScreen(320, 480)
+	m := NewClockMonitor(screen, 50, time.Second, nil)
+	err := m.configure(ClockOptions{Face: "digital", Timezone: "UTC", Calendar: ics,
+		Zones: []ClockZone{{TZ: "Pacific/Auckland"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 19, 9, 20, 58, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	m.setupLayout()
+
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.cache["event_0"]; got != "09:30Standup" {
+		t.Errorf("event_0 = %v, want the standup", got)
+	}
+	if got := m.cache["event_1"]; got != "" {
+		t.Errorf("event_1 = %v, want empty", got)
+	}
+
+	// Within a minute only the seconds are redrawn
+	screen.draws = nil
+	now = now.Add(time.Second)
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	want := []image.Rectangle{m.secondsReg.Bounds()}
+	if !reflect.DeepEqual(screen.draws, want) {
+		t.Errorf("draws = %v, want only the seconds %v", screen.draws, want)
+	}
+
+	// Standup under way at the next minute
+	now = time.Date(2026, 10, 19, 9, 31, 0, 0, time.UTC)
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.cache["event_0"]; got != "nowStandup" {
+		t.Errorf("event_0 = %v, want the standup under way", got)
+	}
+}
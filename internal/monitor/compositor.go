@@ -0,0 +1,284 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"log/slog"
+	"sync"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+)
+
+func init() {
+	Register("compositor", func() CompositorOptions { return CompositorOptions{Direction: "columns"} },
+		func(p Params, opts CompositorOptions) (Monitor, error) {
+			if len(opts.Panes) == 0 {
+				return nil, errors.New("panes: at least one pane is required")
+			}
+			c := NewCompositor(CompositorConfig{Screen: p.Screen, Logger: p.Logger})
+
+			weights := make([]float64, len(opts.Panes))
+			for i, pane := range opts.Panes {
+				weights[i] = pane.Weight
+				if weights[i] <= 0 {
+					weights[i] = 1
+				}
+			}
+			area := Region{0, 0, c.Width(), c.Height()}
+			var regions []Region
+			switch opts.Direction {
+			case "columns":
+				regions = SplitColumns(area, opts.Gap, weights...)
+			case "rows":
+				regions = SplitRows(area, opts.Gap, weights...)
+			default:
+				return nil, fmt.Errorf("direction: unknown value %q (want columns or rows)", opts.Direction)
+			}
+
+			for i, pane := range opts.Panes {
+				name := pane.Name
+				if name == "" {
+					name = pane.Monitor
+				}
+				var buildErr error
+				err := c.AddPane(name, regions[i], func(s lcd.Screen) Monitor {
+					pp := p
+					pp.Screen = s
+					var m Monitor
+					m, buildErr = pane.Spec.Build(pp)
+					return m
+				})
+				if err == nil {
+					err = buildErr
+				}
+				if err != nil {
+					return nil, fmt.Errorf("panes[%d]: %w", i, err)
+				}
+			}
+			return c, nil
+		})
+}
+
+// CompositorOptions are the config options for the "compositor" monitor.
+type CompositorOptions struct {
+	Direction string           `json:"direction"` // columns (side by side) or rows (stacked)
+	Gap       int              `json:"gap"`       // Pixels between panes
+	Panes     []CompositorPane `json:"panes"`
+}
+
+// CompositorPane configures one compositor pane.
+type CompositorPane struct {
+	Name   string  `json:"name,omitempty"`   // Defaults to the monitor name
+	Weight float64 `json:"weight,omitempty"` // Relative size, default 1
+	Spec
+}
+
+// CompositorConfig holds compositor configuration.
+type CompositorConfig struct {
+	Screen     lcd.Screen
+	Background color.Color // Fill for areas not covered by a pane, default black
+	Logger     *slog.Logger
+}
+
+// Compositor runs several monitors side by side on one screen. Each monitor
+// draws to a virtual screen the size of its pane; the compositor translates
+// its draws into a shared frame buffer and on to the physical screen.
+type Compositor struct {
+	screen lcd.Screen
+	bg     color.Color
+	logger *slog.Logger
+
+	mu    sync.Mutex // Guards frame and serializes screen writes
+	frame *image.RGBA
+	panes []*compositorPane
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// compositorPane is one monitor hosted by a compositor.
+type compositorPane struct {
+	name    string
+	region  Region
+	monitor Monitor
+}
+
+// paneScreen is the virtual screen handed to a pane's monitor.
+type paneScreen struct {
+	c      *Compositor
+	region Region
+}
+
+func (s *paneScreen) Width() int  { return s.region.W }
+func (s *paneScreen) Height() int { return s.region.H }
+
+// DrawImage copies img into the shared frame at the pane's offset and sends
+// the affected area to the physical screen. Drawing outside the pane is
+// clipped.
+func (s *paneScreen) DrawImage(img image.Image, x, y int) error {
+	b := img.Bounds()
+	dst := image.Rect(x, y, x+b.Dx(), y+b.Dy()).
+		Intersect(image.Rect(0, 0, s.region.W, s.region.H)).
+		Add(image.Pt(s.region.X, s.region.Y))
+	if dst.Empty() {
+		return nil
+	}
+	src := b.Min.Add(dst.Min.Sub(image.Pt(s.region.X+x, s.region.Y+y)))
+
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	draw.Draw(s.c.frame, dst, img, src, draw.Src)
+	return s.c.screen.DrawImage(s.c.frame.SubImage(dst), dst.Min.X, dst.Min.Y)
+}
+
+// Close is a no-op: the compositor owns the physical screen.
+func (s *paneScreen) Close() error     { return nil }
+func (s *paneScreen) ScreenOn() error  { return nil }
+func (s *paneScreen) ScreenOff() error { return nil }
+
+// NewCompositor creates a compositor with no panes.
+func NewCompositor(cfg CompositorConfig) *Compositor {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.Background == nil {
+		cfg.Background = color.Black
+	}
+	return &Compositor{
+		screen: cfg.Screen,
+		bg:     cfg.Background,
+		logger: cfg.Logger,
+		frame:  image.NewRGBA(image.Rect(0, 0, cfg.Screen.Width(), cfg.Screen.Height())),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Width returns the width of the composited screen.
+func (c *Compositor) Width() int { return c.frame.Bounds().Dx() }
+
+// Height returns the height of the composited screen.
+func (c *Compositor) Height() int { return c.frame.Bounds().Dy() }
+
+// AddPane adds a monitor covering reg. build is called with the virtual
+// screen the monitor must draw to. Panes must be added before Run.
+func (c *Compositor) AddPane(name string, reg Region, build func(screen lcd.Screen) Monitor) error {
+	if !reg.Bounds().In(c.frame.Bounds()) || reg.W <= 0 || reg.H <= 0 {
+		return fmt.Errorf("pane %q region %v outside %dx%d screen", name, reg, c.Width(), c.Height())
+	}
+	for _, p := range c.panes {
+		if p.region.Bounds().Overlaps(reg.Bounds()) {
+			return fmt.Errorf("pane %q overlaps pane %q", name, p.name)
+		}
+	}
+	c.panes = append(c.panes, &compositorPane{
+		name:    name,
+		region:  reg,
+		monitor: build(&paneScreen{c: c, region: reg}),
+	})
+	return nil
+}
+
+// SplitColumns divides area into side-by-side regions with the given
+// relative widths, separated by gap pixels.
+func SplitColumns(area Region, gap int, weights ...float64) []Region {
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	avail := area.W - gap*(len(weights)-1)
+	regions := make([]Region, len(weights))
+	x := area.X
+	var acc float64
+	for i, w := range weights {
+		acc += w
+		end := area.X + int(float64(avail)*acc/total+0.5) + gap*i
+		regions[i] = Region{x, area.Y, end - x, area.H}
+		x = end + gap
+	}
+	return regions
+}
+
+// SplitRows divides area into stacked regions with the given relative
+// heights, separated by gap pixels.
+func SplitRows(area Region, gap int, weights ...float64) []Region {
+	cols := SplitColumns(Region{area.Y, area.X, area.H, area.W}, gap, weights...)
+	rows := make([]Region, len(cols))
+	for i, r := range cols {
+		rows[i] = Region{r.Y, r.X, r.H, r.W}
+	}
+	return rows
+}
+
+// Name returns the monitor name.
+func (c *Compositor) Name() string { return "Compositor" }
+
+// Run clears the screen and runs every pane until Stop is called.
+func (c *Compositor) Run() error {
+	if len(c.panes) == 0 {
+		return errors.New("compositor has no panes")
+	}
+	select {
+	case <-c.stop:
+		return nil // Stopped before Run
+	default:
+	}
+
+	c.mu.Lock()
+	draw.Draw(c.frame, c.frame.Bounds(), &image.Uniform{c.bg}, image.Point{}, draw.Src)
+	err := c.screen.DrawImage(c.frame, 0, 0)
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range c.panes {
+		wg.Add(1)
+		go func(p *compositorPane) {
+			defer wg.Done()
+			if err := p.monitor.Run(); err != nil {
+				c.logger.Error("pane failed", "pane", p.name, "error", err)
+			}
+		}(p)
+	}
+
+	c.logger.Info("started", "monitor", c.Name(), "panes", len(c.panes))
+
+	<-c.stop
+	for _, p := range c.panes {
+		p.monitor.Stop()
+	}
+	wg.Wait()
+	return nil
+}
+
+// Stop stops the compositor and all its panes.
+func (c *Compositor) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// Pause pauses every pane.
+func (c *Compositor) Pause() {
+	for _, p := range c.panes {
+		pause(p.monitor)
+	}
+}
+
+// Resume resumes every pane.
+func (c *Compositor) Resume() {
+	for _, p := range c.panes {
+		resume(p.monitor)
+	}
+}
+
+// RequestRedraw sends the whole shared frame to the screen.
+func (c *Compositor) RequestRedraw() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.screen.DrawImage(c.frame, 0, 0); err != nil {
+		c.logger.Error("redraw failed", "monitor", c.Name(), "error", err)
+	}
+}
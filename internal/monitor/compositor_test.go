@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+)
+
+func TestSplitColumns(t *testing.T) {
+	got := SplitColumns(Region{0, 0, 480, 320}, 4, 1, 1)
+	want := []Region{{0, 0, 238, 320}, {242, 0, 238, 320}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitColumns() = %v, want %v", got, want)
+	}
+
+	got = SplitRows(Region{10, 0, 100, 300}, 0, 2, 1)
+	want = []Region{{10, 0, 100, 200}, {10, 200, 100, 100}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitRows() = %v, want %v", got, want)
+	}
+}
+
+func TestCompositorTranslatesDraws(t *testing.T) {
+	screen := newRecordingScreen(320, 480) // Landscape: 480x320
+	c := NewCompositor(CompositorConfig{Screen: screen})
+	cols := SplitColumns(Region{0, 0, c.Width(), c.Height()}, 0, 1, 1)
+
+	var panes []lcd.Screen
+	for i, reg := range cols {
+		name := []string{"cpu", "agents"}[i]
+		if err := c.AddPane(name, reg, func(s lcd.Screen) Monitor {
+			panes = append(panes, s)
+			return newFakeMonitor(name, s)
+		}); err != nil {
+			t.Fatalf("AddPane(%s) error = %v", name, err)
+		}
+	}
+
+	if err := c.AddPane("overlap", Region{200, 0, 100, 100}, func(s lcd.Screen) Monitor {
+		return newFakeMonitor("overlap", s)
+	}); err == nil {
+		t.Error("AddPane() accepted an overlapping pane")
+	}
+
+	if panes[1].Width() != 240 || panes[1].Height() != 320 {
+		t.Errorf("pane size = %dx%d, want 240x320", panes[1].Width(), panes[1].Height())
+	}
+
+	red := color.RGBA{255, 0, 0, 255}
+	img := image.NewRGBA(image.Rect(0, 0, 300, 10))
+	draw.Draw(img, img.Bounds(), &image.Uniform{red}, image.Point{}, draw.Src)
+
+	// Draw that runs past the right edge of the second pane
+	if err := panes[1].DrawImage(img, 10, 5); err != nil {
+		t.Fatalf("DrawImage() error = %v", err)
+	}
+
+	want := image.Rect(250, 5, 480, 15)
+	if len(screen.draws) != 1 || screen.draws[0] != want {
+		t.Errorf("screen draws = %v, want [%v]", screen.draws, want)
+	}
+	if got := c.frame.RGBAAt(250, 5); got != red {
+		t.Errorf("frame pixel = %v, want red", got)
+	}
+	if got := c.frame.RGBAAt(249, 5); got == red {
+		t.Error("draw leaked outside the pane")
+	}
+}
+
+func TestCompositorStopBeforeRun(t *testing.T) {
+	c := NewCompositor(CompositorConfig{Screen: newRecordingScreen(320, 480)})
+	if err := c.AddPane("cpu", Region{0, 0, c.Width(), c.Height()}, func(s lcd.Screen) Monitor {
+		return newFakeMonitor("cpu", s)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	c.Stop()
+	c.Stop() // Repeated stops are harmless
+	done := make(chan error)
+	go func() { done <- c.Run() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() after Stop() did not return")
+	}
+}
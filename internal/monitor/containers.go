@@ -0,0 +1,339 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func init() {
+	Register("containers", func() ContainerOptions { return ContainerOptions{Sort: "cpu"} },
+		func(p Params, opts ContainerOptions) (Monitor, error) {
+			if opts.Sort != "cpu" && opts.Sort != "memory" && opts.Sort != "name" {
+				return nil, fmt.Errorf("sort: unknown value %q (want cpu, memory or name)", opts.Sort)
+			}
+			for _, g := range opts.Exclude {
+				if _, err := path.Match(g, ""); err != nil {
+					return nil, fmt.Errorf("pattern %q: %w", g, err)
+				}
+			}
+			m := NewContainerMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+			if opts.Socket != "" {
+				m.readContainers = sysinfo.NewDockerClient(opts.Socket).Containers
+			}
+			m.sortBy, m.exclude = opts.Sort, opts.Exclude
+			return m, nil
+		})
+}
+
+// ContainerOptions are the config options for the "containers" monitor.
+type ContainerOptions struct {
+	Socket  string   `json:"socket,omitempty"`  // Docker or Podman API socket; found automatically if empty
+	Sort    string   `json:"sort"`              // cpu, memory or name
+	Exclude []string `json:"exclude,omitempty"` // Container name globs to hide
+}
+
+const (
+	containerRowHeight = 36
+	containerTimeout   = 5 * time.Second // Limit on one round of API requests
+	restartHighlight   = 5 * time.Minute // Time a restarted container stays highlighted
+)
+
+// ContainerMonitor displays running Docker or Podman containers with their
+// health and resource use, unhealthy and restarting ones first.
+type ContainerMonitor struct {
+	*Base
+	sortBy  string
+	exclude []string
+
+	sampler   *sysinfo.ContainerSampler
+	restarted map[string]time.Time // Last restart seen per container ID
+	numRows   int
+
+	ctx    context.Context // Cancelled by Stop to abort API requests
+	cancel context.CancelFunc
+
+	// Readers, replaceable in tests
+	readContainers func(ctx context.Context) ([]sysinfo.ContainerInfo, error)
+	now            func() time.Time
+}
+
+// NewContainerMonitor creates a new container monitor using the API socket
+// found by sysinfo.DockerSocket.
+func NewContainerMonitor(screen lcd.Screen, brightness int, interval time.Duration, logger *slog.Logger) *ContainerMonitor {
+	base := NewBase(Config{
+		Screen:   screen,
+		Theme:    DefaultTheme(),
+		Fonts:    DefaultFontConfig(),
+		Interval: interval,
+		Logger:   logger,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ContainerMonitor{
+		Base:           base,
+		sortBy:         "cpu",
+		sampler:        sysinfo.NewContainerSampler(),
+		restarted:      make(map[string]time.Time),
+		ctx:            ctx,
+		cancel:         cancel,
+		readContainers: sysinfo.NewDockerClient(sysinfo.DockerSocket()).Containers,
+		now:            time.Now,
+	}
+}
+
+// Name returns the monitor name.
+func (m *ContainerMonitor) Name() string { return "Containers" }
+
+// Run starts the container monitor loop.
+func (m *ContainerMonitor) Run() error {
+	m.SetRunning(true)
+
+	// Calculate layout
+	m.setupLayout()
+
+	// Initial draw
+	m.ClearBuffer()
+	m.drawStatic()
+	if err := m.DrawFullBuffer(); err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	m.Logger().Info("started", "monitor", m.Name())
+
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+
+	for m.Running() {
+		select {
+		case <-ticker.C:
+			if err := m.Tick(m.update); err != nil {
+				m.Logger().Error("update failed", "error", err)
+			}
+		case <-m.ctx.Done():
+			// Stopped, possibly before Run set running
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the monitor and aborts API requests in flight.
+func (m *ContainerMonitor) Stop() {
+	m.SetRunning(false)
+	m.cancel()
+}
+
+func (m *ContainerMonitor) setupLayout() {
+	m.numRows = (m.Height() - 40) / containerRowHeight
+}
+
+// rowRegion returns the region of row i.
+func (m *ContainerMonitor) rowRegion(i int) Region {
+	return Region{0, 40 + i*containerRowHeight, m.Width(), containerRowHeight - 2}
+}
+
+// bodyRegion is where the API error is shown when containers can't be
+// listed.
+func (m *ContainerMonitor) bodyRegion() Region {
+	return Region{5, 40, m.Width() - 10, m.Height() - 45}
+}
+
+func (m *ContainerMonitor) drawStatic() {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	r.DrawText(5, 8, "Containers", m.fonts.Large, m.Colors().Header)
+	r.DrawLine(0, 34, float64(m.Width()))
+}
+
+// needsAttention reports whether a container is unhealthy, restarting or
+// restarted recently, so is listed first and highlighted.
+func (m *ContainerMonitor) needsAttention(c sysinfo.ContainerRate) bool {
+	at, ok := m.restarted[c.ID]
+	return c.Unhealthy() || (ok && m.now().Sub(at) < restartHighlight)
+}
+
+// shownContainers filters containers by name and sorts them, those
+// needing attention first.
+func (m *ContainerMonitor) shownContainers(rates []sysinfo.ContainerRate) []sysinfo.ContainerRate {
+	var shown []sysinfo.ContainerRate
+	for _, c := range rates {
+		if !matchAny(m.exclude, c.Name) {
+			shown = append(shown, c)
+		}
+	}
+	sort.SliceStable(shown, func(i, j int) bool {
+		a, b := shown[i], shown[j]
+		if m.needsAttention(a) != m.needsAttention(b) {
+			return m.needsAttention(a)
+		}
+		switch {
+		case m.sortBy == "memory" && a.MemUsage != b.MemUsage:
+			return a.MemUsage > b.MemUsage
+		case m.sortBy == "cpu" && a.CPUPercent != b.CPUPercent:
+			return a.CPUPercent > b.CPUPercent
+		}
+		return a.Name < b.Name
+	})
+	return shown
+}
+
+func (m *ContainerMonitor) update() error {
+	ctx, cancel := context.WithTimeout(m.ctx, containerTimeout)
+	containers, apiErr := m.readContainers(ctx)
+	cancel()
+	if m.ctx.Err() != nil {
+		return nil
+	}
+
+	// A failed request keeps the previous sample for the next rates
+	var rates []sysinfo.ContainerRate
+	if apiErr == nil {
+		rates = m.sampler.Sample(containers, m.now())
+	}
+	for _, c := range rates {
+		if c.Restarted {
+			m.restarted[c.ID] = m.now()
+		}
+	}
+	for id, at := range m.restarted {
+		if m.now().Sub(at) >= restartHighlight {
+			delete(m.restarted, id)
+		}
+	}
+	shown := m.shownContainers(rates)
+
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	var updates []Region
+
+	// Summary of running and troubled containers
+	summary, attention := "", 0
+	for _, c := range shown {
+		if m.needsAttention(c) {
+			attention++
+		}
+	}
+	if apiErr == nil {
+		summary = fmt.Sprintf("%d running", len(shown))
+		if attention > 0 {
+			summary += fmt.Sprintf(" · %d failing", attention)
+		}
+	}
+	if m.Changed("summary", summary) {
+		reg := Region{180, 8, m.Width() - 185, 24}
+		r.Clear(reg)
+		c := m.Colors().TextDim
+		if attention > 0 {
+			c = m.Colors().BarHigh
+		}
+		r.DrawTextRightFit(float64(reg.X), float64(reg.Y)+2, float64(reg.W), summary, m.fonts.Normal, c, EllipsisEnd)
+		updates = append(updates, reg)
+	}
+
+	// The error replaces the rows until the API answers again
+	if apiErr != nil {
+		if m.Changed("error", apiErr.Error()) {
+			body := m.bodyRegion()
+			r.Clear(body)
+			r.DrawWrapped(float64(body.X), float64(body.Y), float64(body.W), apiErr.Error(),
+				m.fonts.Normal, m.fonts.Normal+4, body.H/int(m.fonts.Normal+4), m.Colors().BarHigh)
+			updates = append(updates, body)
+			for i := 0; i < m.numRows; i++ {
+				m.Forget(fmt.Sprintf("container_%d", i))
+			}
+		}
+	} else {
+		if m.Changed("error", "") {
+			body := m.bodyRegion()
+			r.Clear(body)
+			updates = append(updates, body)
+		}
+		for i := 0; i < m.numRows; i++ {
+			reg := m.rowRegion(i)
+			key := fmt.Sprintf("container_%d", i)
+			if i >= len(shown) {
+				if m.Changed(key, "") {
+					r.Clear(reg)
+					updates = append(updates, reg)
+				}
+				continue
+			}
+			c := shown[i]
+			if m.Changed(key, fmt.Sprint(c.Name, c.State, c.Health, c.RestartCount, m.needsAttention(c),
+				int(c.CPUPercent), sysinfo.FormatBytes(c.MemUsage), c.MemLimit, c.HasStats)) {
+				m.renderRow(r, reg, c)
+				updates = append(updates, reg)
+			}
+		}
+	}
+
+	// Push updates to display
+	for _, reg := range updates {
+		if err := m.DrawRegion(reg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// containerStatus returns the health or state shown for a container, ""
+// when it's running without a health check.
+func containerStatus(c sysinfo.ContainerInfo) string {
+	if c.State != "running" {
+		return c.State
+	}
+	return c.Health
+}
+
+// renderRow draws a container's name, CPU use and memory, with its image,
+// restarts and health underneath. Containers needing attention are named
+// in the high color, and those whose health check is starting in medium.
+func (m *ContainerMonitor) renderRow(r *Renderer, reg Region, c sysinfo.ContainerRate) {
+	r.Clear(reg)
+	colors := m.Colors()
+	y := float64(reg.Y)
+
+	nameColor, statusColor := colors.Text, colors.TextDim
+	switch {
+	case m.needsAttention(c):
+		nameColor, statusColor = colors.BarHigh, colors.BarHigh
+	case c.Health == "starting":
+		statusColor = colors.BarMed
+	case c.Health == "healthy":
+		statusColor = colors.BarLow
+	}
+
+	r.DrawTextFit(5, y, 215, c.Name, m.fonts.Normal, nameColor, EllipsisMiddle)
+	cpu := "–"
+	if c.HasStats {
+		cpu = fmt.Sprintf("%.0f%%", c.CPUPercent)
+	}
+	r.DrawTextRight(220, y, 80, cpu, m.fonts.Normal, colors.Text)
+	if c.HasStats {
+		r.DrawTextRightFit(305, y, float64(reg.W-310), sysinfo.FormatBytes(c.MemUsage), m.fonts.Normal, colors.Text, EllipsisEnd)
+	}
+
+	var detail []string
+	detail = append(detail, c.Image)
+	if c.RestartCount > 0 {
+		detail = append(detail, fmt.Sprintf("%d restarts", c.RestartCount))
+	}
+	r.DrawTextFit(5, y+18, 215, strings.Join(detail, " · "), m.fonts.Small-2, colors.TextDim, EllipsisMiddle)
+	r.DrawTextRightFit(220, y+18, 90, containerStatus(c.ContainerInfo), m.fonts.Small-2, statusColor, EllipsisEnd)
+	if c.HasStats && c.MemLimit > 0 {
+		pct := float64(c.MemUsage) / float64(c.MemLimit) * 100
+		r.DrawBarStyled(Region{315, reg.Y + 20, reg.W - 320, 10}, pct, 0, 100, BarStyle{Border: true})
+	}
+}
@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func TestContainerMonitorUpdate(t *testing.T) {
+	m := NewContainerMonitor(newRecordingScreen(320, 480), 50, time.Second, nil)
+	now := time.Unix(1000, 0)
+	m.now = func() time.Time { return now }
+	containers := []sysinfo.ContainerInfo{
+		{ID: "a", Name: "web", State: "running", Health: "healthy", HasStats: true, CPUUsage: 1e9, MemUsage: 100 << 20},
+		{ID: "b", Name: "db", State: "running", HasStats: true, CPUUsage: 1e9, MemUsage: 900 << 20},
+		{ID: "c", Name: "api", State: "running", Health: "unhealthy", MemUsage: 50 << 20},
+		{ID: "d", Name: "cron", State: "running"},
+	}
+	var apiErr error
+	m.readContainers = func(context.Context) ([]sysinfo.ContainerInfo, error) {
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		return containers, nil
+	}
+	m.exclude = []string{"cron"}
+	m.setupLayout()
+
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// db restarts and joins the unhealthy api on top, above the busier web
+	now = now.Add(time.Second)
+	containers[0].CPUUsage, containers[1].RestartCount = 3e9, 1
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	rates := []sysinfo.ContainerRate{
+		{ContainerInfo: containers[0], CPUPercent: 200},
+		{ContainerInfo: containers[1]},
+		{ContainerInfo: containers[2]},
+		{ContainerInfo: containers[3]},
+	}
+	var order []string
+	for _, c := range m.shownContainers(rates) {
+		order = append(order, c.Name)
+	}
+	if want := []string{"api", "db", "web"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+	if m.cache["summary"] != "3 running · 2 failing" {
+		t.Errorf("summary = %q", m.cache["summary"])
+	}
+
+	// The restart highlight wears off
+	now = now.Add(restartHighlight + time.Second)
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if m.cache["summary"] != "3 running · 1 failing" {
+		t.Errorf("summary after the highlight = %q", m.cache["summary"])
+	}
+
+	// API errors replace the rows, which come back afterwards
+	apiErr = errors.New("dial unix /var/run/docker.sock: connect: permission denied")
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if m.cache["error"] != apiErr.Error() || m.cache["container_0"] != nil {
+		t.Errorf("error = %q, row 0 cached as %v", m.cache["error"], m.cache["container_0"])
+	}
+	apiErr = nil
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if m.cache["error"] != "" || m.cache["container_0"] == nil {
+		t.Errorf("error = %q, row 0 cached as %v after recovery", m.cache["error"], m.cache["container_0"])
+	}
+}
+
+func TestContainerMonitorStopBeforeRun(t *testing.T) {
+	m := NewContainerMonitor(newRecordingScreen(320, 480), 50, time.Hour, nil)
+	m.Stop()
+	done := make(chan error)
+	go func() { done <- m.Run() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() after Stop() did not return")
+	}
+}
@@ -3,12 +3,58 @@ package monitor
 import (
 	"fmt"
 	"log/slog"
+	"path"
 	"time"
 
 	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
 	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
 )
 
+func init() {
+	Register("cpu", func() CPUOptions { return CPUOptions{Layout: "auto"} },
+		func(p Params, opts CPUOptions) (Monitor, error) {
+			layout, ok := cpuLayouts[opts.Layout]
+			if !ok {
+				return nil, fmt.Errorf("layout: unknown value %q (want auto, bars or heatmap)", opts.Layout)
+			}
+			if _, err := path.Match(opts.TempSensor, ""); err != nil {
+				return nil, fmt.Errorf("temp_sensor: %w", err)
+			}
+			m := NewCPUMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+			m.SetLayout(layout)
+			m.SetTempSensor(opts.TempSensor)
+			if opts.TopProcesses < 0 {
+				return nil, fmt.Errorf("top_processes: must not be negative, got %d", opts.TopProcesses)
+			}
+			if limit := m.maxTopProcesses(); opts.TopProcesses > limit {
+				return nil, fmt.Errorf("top_processes: %d rows don't fit the screen, at most %d", opts.TopProcesses, limit)
+			}
+			m.SetTopProcesses(opts.TopProcesses)
+			grouper, err := newProcessGrouper(opts.ProcessGroupOptions)
+			if err != nil {
+				return nil, err
+			}
+			m.SetProcessGrouper(grouper)
+			return m, nil
+		})
+}
+
+// Per-CPU bar layout, shared by setupLayout, barsFit and updateBars.
+const (
+	cpuBarsY        = 68 // Top of the first row of bars
+	cpuBarMinHeight = 12
+	cpuBarMaxHeight = 35
+	cpuBarSpacing   = 3 // Gap between rows of bars
+)
+
+// CPUOptions are the config options for the "cpu" monitor.
+type CPUOptions struct {
+	Layout       string `json:"layout"`                  // auto, bars or heatmap
+	TempSensor   string `json:"temp_sensor,omitempty"`   // Sensor selector for the header temperature
+	TopProcesses int    `json:"top_processes,omitempty"` // Rows of the busiest process groups, 0 for none
+	ProcessGroupOptions
+}
+
 // CPUMonitor displays CPU usage information.
 type CPUMonitor struct {
 	*Base
@@ -16,6 +62,27 @@ type CPUMonitor struct {
 	overallY  int
 	cols      int
 	barHeight int
+	history   *History
+
+	// Heatmap layout
+	layout  CPULayout
+	heatmap bool
+	cells   []Region
+	blocks  []heatBlock
+
+	tempSensor string
+
+	// Throttle counters of the previous sample, and which CPUs throttled
+	// since then
+	prevThrottles []uint64
+	throttled     []bool
+
+	// Rows of the top process groups table
+	topN int
+
+	// Readers, replaceable in tests
+	readCPU   func() (*sysinfo.CPUInfo, error)
+	readProcs func(n int) ([]sysinfo.ProcessCPUInfo, error)
 }
 
 // NewCPUMonitor creates a new CPU monitor.
@@ -27,24 +94,33 @@ func NewCPUMonitor(screen lcd.Screen, brightness int, interval time.Duration, lo
 
 	base := NewBase(Config{
 		Screen:   screen,
-		Colors:   DefaultColors(),
+		Theme:    DefaultTheme(),
 		Fonts:    fonts,
 		Interval: interval,
 		Logger:   logger,
 	})
 
-	return &CPUMonitor{Base: base}
+	return &CPUMonitor{
+		Base:      base,
+		readCPU:   sysinfo.GetCPUInfo,
+		readProcs: sysinfo.GetTopCPUProcesses,
+	}
 }
 
 // Name returns the monitor name.
 func (m *CPUMonitor) Name() string { return "CPU" }
 
+// SetTempSensor selects the sensor shown as the CPU temperature, as a
+// "chip/label" glob such as "k10temp/Tdie". Empty uses the CPU driver's
+// package sensor.
+func (m *CPUMonitor) SetTempSensor(selector string) { m.tempSensor = selector }
+
 // Run starts the CPU monitor loop.
 func (m *CPUMonitor) Run() error {
 	m.SetRunning(true)
 	
 	// Get initial CPU info
-	info, err := sysinfo.GetCPUInfo()
+	info, err := m.readCPU()
 	if err != nil {
 		return fmt.Errorf("get cpu info: %w", err)
 	}
@@ -68,7 +144,7 @@ func (m *CPUMonitor) Run() error {
 	for m.Running() {
 		select {
 		case <-ticker.C:
-			if err := m.update(); err != nil {
+			if err := m.Tick(m.update); err != nil {
 				m.Logger().Error("update failed", "error", err)
 			}
 		}
@@ -93,48 +169,77 @@ func (m *CPUMonitor) setupLayout() {
 		m.cols = 4
 	}
 
+	m.overallY = m.Height() - 35
+
 	// Calculate bar height
-	yOffset := 68
-	availableHeight := m.Height() - yOffset - 40
 	rows := (m.cpuCount + m.cols - 1) / m.cols
-	barSpacing := 3
-	m.barHeight = (availableHeight - 30) / rows
-	if m.barHeight < 12 {
-		m.barHeight = 12
+	m.barHeight = (m.barsAreaHeight()+cpuBarSpacing)/rows - cpuBarSpacing
+	if m.barHeight < cpuBarMinHeight {
+		m.barHeight = cpuBarMinHeight
 	}
-	if m.barHeight > 35 {
-		m.barHeight = 35
+	if m.barHeight > cpuBarMaxHeight {
+		m.barHeight = cpuBarMaxHeight
 	}
-	_ = barSpacing
 
-	m.overallY = m.Height() - 35
+	// Fall back to a heatmap when bars would overflow the panel
+	m.heatmap = m.layout == CPULayoutHeatmap || (m.layout == CPULayoutAuto && !m.barsFit())
+	if m.heatmap {
+		m.setupHeatmap()
+	}
+
+	// One sample per pixel: a few minutes of history at typical intervals
+	m.history = NewHistory(m.graphRegion().W)
+}
+
+// overallBarRegion returns the region of the overall usage bar.
+func (m *CPUMonitor) overallBarRegion() Region {
+	return Region{45, m.overallY, (m.Width() - 120) / 2, 24}
+}
+
+// graphRegion returns the region of the overall usage history graph.
+func (m *CPUMonitor) graphRegion() Region {
+	bar := m.overallBarRegion()
+	x := bar.X + bar.W + 6
+	return Region{x, m.overallY, m.Width() - 75 - x, 24}
 }
 
 func (m *CPUMonitor) drawStatic() {
 	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
-	r := NewRenderer(dc, m.Colors(), m.fonts)
+	r := NewRenderer(dc, m.Theme(), m.fonts)
 
 	// Separator lines
 	r.DrawLine(0, 35, float64(m.Width()))
 	r.DrawLine(0, float64(m.overallY-5), float64(m.Width()))
+	if m.topN > 0 {
+		r.DrawLine(0, float64(m.procTableY()-4), float64(m.Width()))
+	}
 
 	// "ALL" label
 	r.DrawText(5, float64(m.overallY), "ALL", m.fonts.Normal, m.Colors().Header)
+
+	if m.heatmap {
+		m.drawHeatmapStatic(r)
+	}
 }
 
 func (m *CPUMonitor) update() error {
-	info, err := sysinfo.GetCPUInfo()
+	info, err := m.readCPU()
 	if err != nil {
 		return err
 	}
+	if m.tempSensor != "" {
+		info.Temp, _ = sysinfo.CPUTemp(info.Sensors, m.tempSensor)
+	}
 
 	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
-	r := NewRenderer(dc, m.Colors(), m.fonts)
+	r := NewRenderer(dc, m.Theme(), m.fonts)
 
 	var updates []Region
 
+	m.updateThrottles(info.Cores)
+
 	// Header
-	header := fmt.Sprintf("CPU Monitor - %d cores", info.CoreCount)
+	header := fmt.Sprintf("CPU Monitor - %s cores", coreCounts(info))
 	if info.Temp > 0 {
 		header += fmt.Sprintf(" | %.0f°C", info.Temp)
 	}
@@ -145,12 +250,18 @@ func (m *CPUMonitor) update() error {
 		updates = append(updates, reg)
 	}
 
-	// Frequency
-	freqStr := fmt.Sprintf("Freq: %.2f GHz", info.Freq)
-	if m.ChangedFloat("freq", info.Freq, 0.05) {
+	// Frequency, with the governor or a throttling warning after it
+	freqStr := fmt.Sprintf("%.2f GHz", info.Freq)
+	note, noteColor := info.Governor, m.Colors().TextDim
+	if m.anyThrottled() {
+		note, noteColor = "throttled", m.Colors().BarHigh
+	}
+	if m.Changed("freq", freqStr+" "+note) {
 		reg := Region{5, 38, 180, 20}
 		r.Clear(reg)
 		r.DrawText(float64(reg.X), float64(reg.Y), freqStr, m.fonts.Normal, m.Colors().TextDim)
+		w := r.MeasureText(freqStr+" ", m.fonts.Normal)
+		r.DrawTextFit(float64(reg.X)+w, float64(reg.Y)+3, float64(reg.W)-w, note, m.fonts.Small-2, noteColor, EllipsisEnd)
 		updates = append(updates, reg)
 	}
 
@@ -163,38 +274,32 @@ func (m *CPUMonitor) update() error {
 		updates = append(updates, reg)
 	}
 
-	// Per-CPU bars
-	yOffset := 68
-	colWidth := (m.Width() - 10) / m.cols
-	pctWidth := 38
-	barWidth := colWidth - pctWidth - 8
-	barSpacing := 3
-
-	for i, pct := range info.PerCPU {
-		col := i % m.cols
-		row := i / m.cols
-		x := 5 + col*colWidth
-		y := yOffset + row*(m.barHeight+barSpacing)
-
-		key := fmt.Sprintf("cpu_%d", i)
-		if m.ChangedFloat(key, pct, 2.0) {
-			// Percentage text
-			pctReg := Region{x, y + (m.barHeight-18)/2, pctWidth, 20}
-			r.Clear(pctReg)
-			r.DrawTextRight(float64(pctReg.X), float64(pctReg.Y), float64(pctReg.W),
-				fmt.Sprintf("%3.0f%%", pct), m.fonts.Small, m.Colors().Text)
-			updates = append(updates, pctReg)
+	// Per-CPU usage
+	if m.heatmap {
+		updates = append(updates, m.updateHeatmap(r, info)...)
+	} else {
+		updates = append(updates, m.updateBars(r, info)...)
+	}
 
-			// Bar
-			barReg := Region{x + pctWidth + 4, y, barWidth, m.barHeight - 1}
-			r.DrawBar(barReg, pct, 0, 100, true)
-			updates = append(updates, barReg)
+	// Busiest process groups
+	if m.topN > 0 {
+		procUpdates, err := m.updateProcesses(r)
+		if err != nil {
+			return err
 		}
+		updates = append(updates, procUpdates...)
 	}
 
+	// Overall history graph
+	m.history.Push(info.Overall)
+	graphReg := m.graphRegion()
+	m.UpdateGraph(r, "overall_graph", graphReg, m.history.Values(),
+		GraphStyle{Kind: GraphArea, Min: 0, Max: 100})
+	updates = append(updates, graphReg)
+
 	// Overall bar
 	if m.ChangedFloat("overall", info.Overall, 1.0) {
-		barReg := Region{45, m.overallY, m.Width() - 120, 24}
+		barReg := m.overallBarRegion()
 		r.DrawBar(barReg, info.Overall, 0, 100, true)
 		updates = append(updates, barReg)
 
@@ -218,3 +323,144 @@ func (m *CPUMonitor) update() error {
 
 	return nil
 }
+
+// updateBars redraws the bars of CPUs whose usage changed. Hybrid CPUs get
+// a P or E label per bar, and each bar shows its live frequency when there's
+// room; both turn red while the CPU is being throttled.
+func (m *CPUMonitor) updateBars(r *Renderer, info *sysinfo.CPUInfo) []Region {
+	var updates []Region
+	colWidth := (m.Width() - 10) / m.cols
+	typeWidth := 0
+	if isHybrid(info.Cores) {
+		typeWidth = 14
+	}
+	freqWidth := 0
+	if info.Cores != nil && colWidth >= 200 {
+		freqWidth = 42
+	}
+	pctWidth := 38
+	barWidth := colWidth - typeWidth - pctWidth - freqWidth - 8
+
+	for i, pct := range info.PerCPU {
+		col := i % m.cols
+		row := i / m.cols
+		x := 5 + col*colWidth
+		y := cpuBarsY + row*(m.barHeight+cpuBarSpacing)
+		textY := y + (m.barHeight-18)/2
+
+		var core sysinfo.CPUCore
+		if i < len(info.Cores) {
+			core = info.Cores[i]
+		}
+		throttled := m.isThrottled(i)
+		textColor := m.Colors().Text
+		if throttled {
+			textColor = m.Colors().BarHigh
+		}
+
+		key := fmt.Sprintf("cpu_%d", i)
+		metaChanged := m.Changed(key+"_meta", fmt.Sprintf("%s %.1f %t", core.Type, core.FreqMHz/1000, throttled))
+		pctChanged := m.ChangedFloat(key, pct, 2.0)
+
+		if metaChanged && typeWidth > 0 {
+			typeReg := Region{x, textY, typeWidth, 20}
+			r.Clear(typeReg)
+			typeColor := textColor
+			if core.Type == sysinfo.CoreEfficiency && !throttled {
+				typeColor = m.Colors().TextDim
+			}
+			r.DrawText(float64(typeReg.X), float64(typeReg.Y), core.Type.String(), m.fonts.Small, typeColor)
+			updates = append(updates, typeReg)
+		}
+
+		if metaChanged || pctChanged {
+			// Percentage text
+			pctReg := Region{x + typeWidth, textY, pctWidth, 20}
+			r.Clear(pctReg)
+			r.DrawTextRight(float64(pctReg.X), float64(pctReg.Y), float64(pctReg.W),
+				fmt.Sprintf("%3.0f%%", pct), m.fonts.Small, textColor)
+			updates = append(updates, pctReg)
+		}
+
+		if pctChanged {
+			// Bar
+			barReg := Region{x + typeWidth + pctWidth + 4, y, barWidth, m.barHeight - 1}
+			r.DrawBar(barReg, pct, 0, 100, true)
+			updates = append(updates, barReg)
+		}
+
+		if metaChanged && freqWidth > 0 {
+			freqReg := Region{x + colWidth - freqWidth - 4, textY, freqWidth, 20}
+			r.Clear(freqReg)
+			if core.FreqMHz > 0 {
+				r.DrawTextRight(float64(freqReg.X), float64(freqReg.Y), float64(freqReg.W),
+					fmt.Sprintf("%.1f", core.FreqMHz/1000), m.fonts.Small, m.Colors().TextDim)
+			}
+			updates = append(updates, freqReg)
+		}
+	}
+
+	return updates
+}
+
+// updateThrottles compares each CPU's throttle counters with the previous
+// sample.
+func (m *CPUMonitor) updateThrottles(cores []sysinfo.CPUCore) {
+	m.throttled = make([]bool, len(cores))
+	if len(m.prevThrottles) == len(cores) {
+		for i, c := range cores {
+			m.throttled[i] = c.Throttles > m.prevThrottles[i]
+		}
+	}
+	m.prevThrottles = make([]uint64, len(cores))
+	for i, c := range cores {
+		m.prevThrottles[i] = c.Throttles
+	}
+}
+
+// isThrottled reports whether CPU i throttled since the previous sample.
+func (m *CPUMonitor) isThrottled(i int) bool {
+	return i < len(m.throttled) && m.throttled[i]
+}
+
+// anyThrottled reports whether any CPU throttled since the previous sample.
+func (m *CPUMonitor) anyThrottled() bool {
+	for _, t := range m.throttled {
+		if t {
+			return true
+		}
+	}
+	return false
+}
+
+// isHybrid reports whether cores include both performance and efficiency
+// cores.
+func isHybrid(cores []sysinfo.CPUCore) bool {
+	var p, e bool
+	for _, c := range cores {
+		switch c.Type {
+		case sysinfo.CorePerformance:
+			p = true
+		case sysinfo.CoreEfficiency:
+			e = true
+		}
+	}
+	return p && e
+}
+
+// coreCounts describes the number of logical CPUs, split into P and E cores
+// on hybrid CPUs, e.g. "16P+8E".
+func coreCounts(info *sysinfo.CPUInfo) string {
+	if !isHybrid(info.Cores) {
+		return fmt.Sprint(info.CoreCount)
+	}
+	var p, e int
+	for _, c := range info.Cores {
+		if c.Type == sysinfo.CoreEfficiency {
+			e++
+		} else {
+			p++
+		}
+	}
+	return fmt.Sprintf("%dP+%dE", p, e)
+}
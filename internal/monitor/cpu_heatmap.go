@@ -0,0 +1,220 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+// CPULayout selects how CPUMonitor shows per-CPU usage.
+type CPULayout int
+
+const (
+	CPULayoutAuto    CPULayout = iota // Bars, or a heatmap when bars won't fit
+	CPULayoutBars                     // One bar per logical CPU
+	CPULayoutHeatmap                  // One colored cell per logical CPU
+)
+
+// cpuLayouts maps config names to layouts.
+var cpuLayouts = map[string]CPULayout{
+	"auto":    CPULayoutAuto,
+	"bars":    CPULayoutBars,
+	"heatmap": CPULayoutHeatmap,
+}
+
+// heatmapGap is the spacing between heatmap cells.
+const heatmapGap = 2
+
+// heatBlock is the group of heatmap cells for one CPU package.
+type heatBlock struct {
+	pkg   int
+	label Region
+}
+
+// SetLayout selects bars, heatmap or automatic per-CPU layout.
+// It must be called before Run.
+func (m *CPUMonitor) SetLayout(l CPULayout) { m.layout = l }
+
+// barsAreaHeight returns the height available to per-CPU bars, down to the
+// separator above the process table or the overall bar.
+func (m *CPUMonitor) barsAreaHeight() int {
+	return m.overallY - 6 - cpuBarsY - m.procTableHeight()
+}
+
+// barsFit reports whether per-CPU bars fit the panel at their minimum height.
+func (m *CPUMonitor) barsFit() bool {
+	rows := (m.cpuCount + m.cols - 1) / m.cols
+	return rows > 0 && rows*(cpuBarMinHeight+cpuBarSpacing)-cpuBarSpacing <= m.barsAreaHeight()
+}
+
+// legendRegion returns the region of the heatmap color legend.
+func (m *CPUMonitor) legendRegion() Region {
+	return Region{5, m.overallY - 28 - m.procTableHeight(), m.Width() - 10, 18}
+}
+
+// setupHeatmap assigns a cell to each logical CPU, keeping the hardware
+// threads of a physical core next to each other and each package in its own
+// block.
+func (m *CPUMonitor) setupHeatmap() {
+	type cpuPos struct{ cpu, core, pkg int }
+	pos := make([]cpuPos, m.cpuCount)
+	for i := range pos {
+		pos[i] = cpuPos{cpu: i, core: i}
+	}
+	if topo, err := sysinfo.GetCPUTopology(); err == nil {
+		for _, t := range topo {
+			if t.CPU < len(pos) {
+				pos[t.CPU] = cpuPos{cpu: t.CPU, core: t.Core, pkg: t.Package}
+			}
+		}
+	}
+	sort.SliceStable(pos, func(i, j int) bool {
+		if pos[i].pkg != pos[j].pkg {
+			return pos[i].pkg < pos[j].pkg
+		}
+		if pos[i].core != pos[j].core {
+			return pos[i].core < pos[j].core
+		}
+		return pos[i].cpu < pos[j].cpu
+	})
+
+	// Threads per core, used to keep siblings on one row
+	threads := make(map[[2]int]int)
+	group := 1
+	var pkgs []int
+	pkgSize := make(map[int]int)
+	for _, p := range pos {
+		key := [2]int{p.pkg, p.core}
+		threads[key]++
+		if threads[key] > group {
+			group = threads[key]
+		}
+		if pkgSize[p.pkg] == 0 {
+			pkgs = append(pkgs, p.pkg)
+		}
+		pkgSize[p.pkg]++
+	}
+
+	area := Region{5, 68, m.Width() - 10, m.legendRegion().Y - 6 - 68}
+	labelW := 0
+	if len(pkgs) > 1 {
+		labelW = 28
+	}
+
+	m.cells = make([]Region, m.cpuCount)
+	m.blocks = nil
+	y := area.Y
+	next := 0
+	for bi, pkg := range pkgs {
+		n := pkgSize[pkg]
+		h := area.H * n / len(pos)
+		if bi == len(pkgs)-1 {
+			h = area.Y + area.H - y
+		}
+		block := Region{area.X + labelW, y, area.W - labelW, h - heatmapGap}
+		if labelW > 0 {
+			m.blocks = append(m.blocks, heatBlock{pkg: pkg, label: Region{area.X, y, labelW - 4, 20}})
+		}
+
+		cols, rows := heatmapGrid(n, group, block)
+		cellW := (block.W - heatmapGap*(cols-1)) / cols
+		cellH := (block.H - heatmapGap*(rows-1)) / rows
+		for i := 0; i < n; i++ {
+			col, row := i%cols, i/cols
+			m.cells[pos[next].cpu] = Region{
+				block.X + col*(cellW+heatmapGap),
+				block.Y + row*(cellH+heatmapGap),
+				cellW, cellH,
+			}
+			next++
+		}
+		y += h
+	}
+}
+
+// heatmapGrid picks the column count, a multiple of group, that gives the
+// largest cells for n cells in area.
+func heatmapGrid(n, group int, area Region) (cols, rows int) {
+	best := -1
+	cols, rows = n, 1
+	for c := group; c < n+group; c += group {
+		r := (n + c - 1) / c
+		w := (area.W - heatmapGap*(c-1)) / c
+		h := (area.H - heatmapGap*(r-1)) / r
+		size := w
+		if h < size {
+			size = h
+		}
+		if size > best {
+			best, cols, rows = size, c, r
+		}
+	}
+	return cols, rows
+}
+
+// drawHeatmapStatic draws the legend and package labels.
+func (m *CPUMonitor) drawHeatmapStatic(r *Renderer) {
+	leg := m.legendRegion()
+	r.DrawText(float64(leg.X), float64(leg.Y), "0%", m.fonts.Small, m.Colors().TextDim)
+	r.DrawTextRight(float64(leg.X), float64(leg.Y), float64(leg.W), "100%", m.fonts.Small, m.Colors().TextDim)
+
+	// Gradient strip between the labels
+	strip := Region{leg.X + 30, leg.Y + 5, leg.W - 80, leg.H - 8}
+	values := make([]float64, strip.W)
+	for i := range values {
+		values[i] = float64(i) / float64(strip.W-1) * 100
+	}
+	r.DrawGraph(strip, values, GraphStyle{Kind: GraphHeat, Min: 0, Max: 100})
+
+	for _, b := range m.blocks {
+		r.DrawText(float64(b.label.X), float64(b.label.Y), fmt.Sprintf("S%d", b.pkg), m.fonts.Small, m.Colors().Header)
+	}
+}
+
+// updateHeatmap redraws the cells of CPUs whose usage changed. E-cores get
+// a mark in their corner and throttling CPUs a red outline.
+func (m *CPUMonitor) updateHeatmap(r *Renderer, info *sysinfo.CPUInfo) []Region {
+	var updates []Region
+	scale := m.Theme().Scale()
+	for i, pct := range info.PerCPU {
+		if i >= len(m.cells) {
+			break
+		}
+		var core sysinfo.CPUCore
+		if i < len(info.Cores) {
+			core = info.Cores[i]
+		}
+		throttled := m.isThrottled(i)
+		key := fmt.Sprintf("cpu_%d", i)
+		metaChanged := m.Changed(key+"_meta", fmt.Sprintf("%s %t", core.Type, throttled))
+		if !m.ChangedFloat(key, pct, 2.0) && !metaChanged {
+			continue
+		}
+		cell := m.cells[i]
+		r.dc.SetColor(scale.Blend(pct))
+		r.dc.DrawRectangle(float64(cell.X), float64(cell.Y), float64(cell.W), float64(cell.H))
+		r.dc.Fill()
+
+		if core.Type == sysinfo.CoreEfficiency && cell.W >= 8 && cell.H >= 8 {
+			r.dc.SetColor(m.Colors().BG)
+			r.dc.DrawRectangle(float64(cell.X+1), float64(cell.Y+1), 3, 3)
+			r.dc.Fill()
+		}
+		if throttled {
+			r.dc.SetColor(m.Colors().BarHigh)
+			r.dc.SetLineWidth(1)
+			r.dc.DrawRectangle(float64(cell.X)+0.5, float64(cell.Y)+0.5, float64(cell.W-1), float64(cell.H-1))
+			r.dc.Stroke()
+		}
+
+		// Show the value when the cell is big enough to read
+		if cell.W >= 34 && cell.H >= 18 {
+			size := m.fonts.Small - 2
+			r.DrawTextRight(float64(cell.X), float64(cell.Y)+float64(cell.H)/2-size/2-2, float64(cell.W-3),
+				fmt.Sprintf("%.0f", pct), size, m.Colors().BG)
+		}
+		updates = append(updates, cell)
+	}
+	return updates
+}
@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeatmapGrid(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     int
+		group int
+		area  Region
+	}{
+		{"64 threads wide panel", 64, 2, Region{0, 0, 470, 180}},
+		{"96 threads", 96, 2, Region{0, 0, 470, 180}},
+		{"odd count no smt", 12, 1, Region{0, 0, 300, 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, rows := heatmapGrid(tt.n, tt.group, tt.area)
+			if cols%tt.group != 0 {
+				t.Errorf("cols = %d, not a multiple of %d", cols, tt.group)
+			}
+			if cols*rows < tt.n {
+				t.Errorf("grid %dx%d holds fewer than %d cells", cols, rows, tt.n)
+			}
+			cellW := (tt.area.W - heatmapGap*(cols-1)) / cols
+			cellH := (tt.area.H - heatmapGap*(rows-1)) / rows
+			if cellW < 4 || cellH < 4 {
+				t.Errorf("cells %dx%d too small for a %dx%d grid", cellW, cellH, cols, rows)
+			}
+		})
+	}
+}
+
+func TestBarsFit(t *testing.T) {
+	// 320 pixels tall: 14 rows of 4 bars fit above the overall bar, 15 don't
+	for _, tt := range []struct {
+		cpus    int
+		heatmap bool
+	}{{56, false}, {60, true}} {
+		m := NewCPUMonitor(newRecordingScreen(320, 480), 50, time.Second, nil)
+		m.cpuCount = tt.cpus
+		m.setupLayout()
+		if m.heatmap != tt.heatmap {
+			t.Errorf("%d CPUs: heatmap = %v, want %v", tt.cpus, m.heatmap, tt.heatmap)
+		}
+		rows := (tt.cpus + m.cols - 1) / m.cols
+		if bottom := cpuBarsY + rows*(m.barHeight+cpuBarSpacing) - cpuBarSpacing; !m.heatmap && bottom > m.overallY-6 {
+			t.Errorf("%d CPUs: bars end at %d, past the separator at %d", tt.cpus, bottom, m.overallY-5)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+// procRowHeight is the height of a process table row.
+const procRowHeight = 19
+
+// SetTopProcesses shows the n busiest process groups below the per-CPU
+// usage, 0 for none. It must be called before Run.
+func (m *CPUMonitor) SetTopProcesses(n int) { m.topN = n }
+
+// SetProcessGrouper sets how the process table groups processes.
+func (m *CPUMonitor) SetProcessGrouper(g *sysinfo.ProcessGrouper) { m.readProcs = g.TopCPU }
+
+// procTableHeight returns the height taken by the process table, including
+// its separator.
+func (m *CPUMonitor) procTableHeight() int {
+	if m.topN == 0 {
+		return 0
+	}
+	return m.topN*procRowHeight + 6
+}
+
+// minCPUAreaHeight is the height the process table leaves for per-CPU
+// usage: a row of bars or heatmap cells and the legend.
+const minCPUAreaHeight = 60
+
+// maxTopProcesses returns the most process table rows that fit the screen.
+func (m *CPUMonitor) maxTopProcesses() int {
+	return max((m.Height()-68-40-minCPUAreaHeight-6)/procRowHeight, 0)
+}
+
+// procTableY returns the top of the first process table row.
+func (m *CPUMonitor) procTableY() int {
+	return m.overallY - 7 - m.topN*procRowHeight
+}
+
+// procRowRegion returns the region of process table row i.
+func (m *CPUMonitor) procRowRegion(i int) Region {
+	return Region{5, m.procTableY() + i*procRowHeight, m.Width() - 10, procRowHeight}
+}
+
+// updateProcesses redraws the process table rows that changed. Usage is
+// per CPU, so one busy thread shows as 100%.
+func (m *CPUMonitor) updateProcesses(r *Renderer) ([]Region, error) {
+	groups, err := m.readProcs(m.topN)
+	if err != nil {
+		return nil, fmt.Errorf("read processes: %w", err)
+	}
+
+	var updates []Region
+	for i := 0; i < m.topN; i++ {
+		var g sysinfo.ProcessCPUInfo
+		if i < len(groups) {
+			g = groups[i]
+		}
+		key := fmt.Sprintf("proc_%d", i)
+		if !m.Changed(key, fmt.Sprintf("%s %d %.0f", g.Name, g.Count, g.Percent)) {
+			continue
+		}
+		reg := m.procRowRegion(i)
+		r.Clear(reg)
+		if g.Name != "" {
+			m.renderProcRow(r, reg, g)
+		}
+		updates = append(updates, reg)
+	}
+	return updates, nil
+}
+
+// renderProcRow draws a process group's name, process count and CPU usage.
+func (m *CPUMonitor) renderProcRow(r *Renderer, reg Region, g sysinfo.ProcessCPUInfo) {
+	colors := m.Colors()
+	x, y := float64(reg.X), float64(reg.Y+1)
+	pctW, barW := 60, 100
+	barX := reg.X + reg.W - pctW - barW - 4
+
+	nameW := float64(barX - reg.X - 8)
+	if g.Count > 1 {
+		count := fmt.Sprintf("×%d", g.Count)
+		countW := r.MeasureText(count, m.fonts.Small-2)
+		r.DrawTextRight(float64(barX)-countW-8, y+1, countW, count, m.fonts.Small-2, colors.TextDim)
+		nameW -= countW + 6
+	}
+	r.DrawTextFit(x, y, nameW, g.Name, m.fonts.Small, colors.Text, EllipsisEnd)
+
+	r.DrawBar(Region{barX, reg.Y + 3, barW, reg.H - 6}, min(g.Percent, 100), 0, 100, true)
+	r.DrawTextRight(float64(reg.X+reg.W-pctW), y, float64(pctW), fmt.Sprintf("%.0f%%", g.Percent), m.fonts.Small, colors.Text)
+}
@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func TestCoreCounts(t *testing.T) {
+	p := sysinfo.CPUCore{Type: sysinfo.CorePerformance}
+	e := sysinfo.CPUCore{Type: sysinfo.CoreEfficiency}
+	tests := []struct {
+		info sysinfo.CPUInfo
+		want string
+	}{
+		{sysinfo.CPUInfo{CoreCount: 8}, "8"},
+		{sysinfo.CPUInfo{CoreCount: 2, Cores: []sysinfo.CPUCore{{}, {}}}, "2"},
+		{sysinfo.CPUInfo{CoreCount: 4, Cores: []sysinfo.CPUCore{p, p, e, e}}, "2P+2E"},
+		{sysinfo.CPUInfo{CoreCount: 2, Cores: []sysinfo.CPUCore{p, p}}, "2"},
+	}
+	for _, tt := range tests {
+		if got := coreCounts(&tt.info); got != tt.want {
+			t.Errorf("coreCounts(%+v) = %q, want %q", tt.info.Cores, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateThrottles(t *testing.T) {
+	m := &CPUMonitor{}
+	cores := []sysinfo.CPUCore{{Throttles: 5}, {Throttles: 0}}
+
+	// The first sample has nothing to compare against
+	m.updateThrottles(cores)
+	if m.anyThrottled() {
+		t.Error("throttled on first sample")
+	}
+
+	cores[1].Throttles = 2
+	m.updateThrottles(cores)
+	if m.isThrottled(0) || !m.isThrottled(1) {
+		t.Errorf("throttled = %v, want [false true]", m.throttled)
+	}
+
+	m.updateThrottles(cores)
+	if m.anyThrottled() {
+		t.Error("still throttled with unchanged counters")
+	}
+}
+
+func TestUpdateProcesses(t *testing.T) {
+	m := NewCPUMonitor(newRecordingScreen(320, 480), 50, time.Second, nil)
+	m.readProcs = func(n int) ([]sysinfo.ProcessCPUInfo, error) {
+		groups := []sysinfo.ProcessCPUInfo{{Name: "gopls", Percent: 150, Count: 1}, {Name: "bash", Count: 1}}
+		return groups[:min(n, len(groups))], nil
+	}
+	m.cpuCount = 4
+	m.SetTopProcesses(3)
+	m.setupLayout()
+	r := NewRenderer(m.NewContext(Region{0, 0, m.Width(), m.Height()}), m.Theme(), m.fonts)
+
+	updates, err := m.updateProcesses(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 3 {
+		t.Errorf("first update redrew %d rows, want 3", len(updates))
+	}
+	want := []string{"gopls 1 150", "bash 1 0", " 0 0"}
+	for i, w := range want {
+		if got := m.cache[fmt.Sprintf("proc_%d", i)]; got != w {
+			t.Errorf("row %d = %q, want %q", i, got, w)
+		}
+	}
+	if updates, _ = m.updateProcesses(r); len(updates) != 0 {
+		t.Errorf("unchanged update redrew %d rows", len(updates))
+	}
+}
+
+func TestCPUMonitorTempSensor(t *testing.T) {
+	m := NewCPUMonitor(newRecordingScreen(320, 480), 50, time.Second, nil)
+	m.SetTempSensor("nvme/Composite")
+	m.readCPU = func() (*sysinfo.CPUInfo, error) {
+		return &sysinfo.CPUInfo{PerCPU: []float64{10, 20}, CoreCount: 2, Temp: 55, Sensors: []sysinfo.Sensor{
+			{Chip: "k10temp", Name: "temp1", Label: "Tctl", Kind: sysinfo.SensorTemp, Value: 55},
+			{Chip: "nvme", Name: "temp1", Label: "Composite", Kind: sysinfo.SensorTemp, Value: 41},
+		}}, nil
+	}
+	m.cpuCount = 2
+	m.setupLayout()
+
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.cache["header"], "CPU Monitor - 2 cores | 41°C"; got != want {
+		t.Errorf("header = %q, want %q", got, want)
+	}
+}
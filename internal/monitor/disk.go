@@ -0,0 +1,323 @@
+package monitor
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func init() {
+	Register("disk", func() DiskOptions {
+		return DiskOptions{Exclude: defaultDiskExclude, ExcludeDevices: defaultDeviceExclude}
+	}, func(p Params, opts DiskOptions) (Monitor, error) {
+		for _, globs := range [][]string{opts.Mounts, opts.Exclude, opts.Devices, opts.ExcludeDevices} {
+			for _, g := range globs {
+				if _, err := path.Match(g, ""); err != nil {
+					return nil, fmt.Errorf("pattern %q: %w", g, err)
+				}
+			}
+		}
+		m := NewDiskMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+		m.fstypes, m.mounts, m.exclude = opts.Fstypes, opts.Mounts, opts.Exclude
+		m.devices, m.excludeDevices = opts.Devices, opts.ExcludeDevices
+		return m, nil
+	})
+}
+
+// DiskOptions are the config options for the "disk" monitor.
+type DiskOptions struct {
+	Fstypes        []string `json:"fstypes,omitempty"`         // Filesystem types to show; empty shows all
+	Mounts         []string `json:"mounts,omitempty"`          // Mountpoint globs to show; empty shows all
+	Exclude        []string `json:"exclude,omitempty"`         // Mountpoint globs to hide
+	Devices        []string `json:"devices,omitempty"`         // Block device globs for I/O rows; empty shows all
+	ExcludeDevices []string `json:"exclude_devices,omitempty"` // Block device globs to hide
+}
+
+// defaultDiskExclude hides boot partitions and snap package images.
+var defaultDiskExclude = []string{"/boot", "/boot/*", "/efi", "/snap/*"}
+
+// defaultDeviceExclude hides loop, RAM and compressed swap devices.
+var defaultDeviceExclude = []string{"loop*", "ram*", "zram*"}
+
+// usageInterval is how often filesystem usage is re-read; space changes
+// slowly and statfs on a network mount can stall.
+const usageInterval = 10 * time.Second
+
+// DiskMonitor displays filesystem usage and block device I/O.
+type DiskMonitor struct {
+	*Base
+	fstypes        []string
+	mounts         []string
+	exclude        []string
+	devices        []string
+	excludeDevices []string
+
+	sampler   *sysinfo.DiskSampler
+	usage     []sysinfo.DiskUsage
+	lastUsage time.Time
+	fsRows    int
+	devY      int
+	devRows   int
+
+	// Readers, replaceable in tests
+	readUsage    func() ([]sysinfo.DiskUsage, error)
+	readCounters func() ([]sysinfo.DiskCounters, error)
+	readCPUTimes func() (sysinfo.CPUTimes, error)
+	readTemp     func(name string) float64
+	now          func() time.Time
+}
+
+// NewDiskMonitor creates a new disk monitor.
+func NewDiskMonitor(screen lcd.Screen, brightness int, interval time.Duration, logger *slog.Logger) *DiskMonitor {
+	base := NewBase(Config{
+		Screen:   screen,
+		Theme:    DefaultTheme(),
+		Fonts:    DefaultFontConfig(),
+		Interval: interval,
+		Logger:   logger,
+	})
+
+	return &DiskMonitor{
+		Base:           base,
+		exclude:        defaultDiskExclude,
+		excludeDevices: defaultDeviceExclude,
+		sampler:        sysinfo.NewDiskSampler(),
+		readUsage:      sysinfo.GetDiskUsage,
+		readCounters:   sysinfo.GetDiskCounters,
+		readCPUTimes:   sysinfo.GetCPUTimes,
+		readTemp: func(name string) float64 {
+			return sysinfo.ReadDiskTemp(sysinfo.SysfsRoot, name)
+		},
+		now: time.Now,
+	}
+}
+
+// Name returns the monitor name.
+func (m *DiskMonitor) Name() string { return "Disk" }
+
+// Run starts the disk monitor loop.
+func (m *DiskMonitor) Run() error {
+	m.SetRunning(true)
+
+	// Calculate layout
+	m.setupLayout()
+
+	// Initial draw
+	m.ClearBuffer()
+	m.drawStatic()
+	if err := m.DrawFullBuffer(); err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	m.Logger().Info("started", "monitor", m.Name())
+
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+
+	for m.Running() {
+		select {
+		case <-ticker.C:
+			if err := m.Tick(m.update); err != nil {
+				m.Logger().Error("update failed", "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the monitor.
+func (m *DiskMonitor) Stop() {
+	m.SetRunning(false)
+}
+
+func (m *DiskMonitor) setupLayout() {
+	const fsY, fsRowHeight, devRowHeight = 40, 26, 36
+	// Up to three devices at the bottom, filesystems fill the rest
+	m.devRows = 3
+	m.devY = m.Height() - m.devRows*devRowHeight
+	m.fsRows = (m.devY - 26 - fsY) / fsRowHeight
+}
+
+// fsRegion returns the region of filesystem row i.
+func (m *DiskMonitor) fsRegion(i int) Region {
+	return Region{0, 40 + i*26, m.Width(), 24}
+}
+
+// devRegion returns the region of device row i.
+func (m *DiskMonitor) devRegion(i int) Region {
+	return Region{0, m.devY + i*36, m.Width(), 34}
+}
+
+func (m *DiskMonitor) drawStatic() {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	r.DrawText(5, 8, "Disks", m.fonts.Large, m.Colors().Header)
+
+	// Separator lines
+	r.DrawLine(0, 34, float64(m.Width()))
+	r.DrawLine(0, float64(m.devY-24), float64(m.Width()))
+	r.DrawText(5, float64(m.devY-20), "I/O", m.fonts.Small, m.Colors().Header)
+}
+
+// shownFilesystems filters filesystems by type and mountpoint, keeping only
+// the first mount of each device so bind mounts aren't listed twice.
+func (m *DiskMonitor) shownFilesystems(usage []sysinfo.DiskUsage) []sysinfo.DiskUsage {
+	var shown []sysinfo.DiskUsage
+	seen := make(map[string]bool)
+	for _, u := range usage {
+		if len(m.fstypes) > 0 && !contains(m.fstypes, u.Fstype) {
+			continue
+		}
+		if len(m.mounts) > 0 && !matchAny(m.mounts, u.Mountpoint) {
+			continue
+		}
+		if matchAny(m.exclude, u.Mountpoint) || seen[u.Device] {
+			continue
+		}
+		seen[u.Device] = true
+		shown = append(shown, u)
+	}
+	return shown
+}
+
+// shownDevice reports whether a block device passes the device filters.
+func (m *DiskMonitor) shownDevice(name string) bool {
+	if len(m.devices) > 0 && !matchAny(m.devices, name) {
+		return false
+	}
+	return !matchAny(m.excludeDevices, name)
+}
+
+func (m *DiskMonitor) update() error {
+	counters, err := m.readCounters()
+	if err != nil {
+		return err
+	}
+	times, err := m.readCPUTimes()
+	if err != nil {
+		m.Logger().Debug("cpu times unavailable", "error", err)
+	}
+	rates, iowait := m.sampler.Sample(counters, times, m.now())
+
+	if m.lastUsage.IsZero() || m.now().Sub(m.lastUsage) >= usageInterval {
+		usage, err := m.readUsage()
+		if err != nil {
+			return err
+		}
+		m.usage, m.lastUsage = m.shownFilesystems(usage), m.now()
+	}
+
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	var updates []Region
+
+	// I/O wait
+	if m.ChangedFloat("iowait", iowait, 0.1) {
+		reg := Region{200, 8, m.Width() - 205, 24}
+		r.Clear(reg)
+		c := m.Theme().Scale().Color(iowait * 4) // 25% iowait is already severe
+		r.DrawTextRight(float64(reg.X), float64(reg.Y)+2, float64(reg.W), fmt.Sprintf("iowait %.1f%%", iowait), m.fonts.Normal, c)
+		updates = append(updates, reg)
+	}
+
+	// Filesystem rows
+	for i := 0; i < m.fsRows; i++ {
+		reg := m.fsRegion(i)
+		key := fmt.Sprintf("fs_%d", i)
+		if i >= len(m.usage) {
+			if m.Changed(key, "") {
+				r.Clear(reg)
+				updates = append(updates, reg)
+			}
+			continue
+		}
+		u := m.usage[i]
+		if m.Changed(key, fmt.Sprintf("%s %.0f %s", u.Mountpoint, u.UsedPercent, sysinfo.FormatBytes(u.Used))) {
+			m.renderFilesystemRow(r, reg, u)
+			updates = append(updates, reg)
+		}
+	}
+
+	// Device rows
+	var shown []sysinfo.DiskRate
+	for _, rate := range rates {
+		if m.shownDevice(rate.Name) {
+			shown = append(shown, rate)
+		}
+	}
+	for i := 0; i < m.devRows; i++ {
+		reg := m.devRegion(i)
+		key := fmt.Sprintf("dev_%d", i)
+		if i >= len(shown) {
+			if m.Changed(key, "") {
+				r.Clear(reg)
+				updates = append(updates, reg)
+			}
+			continue
+		}
+		rate := shown[i]
+		temp := m.readTemp(rate.Name)
+		if m.Changed(key, fmt.Sprint(rate.Name, formatRate(rate.ReadRate), formatRate(rate.WriteRate),
+			formatIOPS(rate.ReadIOPS), formatIOPS(rate.WriteIOPS), int(rate.BusyPct), int(temp))) {
+			m.renderDeviceRow(r, reg, rate, temp)
+			updates = append(updates, reg)
+		}
+	}
+
+	// Push updates to display
+	for _, reg := range updates {
+		if err := m.DrawRegion(reg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderFilesystemRow draws a mountpoint with its usage bar and sizes.
+func (m *DiskMonitor) renderFilesystemRow(r *Renderer, reg Region, u sysinfo.DiskUsage) {
+	r.Clear(reg)
+	colors := m.Colors()
+	y := float64(reg.Y)
+
+	r.DrawTextFit(5, y+2, 130, u.Mountpoint, m.fonts.Normal, colors.Text, EllipsisMiddle)
+	r.DrawBarStyled(Region{140, reg.Y + 4, 140, reg.H - 8}, u.UsedPercent, 0, 100, BarStyle{Border: true})
+	r.DrawTextRight(280, y+4, 45, fmt.Sprintf("%.0f%%", u.UsedPercent), m.fonts.Small, colors.Text)
+	sizes := sysinfo.FormatBytes(u.Used) + " / " + sysinfo.FormatBytes(u.Total)
+	r.DrawTextRightFit(330, y+4, float64(reg.W-335), sizes, m.fonts.Small, colors.TextDim, EllipsisEnd)
+}
+
+// renderDeviceRow draws a block device's throughput and temperature, with
+// IOPS and utilization underneath.
+func (m *DiskMonitor) renderDeviceRow(r *Renderer, reg Region, rate sysinfo.DiskRate, temp float64) {
+	r.Clear(reg)
+	colors := m.Colors()
+	y := float64(reg.Y)
+
+	r.DrawTextFit(5, y, 105, rate.Name, m.fonts.Normal, colors.Text, EllipsisMiddle)
+	r.DrawTextFit(115, y, 120, "R "+formatRate(rate.ReadRate), m.fonts.Normal, colors.Text, EllipsisEnd)
+	r.DrawTextFit(240, y, 120, "W "+formatRate(rate.WriteRate), m.fonts.Normal, colors.Text, EllipsisEnd)
+	if temp > 0 {
+		r.DrawTextRight(365, y, float64(reg.W-370), fmt.Sprintf("%.0f°C", temp), m.fonts.Normal, m.Theme().TempColor(temp, 55, 70))
+	}
+
+	detail := fmt.Sprintf("%s r/s  %s w/s", formatIOPS(rate.ReadIOPS), formatIOPS(rate.WriteIOPS))
+	r.DrawTextFit(115, y+18, 245, detail, m.fonts.Small-2, colors.TextDim, EllipsisEnd)
+	r.DrawTextRight(365, y+18, float64(reg.W-370), fmt.Sprintf("busy %.0f%%", rate.BusyPct), m.fonts.Small-2,
+		m.Theme().Scale().Color(rate.BusyPct))
+}
+
+// formatIOPS formats an operation rate compactly.
+func formatIOPS(v float64) string {
+	if v >= 1000 {
+		return fmt.Sprintf("%.1fk", v/1000)
+	}
+	return fmt.Sprintf("%.0f", v)
+}
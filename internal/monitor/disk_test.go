@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func TestDiskFilesystemFilter(t *testing.T) {
+	usage := []sysinfo.DiskUsage{
+		{Mountpoint: "/", Device: "/dev/nvme0n1p2", Fstype: "ext4"},
+		{Mountpoint: "/boot", Device: "/dev/nvme0n1p1", Fstype: "vfat"},
+		{Mountpoint: "/var/lib/docker", Device: "/dev/nvme1n1", Fstype: "xfs"},
+		{Mountpoint: "/srv/www", Device: "/dev/nvme0n1p2", Fstype: "ext4"}, // Bind mount
+		{Mountpoint: "/mnt/nas", Device: "nas:/export", Fstype: "nfs4"},
+	}
+
+	tests := []struct {
+		name string
+		opts DiskOptions
+		want []string
+	}{
+		{"defaults", DiskOptions{Exclude: defaultDiskExclude}, []string{"/", "/var/lib/docker", "/mnt/nas"}},
+		{"fstypes", DiskOptions{Fstypes: []string{"ext4", "xfs"}}, []string{"/", "/var/lib/docker"}},
+		{"mounts", DiskOptions{Mounts: []string{"/var/lib/*", "/mnt/*"}}, []string{"/var/lib/docker", "/mnt/nas"}},
+		{"exclude", DiskOptions{Exclude: []string{"/", "/boot"}}, []string{"/var/lib/docker", "/srv/www", "/mnt/nas"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewDiskMonitor(lcd.NewSimulated(320, 480), 50, time.Second, nil)
+			m.fstypes, m.mounts, m.exclude = tt.opts.Fstypes, tt.opts.Mounts, tt.opts.Exclude
+			var got []string
+			for _, u := range m.shownFilesystems(usage) {
+				got = append(got, u.Mountpoint)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shown = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiskMonitorUpdate(t *testing.T) {
+	screen := newRecordingScreen(320, 480)
+	m := NewDiskMonitor(screen, 50, time.Second, nil)
+	now := time.Unix(1000, 0)
+	var written uint64
+	var cpu sysinfo.CPUTimes
+	m.now = func() time.Time { return now }
+	m.readCounters = func() ([]sysinfo.DiskCounters, error) {
+		return []sysinfo.DiskCounters{
+			{Name: "loop0", ReadBytes: written},
+			{Name: "nvme0n1", WriteBytes: written, Writes: written >> 16},
+		}, nil
+	}
+	m.readCPUTimes = func() (sysinfo.CPUTimes, error) { return cpu, nil }
+	usageReads := 0
+	m.readUsage = func() ([]sysinfo.DiskUsage, error) {
+		usageReads++
+		return []sysinfo.DiskUsage{{Mountpoint: "/", Device: "/dev/nvme0n1p2", UsedPercent: 91}}, nil
+	}
+	m.readTemp = func(string) float64 { return 48 }
+	m.setupLayout()
+
+	for i := 0; i < 3; i++ {
+		written += 8 << 20
+		cpu.Total += 4
+		cpu.Iowait += 1
+		if err := m.update(); err != nil {
+			t.Fatalf("update() error = %v", err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	if usageReads != 1 {
+		t.Errorf("usage read %d times in 3s, want 1", usageReads)
+	}
+	if got := m.cache["iowait"]; got != 25.0 {
+		t.Errorf("iowait = %v, want 25", got)
+	}
+
+	// Steady rates send nothing
+	before := screen.count()
+	written += 8 << 20
+	cpu.Total += 4
+	cpu.Iowait += 1
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if n := screen.count() - before; n != 0 {
+		t.Errorf("steady state sent %d regions", n)
+	}
+}
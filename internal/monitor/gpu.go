@@ -0,0 +1,293 @@
+package monitor
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func init() {
+	Register("gpu", func() GPUOptions { return GPUOptions{} }, func(p Params, opts GPUOptions) (Monitor, error) {
+		for _, g := range opts.Cards {
+			if _, err := path.Match(g, ""); err != nil {
+				return nil, fmt.Errorf("card pattern %q: %w", g, err)
+			}
+		}
+		m := NewGPUMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+		m.cards = opts.Cards
+		return m, nil
+	})
+}
+
+// GPUOptions are the config options for the "gpu" monitor.
+type GPUOptions struct {
+	Cards []string `json:"cards,omitempty"` // DRM card name globs to show; empty shows all
+}
+
+// maxGPUs is how many GPUs fit on one screen.
+const maxGPUs = 2
+
+// GPUMonitor displays GPU utilization, memory, clocks, power and temperature.
+type GPUMonitor struct {
+	*Base
+	cards []string
+
+	sampler  *sysinfo.GPUSampler
+	history  *HistoryStore
+	sections []Region
+
+	// Readers, replaceable in tests
+	readGPUs func() ([]sysinfo.GPUStats, error)
+	now      func() time.Time
+}
+
+// NewGPUMonitor creates a new GPU monitor.
+func NewGPUMonitor(screen lcd.Screen, brightness int, interval time.Duration, logger *slog.Logger) *GPUMonitor {
+	base := NewBase(Config{
+		Screen:   screen,
+		Theme:    DefaultTheme(),
+		Fonts:    DefaultFontConfig(),
+		Interval: interval,
+		Logger:   logger,
+	})
+
+	return &GPUMonitor{
+		Base:     base,
+		sampler:  sysinfo.NewGPUSampler(),
+		readGPUs: sysinfo.GetGPUs,
+		now:      time.Now,
+	}
+}
+
+// Name returns the monitor name.
+func (m *GPUMonitor) Name() string { return "GPU" }
+
+// Run starts the GPU monitor loop.
+func (m *GPUMonitor) Run() error {
+	m.SetRunning(true)
+
+	// Calculate layout
+	m.setupLayout()
+
+	// Initial draw
+	m.ClearBuffer()
+	m.drawStatic()
+	if err := m.DrawFullBuffer(); err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	m.Logger().Info("started", "monitor", m.Name())
+
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+
+	for m.Running() {
+		select {
+		case <-ticker.C:
+			if err := m.Tick(m.update); err != nil {
+				m.Logger().Error("update failed", "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the monitor.
+func (m *GPUMonitor) Stop() {
+	m.SetRunning(false)
+}
+
+// setupLayout splits the screen below the header into one section per GPU.
+func (m *GPUMonitor) setupLayout() {
+	n := 0
+	if gpus, err := m.readGPUs(); err == nil {
+		n = len(m.filter(gpus))
+	}
+	m.setSections(n)
+}
+
+// setSections lays out sections for n GPUs, at most maxGPUs.
+func (m *GPUMonitor) setSections(n int) {
+	n = min(n, maxGPUs)
+	m.Changed("gpus", n)
+	top := 38
+	h := (m.Height() - top) / max(n, 1)
+	m.sections = make([]Region, max(n, 1))
+	for i := range m.sections {
+		m.sections[i] = Region{0, top + i*h, m.Width(), h - 2}
+	}
+	m.history = NewHistoryStore(m.graphRegion(m.sections[0]).W)
+}
+
+// graphRegion returns the utilization graph area of a section, below its
+// four rows of readings.
+func (m *GPUMonitor) graphRegion(sec Region) Region {
+	return Region{5, sec.Y + 88, sec.W - 10, sec.H - 92}
+}
+
+func (m *GPUMonitor) drawStatic() {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	r.DrawText(5, 8, "GPU", m.fonts.Large, m.Colors().Header)
+
+	// Separator lines
+	r.DrawLine(0, 34, float64(m.Width()))
+	for _, sec := range m.sections[1:] {
+		r.DrawLine(0, float64(sec.Y-2), float64(m.Width()))
+	}
+}
+
+// filter returns the GPUs passing the card filter.
+func (m *GPUMonitor) filter(gpus []sysinfo.GPUStats) []sysinfo.GPUStats {
+	if len(m.cards) == 0 {
+		return gpus
+	}
+	var shown []sysinfo.GPUStats
+	for _, g := range gpus {
+		if matchAny(m.cards, g.Card) {
+			shown = append(shown, g)
+		}
+	}
+	return shown
+}
+
+func (m *GPUMonitor) update() error {
+	gpus, err := m.readGPUs()
+	if err != nil {
+		return err
+	}
+	gpus = m.filter(m.sampler.Sample(gpus, m.now()))
+
+	// A GPU appearing or going away changes the layout
+	if n := min(len(gpus), maxGPUs); m.Changed("gpus", n) {
+		m.ResetChanged()
+		m.setSections(n)
+		m.ClearBuffer()
+		m.drawStatic()
+		if err := m.DrawFullBuffer(); err != nil {
+			return err
+		}
+	}
+
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	var updates []Region
+
+	if len(gpus) == 0 {
+		if m.Changed("empty", true) {
+			reg := m.sections[0]
+			r.Clear(reg)
+			r.DrawText(5, float64(reg.Y+4), "No GPU found", m.fonts.Normal, m.Colors().TextDim)
+			updates = append(updates, reg)
+		}
+	}
+
+	for i, g := range gpus {
+		if i >= len(m.sections) {
+			break
+		}
+		updates = append(updates, m.updateSection(r, m.sections[i], g)...)
+	}
+
+	// Push updates to display
+	for _, reg := range updates {
+		if err := m.DrawRegion(reg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateSection redraws the parts of one GPU's section that changed.
+func (m *GPUMonitor) updateSection(r *Renderer, sec Region, g sysinfo.GPUStats) []Region {
+	colors := m.Colors()
+	var updates []Region
+	key := func(name string) string { return g.Card + "_" + name }
+	y := float64(sec.Y)
+
+	// Title and temperature
+	title := g.Card + "  " + g.Driver
+	if g.Name != "" {
+		title = g.Name
+	}
+	if m.Changed(key("title"), fmt.Sprint(title, int(g.TempC))) {
+		reg := Region{0, sec.Y, sec.W, 22}
+		r.Clear(reg)
+		r.DrawTextFit(5, y+2, float64(sec.W-90), title, m.fonts.Normal, colors.Text, EllipsisEnd)
+		if g.TempC > 0 {
+			r.DrawTextRight(float64(sec.W-85), y+2, 80, fmt.Sprintf("%.0f°C", g.TempC),
+				m.fonts.Normal, m.Theme().TempColor(g.TempC, 75, 90))
+		}
+		updates = append(updates, reg)
+	}
+
+	// Utilization
+	if m.ChangedFloat(key("busy"), g.Busy, 1) {
+		reg := Region{0, sec.Y + 22, sec.W, 22}
+		r.Clear(reg)
+		r.DrawText(5, y+25, "Load", m.fonts.Small, colors.TextDim)
+		if g.Busy < 0 {
+			r.DrawText(70, y+25, "n/a", m.fonts.Small, colors.TextDim)
+		} else {
+			r.DrawBarStyled(Region{70, reg.Y + 4, sec.W - 230, 14}, g.Busy, 0, 100, BarStyle{Border: true})
+			r.DrawTextRight(float64(sec.W-65), y+25, 60, fmt.Sprintf("%.0f%%", g.Busy), m.fonts.Small, colors.Text)
+		}
+		updates = append(updates, reg)
+	}
+
+	// Memory
+	vram := sysinfo.FormatBytes(g.VRAMUsed) + " / " + sysinfo.FormatBytes(g.VRAMTotal)
+	if m.Changed(key("vram"), vram) {
+		reg := Region{0, sec.Y + 44, sec.W, 22}
+		r.Clear(reg)
+		r.DrawText(5, y+47, "VRAM", m.fonts.Small, colors.TextDim)
+		if g.VRAMTotal == 0 {
+			r.DrawText(70, y+47, "shared", m.fonts.Small, colors.TextDim)
+		} else {
+			pct := float64(g.VRAMUsed) / float64(g.VRAMTotal) * 100
+			r.DrawBarStyled(Region{70, reg.Y + 4, sec.W - 230, 14}, pct, 0, 100, BarStyle{Border: true})
+			r.DrawTextRight(float64(sec.W-155), y+47, 150, vram, m.fonts.Small, colors.Text)
+		}
+		updates = append(updates, reg)
+	}
+
+	// Clocks and power
+	clocks := fmt.Sprintf("Core %d MHz", g.CoreMHz)
+	if g.MemMHz > 0 {
+		clocks += fmt.Sprintf("  Mem %d MHz", g.MemMHz)
+	}
+	power := ""
+	if g.PowerW > 0 {
+		power = fmt.Sprintf("%.0f W", g.PowerW)
+		if g.PowerCapW > 0 {
+			power += fmt.Sprintf(" / %.0f W", g.PowerCapW)
+		}
+	}
+	if m.Changed(key("clocks"), clocks+power) {
+		reg := Region{0, sec.Y + 66, sec.W, 20}
+		r.Clear(reg)
+		if g.CoreMHz > 0 {
+			r.DrawTextFit(5, y+68, float64(sec.W-150), clocks, m.fonts.Small, colors.Text, EllipsisEnd)
+		}
+		r.DrawTextRight(float64(sec.W-145), y+68, 140, power, m.fonts.Small, colors.Text)
+		updates = append(updates, reg)
+	}
+
+	// Utilization history
+	if reg := m.graphRegion(sec); reg.H >= 8 && g.Busy >= 0 {
+		h := m.history.Get(g.Card)
+		h.Push(g.Busy)
+		m.UpdateGraph(r, key("graph"), reg, h.Values(), GraphStyle{Kind: GraphArea, Min: 0, Max: 100})
+		updates = append(updates, reg)
+	}
+
+	return updates
+}
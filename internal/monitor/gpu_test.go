@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func TestGPUMonitorUpdate(t *testing.T) {
+	screen := newRecordingScreen(320, 480)
+	m := NewGPUMonitor(screen, 50, time.Second, nil)
+	now := time.Unix(1000, 0)
+	m.now = func() time.Time { return now }
+	amd := sysinfo.GPUStats{Card: "card0", Driver: "amdgpu", Busy: 40, VRAMUsed: 2 << 30, VRAMTotal: 8 << 30, TempC: 60}
+	intel := sysinfo.GPUStats{Card: "card1", Driver: "i915", Busy: -1, EngineBusy: map[sysinfo.EngineKey]uint64{}}
+	gpus := []sysinfo.GPUStats{amd}
+	m.readGPUs = func() ([]sysinfo.GPUStats, error) { return gpus, nil }
+
+	m.setupLayout()
+	if len(m.sections) != 1 {
+		t.Fatalf("sections = %d, want 1", len(m.sections))
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.update(); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Second)
+	}
+	if got := m.history.Get("card0").Last(); got != 40 {
+		t.Errorf("card0 history = %v, want 40", got)
+	}
+
+	// A second GPU splits the screen and redraws everything
+	gpus = []sysinfo.GPUStats{amd, intel}
+	before := screen.count()
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.sections) != 2 {
+		t.Errorf("sections = %d, want 2", len(m.sections))
+	}
+	if screen.count() == before {
+		t.Error("layout change sent nothing")
+	}
+
+	// The card filter drops it again
+	m.cards = []string{"card0"}
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.sections) != 1 {
+		t.Errorf("filtered sections = %d, want 1", len(m.sections))
+	}
+}
@@ -0,0 +1,187 @@
+package monitor
+
+import (
+	"image/color"
+)
+
+// GraphKind selects how DrawGraph renders a series.
+type GraphKind int
+
+const (
+	GraphLine GraphKind = iota // Sparkline
+	GraphArea                  // Filled area under the line
+	GraphHeat                  // One colored cell per sample
+)
+
+// GraphStyle configures graph rendering.
+type GraphStyle struct {
+	Kind  GraphKind
+	Min   float64
+	Max   float64
+	Step  int         // Pixels per sample, default 1
+	Color color.Color // Line and area color; nil colors samples by Scale
+	Scale *Scale      // Nil uses the theme's ascending scale
+}
+
+func (s GraphStyle) step() int {
+	if s.Step < 1 {
+		return 1
+	}
+	return s.Step
+}
+
+// pct returns v as a percentage of the style's range, clamped to 0-100.
+func (s GraphStyle) pct(v float64) float64 {
+	if s.Max <= s.Min {
+		return 0
+	}
+	p := (v - s.Min) / (s.Max - s.Min) * 100
+	if p < 0 {
+		return 0
+	}
+	if p > 100 {
+		return 100
+	}
+	return p
+}
+
+// graphY returns the y coordinate of v within reg.
+func graphY(reg Region, style GraphStyle, v float64) float64 {
+	return float64(reg.Y+reg.H) - style.pct(v)/100*float64(reg.H-1) - 1
+}
+
+// DrawGraph clears reg and draws the newest samples that fit, right-aligned
+// so the latest value is at the right edge.
+func (r *Renderer) DrawGraph(reg Region, values []float64, style GraphStyle) {
+	r.Clear(reg)
+	step := style.step()
+	if n := reg.W / step; len(values) > n {
+		values = values[len(values)-n:]
+	}
+	right := reg.X + reg.W
+	for i := range values {
+		x := right - (len(values)-i)*step
+		var prev *float64
+		if i > 0 {
+			prev = &values[i-1]
+		}
+		r.drawGraphColumn(reg, x, prev, values[i], style)
+	}
+}
+
+// DrawGraphTail draws only the newest sample in the rightmost column of reg.
+// Call it after scrolling the existing graph left by the style's step with
+// Base.ScrollLeft to update a graph without redrawing it.
+func (r *Renderer) DrawGraphTail(reg Region, values []float64, style GraphStyle) {
+	if len(values) == 0 {
+		return
+	}
+	step := style.step()
+	x := reg.X + reg.W - step
+	r.Clear(Region{x, reg.Y, step, reg.H})
+	var prev *float64
+	if len(values) > 1 {
+		prev = &values[len(values)-2]
+	}
+	r.drawGraphColumn(reg, x, prev, values[len(values)-1], style)
+}
+
+// drawGraphColumn draws one sample into the column starting at x.
+func (r *Renderer) drawGraphColumn(reg Region, x int, prev *float64, v float64, style GraphStyle) {
+	step := style.step()
+	scale := r.theme.Scale()
+	if style.Scale != nil {
+		scale = *style.Scale
+	}
+	c := style.Color
+	if c == nil {
+		c = scale.Color(style.pct(v))
+	}
+
+	switch style.Kind {
+	case GraphHeat:
+		r.dc.SetColor(scale.Blend(style.pct(v)))
+		r.dc.DrawRectangle(float64(x), float64(reg.Y), float64(step), float64(reg.H))
+		r.dc.Fill()
+
+	case GraphArea:
+		y := graphY(reg, style, v)
+		r.dc.SetColor(c)
+		r.dc.DrawRectangle(float64(x), y, float64(step), float64(reg.Y+reg.H)-y)
+		r.dc.Fill()
+
+	default:
+		y := graphY(reg, style, v)
+		// Samples sit at the right edge of their column; the half pixel
+		// keeps the stroke inside the region
+		x2 := float64(x+step) - 0.5
+		x1 := x2 - 1
+		y1 := y
+		if prev != nil {
+			x1 = float64(x) - 0.5
+			y1 = graphY(reg, style, *prev)
+		}
+		r.dc.SetColor(c)
+		r.dc.SetLineWidth(1)
+		r.dc.DrawLine(x1, y1, x2, y)
+		r.dc.Stroke()
+	}
+}
+
+// heatRows splits reg into one strip per row with a 1px gap when there is room.
+func heatRows(reg Region, n int) []Region {
+	if n == 0 {
+		return nil
+	}
+	gap := 1
+	if reg.H/n < 3 {
+		gap = 0
+	}
+	h := (reg.H - gap*(n-1)) / n
+	rows := make([]Region, n)
+	for i := range rows {
+		rows[i] = Region{reg.X, reg.Y + i*(h+gap), reg.W, h}
+	}
+	return rows
+}
+
+// DrawHeatStrips draws one heat strip per series stacked vertically in reg,
+// e.g. per-core usage over time.
+func (r *Renderer) DrawHeatStrips(reg Region, series [][]float64, style GraphStyle) {
+	r.Clear(reg)
+	style.Kind = GraphHeat
+	for i, row := range heatRows(reg, len(series)) {
+		r.DrawGraph(row, series[i], style)
+	}
+}
+
+// DrawHeatStripsTail draws the newest column of each strip after the strips
+// have been scrolled with Base.ScrollLeft.
+func (r *Renderer) DrawHeatStripsTail(reg Region, series [][]float64, style GraphStyle) {
+	style.Kind = GraphHeat
+	for i, row := range heatRows(reg, len(series)) {
+		r.DrawGraphTail(row, series[i], style)
+	}
+}
+
+// UpdateGraph draws a scrolling graph of values into reg, once per new
+// sample. The first call for a key draws the whole graph; later calls scroll
+// it left by one sample and draw only the newest.
+func (b *Base) UpdateGraph(r *Renderer, key string, reg Region, values []float64, style GraphStyle) {
+	if b.Changed(key, true) {
+		r.DrawGraph(reg, values, style)
+		return
+	}
+	b.ScrollLeft(reg, style.step())
+	r.DrawGraphTail(reg, values, style)
+}
+
+// UpdateHeatStrips is UpdateGraph for stacked heat strips.
+func (b *Base) UpdateHeatStrips(r *Renderer, key string, reg Region, series [][]float64, style GraphStyle) {
+	if b.Changed(key, true) {
+		r.DrawHeatStrips(reg, series, style)
+		return
+	}
+	b.ScrollLeft(reg, style.step())
+	r.DrawHeatStripsTail(reg, series, style)
+}
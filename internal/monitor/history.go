@@ -0,0 +1,114 @@
+package monitor
+
+import "math"
+
+// History is a fixed-capacity ring buffer of samples for one metric.
+// Once full, each Push overwrites the oldest sample.
+type History struct {
+	values []float64
+	head   int // Index of the next write
+	n      int
+}
+
+// NewHistory creates a history holding up to capacity samples.
+func NewHistory(capacity int) *History {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &History{values: make([]float64, capacity)}
+}
+
+// Push appends a sample, evicting the oldest if the history is full.
+func (h *History) Push(v float64) {
+	h.values[h.head] = v
+	h.head = (h.head + 1) % len(h.values)
+	if h.n < len(h.values) {
+		h.n++
+	}
+}
+
+// Len returns the number of samples held.
+func (h *History) Len() int { return h.n }
+
+// Cap returns the maximum number of samples held.
+func (h *History) Cap() int { return len(h.values) }
+
+// Values returns the samples, oldest first.
+func (h *History) Values() []float64 {
+	out := make([]float64, h.n)
+	start := (h.head - h.n + len(h.values)) % len(h.values)
+	for i := range out {
+		out[i] = h.values[(start+i)%len(h.values)]
+	}
+	return out
+}
+
+// Last returns the most recent sample, or 0 if empty.
+func (h *History) Last() float64 {
+	if h.n == 0 {
+		return 0
+	}
+	return h.values[(h.head-1+len(h.values))%len(h.values)]
+}
+
+// Min returns the smallest sample, or 0 if empty.
+func (h *History) Min() float64 {
+	if h.n == 0 {
+		return 0
+	}
+	m := math.Inf(1)
+	for _, v := range h.Values() {
+		m = math.Min(m, v)
+	}
+	return m
+}
+
+// Max returns the largest sample, or 0 if empty.
+func (h *History) Max() float64 {
+	if h.n == 0 {
+		return 0
+	}
+	m := math.Inf(-1)
+	for _, v := range h.Values() {
+		m = math.Max(m, v)
+	}
+	return m
+}
+
+// Avg returns the mean of the samples, or 0 if empty.
+func (h *History) Avg() float64 {
+	if h.n == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range h.Values() {
+		sum += v
+	}
+	return sum / float64(h.n)
+}
+
+// HistoryStore holds one History per named metric, all of the same capacity.
+type HistoryStore struct {
+	capacity int
+	metrics  map[string]*History
+}
+
+// NewHistoryStore creates a store whose histories hold capacity samples.
+func NewHistoryStore(capacity int) *HistoryStore {
+	return &HistoryStore{capacity: capacity, metrics: make(map[string]*History)}
+}
+
+// Get returns the history for a metric, creating it if needed.
+func (s *HistoryStore) Get(name string) *History {
+	h, ok := s.metrics[name]
+	if !ok {
+		h = NewHistory(s.capacity)
+		s.metrics[name] = h
+	}
+	return h
+}
+
+// Push appends a sample to a metric's history.
+func (s *HistoryStore) Push(name string, v float64) {
+	s.Get(name).Push(v)
+}
@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+)
+
+func TestHistory(t *testing.T) {
+	h := NewHistory(3)
+	if h.Len() != 0 || h.Last() != 0 || h.Avg() != 0 {
+		t.Fatalf("empty history: len=%d last=%v avg=%v", h.Len(), h.Last(), h.Avg())
+	}
+
+	for _, v := range []float64{5, 1, 9, 4} {
+		h.Push(v)
+	}
+
+	if got, want := h.Values(), []float64{1, 9, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values() = %v, want %v", got, want)
+	}
+	if h.Len() != 3 || h.Cap() != 3 {
+		t.Errorf("Len() = %d, Cap() = %d, want 3, 3", h.Len(), h.Cap())
+	}
+	if h.Last() != 4 {
+		t.Errorf("Last() = %v, want 4", h.Last())
+	}
+	if h.Min() != 1 || h.Max() != 9 {
+		t.Errorf("Min() = %v, Max() = %v, want 1, 9", h.Min(), h.Max())
+	}
+	if h.Avg() != 14.0/3 {
+		t.Errorf("Avg() = %v, want %v", h.Avg(), 14.0/3)
+	}
+}
+
+func TestHistoryStore(t *testing.T) {
+	s := NewHistoryStore(2)
+	s.Push("cpu", 10)
+	s.Push("cpu", 20)
+	s.Push("cpu", 30)
+	s.Push("mem", 1)
+
+	if got := s.Get("cpu").Values(); !reflect.DeepEqual(got, []float64{20, 30}) {
+		t.Errorf("cpu = %v, want [20 30]", got)
+	}
+	if got := s.Get("mem").Len(); got != 1 {
+		t.Errorf("mem len = %d, want 1", got)
+	}
+}
+
+func TestScrollLeft(t *testing.T) {
+	b := NewBase(Config{Screen: lcd.NewSimulated(10, 10), Theme: DefaultTheme()})
+	buf := b.Buffer()
+	red := color.RGBA{255, 0, 0, 255}
+	buf.SetRGBA(5, 2, red)
+
+	b.ScrollLeft(Region{2, 0, 6, 4}, 2)
+
+	if got := buf.RGBAAt(3, 2); got != red {
+		t.Errorf("pixel after scroll = %v, want red", got)
+	}
+	if got := buf.RGBAAt(5, 2); got == red {
+		t.Error("pixel was not moved")
+	}
+}
@@ -0,0 +1,370 @@
+package monitor
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func init() {
+	Register("network", func() NetworkOptions {
+		return NetworkOptions{Exclude: defaultNetExclude, Talkers: true}
+	}, func(p Params, opts NetworkOptions) (Monitor, error) {
+		for _, g := range append(opts.Include, opts.Exclude...) {
+			if _, err := path.Match(g, ""); err != nil {
+				return nil, fmt.Errorf("interface pattern %q: %w", g, err)
+			}
+		}
+		m := NewNetworkMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+		m.include, m.exclude, m.showTalkers = opts.Include, opts.Exclude, opts.Talkers
+		return m, nil
+	})
+}
+
+// NetworkOptions are the config options for the "network" monitor.
+type NetworkOptions struct {
+	Include []string `json:"include,omitempty"` // Interface name globs to show; empty shows all
+	Exclude []string `json:"exclude,omitempty"` // Interface name globs to hide
+	Talkers bool     `json:"talkers"`           // Show processes with the most connections
+}
+
+// defaultNetExclude hides loopback and container bridge interfaces.
+var defaultNetExclude = []string{"lo", "veth*", "docker*", "br-*"}
+
+// talkerInterval is how often the connection table is scanned; it walks
+// every process's file descriptors, so it's done less often than sampling.
+const talkerInterval = 5 * time.Second
+
+// NetworkMonitor displays network throughput per interface.
+type NetworkMonitor struct {
+	*Base
+	include     []string
+	exclude     []string
+	showTalkers bool
+
+	sampler     *sysinfo.NetSampler
+	rx, tx      *History
+	rowHeight   int
+	numRows     int
+	talkersY    int
+	talkers     []sysinfo.NetTalker
+	lastTalkers time.Time
+
+	// Readers, replaceable in tests
+	readCounters   func() ([]sysinfo.NetCounters, error)
+	readInterfaces func() ([]sysinfo.NetInterface, error)
+	readTalkers    func(n int) ([]sysinfo.NetTalker, error)
+	now            func() time.Time
+}
+
+// NewNetworkMonitor creates a new network monitor.
+func NewNetworkMonitor(screen lcd.Screen, brightness int, interval time.Duration, logger *slog.Logger) *NetworkMonitor {
+	base := NewBase(Config{
+		Screen:   screen,
+		Theme:    DefaultTheme(),
+		Fonts:    DefaultFontConfig(),
+		Interval: interval,
+		Logger:   logger,
+	})
+
+	return &NetworkMonitor{
+		Base:           base,
+		exclude:        defaultNetExclude,
+		showTalkers:    true,
+		sampler:        sysinfo.NewNetSampler(),
+		readCounters:   sysinfo.GetNetCounters,
+		readInterfaces: sysinfo.GetNetInterfaces,
+		readTalkers:    sysinfo.GetTopTalkers,
+		now:            time.Now,
+	}
+}
+
+// Name returns the monitor name.
+func (m *NetworkMonitor) Name() string { return "Network" }
+
+// Run starts the network monitor loop.
+func (m *NetworkMonitor) Run() error {
+	m.SetRunning(true)
+
+	// Calculate layout
+	m.setupLayout()
+
+	// Initial draw
+	m.ClearBuffer()
+	m.drawStatic()
+	if err := m.DrawFullBuffer(); err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	m.Logger().Info("started", "monitor", m.Name())
+
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+
+	for m.Running() {
+		select {
+		case <-ticker.C:
+			if err := m.Tick(m.update); err != nil {
+				m.Logger().Error("update failed", "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the monitor.
+func (m *NetworkMonitor) Stop() {
+	m.SetRunning(false)
+}
+
+func (m *NetworkMonitor) setupLayout() {
+	m.rowHeight = 36
+	m.talkersY = m.Height()
+	if m.showTalkers {
+		m.talkersY = m.Height() - 72
+	}
+	m.numRows = (m.talkersY - 4 - m.ifaceListY()) / m.rowHeight
+
+	m.rx = NewHistory(m.graphRegion(0).W)
+	m.tx = NewHistory(m.graphRegion(1).W)
+}
+
+// graphRegion returns the region of the receive (0) or transmit (1) graph.
+func (m *NetworkMonitor) graphRegion(i int) Region {
+	return Region{60, 40 + i*44, m.Width() - 65, 40}
+}
+
+// ifaceListY returns the top of the interface list.
+func (m *NetworkMonitor) ifaceListY() int { return 136 }
+
+func (m *NetworkMonitor) drawStatic() {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	r.DrawText(5, 8, "Network", m.fonts.Large, m.Colors().Header)
+	r.DrawText(5, 40, "RX", m.fonts.Normal, m.Colors().Text)
+	r.DrawText(5, 84, "TX", m.fonts.Normal, m.Colors().Text)
+
+	// Separator lines
+	r.DrawLine(0, 34, float64(m.Width()))
+	r.DrawLine(0, float64(m.ifaceListY()-5), float64(m.Width()))
+	if m.showTalkers {
+		r.DrawLine(0, float64(m.talkersY-2), float64(m.Width()))
+		r.DrawText(5, float64(m.talkersY+2), "Connections", m.fonts.Small, m.Colors().Header)
+	}
+}
+
+// shown reports whether an interface passes the include/exclude filters.
+func (m *NetworkMonitor) shown(name string) bool {
+	if len(m.include) > 0 && !matchAny(m.include, name) {
+		return false
+	}
+	return !matchAny(m.exclude, name)
+}
+
+// matchAny reports whether name matches any of the glob patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// formatRate formats a byte rate.
+func formatRate(bytesPerSec float64) string {
+	return sysinfo.FormatBytes(uint64(bytesPerSec)) + "/s"
+}
+
+// niceScale rounds a rate up to 1, 2 or 5 times a power of ten so the graph
+// scale changes in visible steps rather than every sample.
+func niceScale(v float64) float64 {
+	const floor = 10 * 1024 // Keep idle links from magnifying noise
+	if v < floor {
+		return floor
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, f := range []float64{1, 2, 5, 10} {
+		if v <= f*exp {
+			return f * exp
+		}
+	}
+	return 10 * exp
+}
+
+func (m *NetworkMonitor) update() error {
+	counters, err := m.readCounters()
+	if err != nil {
+		return err
+	}
+	rates := m.sampler.Sample(counters, m.now())
+	byName := make(map[string]sysinfo.NetRate, len(rates))
+	for _, r := range rates {
+		byName[r.Name] = r
+	}
+
+	// Interface details, falling back to counter names only
+	ifaces, err := m.readInterfaces()
+	if err != nil {
+		m.Logger().Debug("interfaces unavailable", "error", err)
+		ifaces = nil
+		for _, c := range counters {
+			ifaces = append(ifaces, sysinfo.NetInterface{Name: c.Name, Link: "unknown"})
+		}
+	}
+	var shown []sysinfo.NetInterface
+	var rxTotal, txTotal float64
+	for _, iface := range ifaces {
+		if !m.shown(iface.Name) {
+			continue
+		}
+		shown = append(shown, iface)
+		rxTotal += byName[iface.Name].RxRate
+		txTotal += byName[iface.Name].TxRate
+	}
+
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	var updates []Region
+
+	// Total throughput
+	header := fmt.Sprintf("↓ %s  ↑ %s", formatRate(rxTotal), formatRate(txTotal))
+	if m.Changed("header", header) {
+		reg := Region{130, 8, m.Width() - 135, 24}
+		r.Clear(reg)
+		r.DrawTextRightFit(float64(reg.X), float64(reg.Y)+2, float64(reg.W), header, m.fonts.Normal, m.Colors().Text, EllipsisEnd)
+		updates = append(updates, reg)
+	}
+
+	// Throughput graphs, each scaled to its own recent peak
+	m.rx.Push(rxTotal)
+	m.tx.Push(txTotal)
+	for i, h := range []*History{m.rx, m.tx} {
+		scale := niceScale(h.Max())
+		name := []string{"rx", "tx"}[i]
+		if m.Changed(name+"_scale", scale) {
+			// Redraw the whole graph at the new scale
+			m.Forget(name + "_graph")
+			reg := Region{5, 62 + i*44, 52, 18}
+			r.Clear(reg)
+			r.DrawText(float64(reg.X), float64(reg.Y), sysinfo.FormatBytes(uint64(scale)), m.fonts.Small, m.Colors().TextDim)
+			updates = append(updates, reg)
+		}
+		reg := m.graphRegion(i)
+		style := GraphStyle{Kind: GraphArea, Min: 0, Max: scale, Color: m.Colors().BarLow}
+		if i == 1 {
+			style.Color = m.Colors().BarMed
+		}
+		m.UpdateGraph(r, name+"_graph", reg, h.Values(), style)
+		updates = append(updates, reg)
+	}
+
+	// Interface rows
+	for i := 0; i < m.numRows; i++ {
+		reg := Region{0, m.ifaceListY() + i*m.rowHeight, m.Width(), m.rowHeight - 2}
+		key := fmt.Sprintf("iface_%d", i)
+		if i >= len(shown) {
+			if m.Changed(key, "") {
+				r.Clear(reg)
+				updates = append(updates, reg)
+			}
+			continue
+		}
+		iface := shown[i]
+		rate := byName[iface.Name]
+		if m.Changed(key, fmt.Sprint(iface, formatRate(rate.RxRate), formatRate(rate.TxRate),
+			sysinfo.FormatBytes(rate.RxTotal), sysinfo.FormatBytes(rate.TxTotal))) {
+			m.renderInterfaceRow(r, reg, iface, rate)
+			updates = append(updates, reg)
+		}
+	}
+
+	// Top talkers
+	if m.showTalkers && m.now().Sub(m.lastTalkers) >= talkerInterval {
+		m.lastTalkers = m.now()
+		talkers, err := m.readTalkers(6)
+		if err != nil {
+			m.Logger().Debug("connections unavailable", "error", err)
+		}
+		m.talkers = talkers
+	}
+	if m.showTalkers {
+		var parts []string
+		for _, t := range m.talkers {
+			parts = append(parts, fmt.Sprintf("%s:%d", t.Name, t.Conns))
+		}
+		if m.Changed("talkers", strings.Join(parts, " ")) {
+			reg := Region{0, m.talkersY + 20, m.Width(), m.Height() - m.talkersY - 20}
+			m.renderTalkers(r, reg)
+			updates = append(updates, reg)
+		}
+	}
+
+	// Push updates to display
+	for _, reg := range updates {
+		if err := m.DrawRegion(reg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderInterfaceRow draws one interface: link dot, name, rates and totals,
+// with its speed and addresses underneath.
+func (m *NetworkMonitor) renderInterfaceRow(r *Renderer, reg Region, iface sysinfo.NetInterface, rate sysinfo.NetRate) {
+	r.Clear(reg)
+	colors := m.Colors()
+	y := float64(reg.Y)
+
+	linkColor := colors.TextDim
+	switch iface.Link {
+	case "up":
+		linkColor = colors.BarLow
+	case "down", "lowerlayerdown":
+		linkColor = colors.BarHigh
+	}
+	r.DrawCircle(12, y+10, 5, linkColor)
+
+	r.DrawTextFit(22, y, 90, iface.Name, m.fonts.Normal, colors.Text, EllipsisMiddle)
+	r.DrawTextFit(115, y, 95, "↓ "+formatRate(rate.RxRate), m.fonts.Normal, colors.Text, EllipsisEnd)
+	r.DrawTextFit(215, y, 95, "↑ "+formatRate(rate.TxRate), m.fonts.Normal, colors.Text, EllipsisEnd)
+	totals := sysinfo.FormatBytes(rate.RxTotal) + " / " + sysinfo.FormatBytes(rate.TxTotal)
+	r.DrawTextRightFit(315, y+2, float64(reg.W-320), totals, m.fonts.Small, colors.TextDim, EllipsisEnd)
+
+	detail := strings.Join(iface.Addrs, "  ")
+	if iface.SpeedMbps > 0 {
+		detail = fmt.Sprintf("%dMb/s  %s", iface.SpeedMbps, detail)
+	}
+	if iface.Link != "up" {
+		detail = iface.Link + "  " + detail
+	}
+	r.DrawTextFit(22, y+18, float64(reg.W-27), detail, m.fonts.Small-2, colors.TextDim, EllipsisEnd)
+}
+
+// renderTalkers draws the process groups with the most connections in two
+// columns.
+func (m *NetworkMonitor) renderTalkers(r *Renderer, reg Region) {
+	r.Clear(reg)
+	if len(m.talkers) == 0 {
+		r.DrawText(float64(reg.X+5), float64(reg.Y), "No connections visible", m.fonts.Small, m.Colors().TextDim)
+		return
+	}
+	colW := reg.W / 2
+	rows := (len(m.talkers) + 1) / 2
+	for i, t := range m.talkers {
+		x := float64(reg.X + 5 + (i/rows)*colW)
+		y := float64(reg.Y + (i%rows)*17)
+		r.DrawTextFit(x, y, float64(colW-60), t.Name, m.fonts.Small, m.Colors().Text, EllipsisEnd)
+		r.DrawTextRight(x, y, float64(colW-15), fmt.Sprint(t.Conns), m.fonts.Small, m.Colors().TextDim)
+	}
+}
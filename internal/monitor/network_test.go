@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func TestNetworkMonitorUpdate(t *testing.T) {
+	m := NewNetworkMonitor(lcd.NewSimulated(320, 480), 50, time.Second, nil)
+	now := time.Unix(1000, 0)
+	var rx uint64
+	m.now = func() time.Time { return now }
+	m.readCounters = func() ([]sysinfo.NetCounters, error) {
+		return []sysinfo.NetCounters{
+			{Name: "lo", BytesRecv: rx * 10, BytesSent: rx * 10},
+			{Name: "eth0", BytesRecv: rx, BytesSent: rx / 2},
+			{Name: "veth12ab", BytesRecv: rx, BytesSent: rx},
+		}, nil
+	}
+	m.readInterfaces = func() ([]sysinfo.NetInterface, error) {
+		return []sysinfo.NetInterface{
+			{Name: "lo", Up: true, Loopback: true, Link: "unknown"},
+			{Name: "eth0", Up: true, Link: "up", SpeedMbps: 1000, Addrs: []string{"192.168.1.5/24"}},
+			{Name: "veth12ab", Up: true, Link: "up"},
+		}, nil
+	}
+	talkerScans := 0
+	m.readTalkers = func(n int) ([]sysinfo.NetTalker, error) {
+		talkerScans++
+		return []sysinfo.NetTalker{{Name: "firefox", Conns: 12}}, nil
+	}
+	m.setupLayout()
+
+	for i := 0; i < 3; i++ {
+		rx += 4 << 20
+		if err := m.update(); err != nil {
+			t.Fatalf("update() error = %v", err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	// Loopback and container interfaces are excluded from the totals
+	if got := m.rx.Last(); got != 4<<20 {
+		t.Errorf("rx rate = %v, want %v", got, 4<<20)
+	}
+	if got := m.tx.Last(); got != 2<<20 {
+		t.Errorf("tx rate = %v, want %v", got, 2<<20)
+	}
+	if talkerScans != 1 {
+		t.Errorf("connections scanned %d times in 3s, want 1", talkerScans)
+	}
+}
+
+func TestNiceScale(t *testing.T) {
+	tests := []struct{ in, want float64 }{
+		{0, 10 * 1024},
+		{15000, 20000},
+		{20000, 20000},
+		{3.1e6, 5e6},
+		{7e8, 1e9},
+	}
+	for _, tt := range tests {
+		if got := niceScale(tt.in); got != tt.want {
+			t.Errorf("niceScale(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
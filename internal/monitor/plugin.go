@@ -0,0 +1,556 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+)
+
+func init() {
+	Register("plugin", func() PluginOptions { return PluginOptions{Mode: "exec", Timeout: Duration(10 * time.Second)} },
+		func(p Params, opts PluginOptions) (Monitor, error) {
+			if opts.Mode != "exec" && opts.Mode != "stream" {
+				return nil, fmt.Errorf("mode: unknown value %q (want exec or stream)", opts.Mode)
+			}
+			return NewPluginMonitor(p.Screen, p.Brightness, p.Interval, p.Logger, PluginConfig{
+				Command: opts.Command,
+				Stream:  opts.Mode == "stream",
+				Timeout: time.Duration(opts.Timeout),
+				Title:   opts.Title,
+			})
+		})
+}
+
+// PluginOptions are the config options for the "plugin" monitor.
+type PluginOptions struct {
+	Command []string `json:"command"`         // Executable and arguments
+	Mode    string   `json:"mode"`            // exec (run every interval) or stream (long-running)
+	Timeout Duration `json:"timeout"`         // exec: run time limit; stream: max silence
+	Title   string   `json:"title,omitempty"` // Header until the plugin sends one
+}
+
+// PluginConfig configures a plugin monitor.
+type PluginConfig struct {
+	Command []string
+	Stream  bool          // Keep the command running and read one frame per line
+	Timeout time.Duration // Exec: run time limit; stream: max time between frames
+	Title   string
+}
+
+// PluginFrame is one screenful of plugin output:
+//
+//	{"title": "Builds", "widgets": [
+//	  {"type": "text", "label": "Queue", "text": "12 jobs", "color": "bar_med"},
+//	  {"type": "bar", "label": "Lag", "value": 4.2, "max": 30, "text": "4.2s"},
+//	  {"type": "sparkline", "label": "Depth", "value": 12},
+//	  {"type": "table", "columns": ["host", "lag"], "rows": [["db1", "2s"]]}
+//	]}
+type PluginFrame struct {
+	Title   string         `json:"title,omitempty"`
+	Widgets []PluginWidget `json:"widgets"`
+}
+
+// PluginWidget is one widget of a plugin frame. Which fields apply depends
+// on Type. Colors are hex (#rrggbb), a theme color key such as "bar_high",
+// or an agent status such as "error".
+type PluginWidget struct {
+	Type      string     `json:"type"` // text, bar, sparkline or table
+	Label     string     `json:"label,omitempty"`
+	Text      string     `json:"text,omitempty"`       // text: the value; bar: shown after the bar
+	Value     *float64   `json:"value,omitempty"`      // bar: fill; sparkline: appended to history
+	Values    []float64  `json:"values,omitempty"`     // sparkline: whole series, replaces history
+	Min       float64    `json:"min,omitempty"`        // bar: default 0; sparkline: auto when min == max
+	Max       float64    `json:"max,omitempty"`        // bar: default 100
+	Columns   []string   `json:"columns,omitempty"`    // table header
+	Rows      [][]string `json:"rows,omitempty"`       // table cells
+	Color     string     `json:"color,omitempty"`      // Text, bar or line color
+	RowColors []string   `json:"row_colors,omitempty"` // table: color per row
+}
+
+// Plugin widget heights in pixels.
+const (
+	pluginTextH      = 22
+	pluginBarH       = 26
+	pluginSparklineH = 34
+	pluginTableRowH  = 18
+)
+
+// ParsePluginFrame decodes and validates one frame of plugin output.
+func ParsePluginFrame(data []byte) (*PluginFrame, error) {
+	var f PluginFrame
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid frame: %w", err)
+	}
+	theme := DefaultTheme()
+	for i, w := range f.Widgets {
+		switch w.Type {
+		case "text", "table":
+		case "bar":
+			if w.Value == nil {
+				return nil, fmt.Errorf("widgets[%d]: bar has no value", i)
+			}
+		case "sparkline":
+			if w.Value == nil && len(w.Values) == 0 {
+				return nil, fmt.Errorf("widgets[%d]: sparkline has no value or values", i)
+			}
+		default:
+			return nil, fmt.Errorf("widgets[%d]: unknown type %q", i, w.Type)
+		}
+		for _, c := range append([]string{w.Color}, w.RowColors...) {
+			if _, err := theme.NamedColor(c, nil); err != nil {
+				return nil, fmt.Errorf("widgets[%d]: color %q: %w", i, c, err)
+			}
+		}
+	}
+	return &f, nil
+}
+
+// PluginMonitor renders widgets described by an external command.
+type PluginMonitor struct {
+	*Base
+	cfg    PluginConfig
+	sparks *HistoryStore
+
+	mu       sync.Mutex // Guards the fields below, written by the stream reader
+	frame    *PluginFrame
+	seq      int // Incremented per received frame
+	err      error
+	received time.Time
+
+	ctx    context.Context // Cancelled by Stop to end the plugin process
+	cancel context.CancelFunc
+}
+
+// NewPluginMonitor creates a plugin monitor.
+func NewPluginMonitor(screen lcd.Screen, brightness int, interval time.Duration, logger *slog.Logger, cfg PluginConfig) (*PluginMonitor, error) {
+	if len(cfg.Command) == 0 || cfg.Command[0] == "" {
+		return nil, errors.New("command: must name an executable")
+	}
+	if cfg.Title == "" {
+		cfg.Title = filepath.Base(cfg.Command[0])
+	}
+
+	base := NewBase(Config{
+		Screen:   screen,
+		Theme:    DefaultTheme(),
+		Fonts:    DefaultFontConfig(),
+		Interval: interval,
+		Logger:   logger,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PluginMonitor{
+		Base:   base,
+		cfg:    cfg,
+		sparks: NewHistoryStore(base.Width()),
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// Name returns the monitor name.
+func (m *PluginMonitor) Name() string { return "Plugin" }
+
+// Run starts the plugin monitor loop.
+func (m *PluginMonitor) Run() error {
+	m.SetRunning(true)
+	ctx := m.ctx
+
+	m.ClearBuffer()
+	m.drawStatic()
+	if err := m.DrawFullBuffer(); err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	m.Logger().Info("started", "monitor", m.Name(), "command", m.cfg.Command[0])
+
+	if m.cfg.Stream {
+		m.mu.Lock()
+		m.received = time.Now()
+		m.mu.Unlock()
+		go m.stream(ctx)
+	}
+
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+
+	for m.Running() {
+		select {
+		case <-ticker.C:
+			if err := m.Tick(func() error { return m.update(ctx) }); err != nil {
+				m.Logger().Error("update failed", "error", err)
+			}
+		case <-ctx.Done():
+			// Stopped, possibly before Run set running
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the monitor and any running plugin process.
+func (m *PluginMonitor) Stop() {
+	m.SetRunning(false)
+	m.cancel()
+}
+
+// setResult records a frame and/or error from the plugin.
+func (m *PluginMonitor) setResult(f *PluginFrame, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if f != nil {
+		m.frame = f
+		m.seq++
+		m.received = time.Now()
+	}
+	m.err = err
+}
+
+// runOnce runs the command once and parses its stdout as a single frame.
+func (m *PluginMonitor) runOnce(ctx context.Context) (*PluginFrame, error) {
+	if m.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.cfg.Timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, m.cfg.Command[0], m.cfg.Command[1:]...)
+	cmd.WaitDelay = time.Second
+
+	out, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timed out after %s", m.cfg.Timeout)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("%w: %s", err, lastLine(exitErr.Stderr))
+		}
+		return nil, err
+	}
+	return ParsePluginFrame(out)
+}
+
+// stream keeps the command running, restarting it with backoff when it
+// exits, until ctx is cancelled.
+func (m *PluginMonitor) stream(ctx context.Context) {
+	backoff := time.Second
+	for {
+		frames, err := m.streamOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("exited")
+		}
+		m.setResult(nil, fmt.Errorf("plugin stopped: %w", err))
+		m.Logger().Warn("plugin stopped", "command", m.cfg.Command[0], "error", err, "retry", backoff)
+
+		if frames > 0 {
+			backoff = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+// streamOnce runs the command and reads newline-delimited frames until it
+// exits, returning the number of lines read.
+func (m *PluginMonitor) streamOnce(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, m.cfg.Command[0], m.cfg.Command[1:]...)
+	cmd.WaitDelay = time.Second
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	frames := 0
+	sc := bufio.NewScanner(stdout)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		// A bad line is shown as an error but keeps the last good frame
+		m.setResult(ParsePluginFrame(line))
+		frames++
+	}
+	scanErr := sc.Err()
+	cancel()
+
+	err = cmd.Wait()
+	switch {
+	case scanErr != nil:
+		return frames, scanErr
+	case stderr.Len() > 0:
+		return frames, fmt.Errorf("%v: %s", err, lastLine(stderr.Bytes()))
+	}
+	return frames, err
+}
+
+// lastLine returns the last non-empty line of b, for error messages.
+func lastLine(b []byte) string {
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	return string(lines[len(lines)-1])
+}
+
+// bodyRegion returns the area widgets are drawn in.
+func (m *PluginMonitor) bodyRegion() Region {
+	return Region{5, 40, m.Width() - 10, m.Height() - 40 - 24}
+}
+
+// statusRegion returns the status line at the bottom of the screen.
+func (m *PluginMonitor) statusRegion() Region {
+	return Region{5, m.Height() - 20, m.Width() - 10, 18}
+}
+
+func (m *PluginMonitor) drawStatic() {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	// Header separator
+	r.DrawLine(0, 32, float64(m.Width()))
+}
+
+func (m *PluginMonitor) update(ctx context.Context) error {
+	if !m.cfg.Stream {
+		m.setResult(m.runOnce(ctx))
+	}
+
+	m.mu.Lock()
+	frame, seq, pluginErr := m.frame, m.seq, m.err
+	if m.cfg.Stream && m.cfg.Timeout > 0 && pluginErr == nil {
+		if silent := time.Since(m.received); silent > m.cfg.Timeout {
+			pluginErr = fmt.Errorf("no output for %s", silent.Round(time.Second))
+		}
+	}
+	m.mu.Unlock()
+
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	var updates []Region
+
+	// Header
+	title := m.cfg.Title
+	if frame != nil && frame.Title != "" {
+		title = frame.Title
+	}
+	if m.Changed("header", title) {
+		reg := Region{5, 8, m.Width() - 10, 24}
+		r.Clear(reg)
+		r.DrawTextFit(float64(reg.X), float64(reg.Y), float64(reg.W), title, m.fonts.Large, m.Colors().Header, EllipsisEnd)
+		updates = append(updates, reg)
+	}
+
+	// Widgets, or the error when there is nothing to show yet
+	body := m.bodyRegion()
+	switch {
+	case frame != nil:
+		if m.Changed("frame", seq) {
+			m.pushSparks(frame)
+			r.Clear(body)
+			m.renderFrame(r, body, frame)
+			updates = append(updates, body)
+		}
+	case pluginErr != nil:
+		if m.Changed("frame", pluginErr.Error()) {
+			r.Clear(body)
+			r.DrawWrapped(float64(body.X), float64(body.Y), float64(body.W), pluginErr.Error(),
+				m.fonts.Normal, m.fonts.Normal+4, body.H/int(m.fonts.Normal+4), m.Colors().BarHigh)
+			updates = append(updates, body)
+		}
+	default:
+		if m.Changed("frame", "waiting") {
+			r.Clear(body)
+			r.DrawText(float64(body.X), float64(body.Y), "Waiting for plugin...", m.fonts.Normal, m.Colors().TextDim)
+			updates = append(updates, body)
+		}
+	}
+
+	// Status line
+	status := ""
+	if pluginErr != nil && frame != nil {
+		status = pluginErr.Error()
+	}
+	if m.Changed("status", status) {
+		reg := m.statusRegion()
+		r.Clear(reg)
+		r.DrawTextFit(float64(reg.X), float64(reg.Y), float64(reg.W), status, m.fonts.Small, m.Colors().BarHigh, EllipsisEnd)
+		updates = append(updates, reg)
+	}
+
+	for _, reg := range updates {
+		if err := m.DrawRegion(reg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sparkKey identifies the history of the i-th widget.
+func sparkKey(i int, w PluginWidget) string {
+	return fmt.Sprintf("%d_%s", i, w.Label)
+}
+
+// pushSparks appends single-value sparkline samples to their histories.
+func (m *PluginMonitor) pushSparks(f *PluginFrame) {
+	for i, w := range f.Widgets {
+		if w.Type == "sparkline" && len(w.Values) == 0 && w.Value != nil {
+			m.sparks.Push(sparkKey(i, w), *w.Value)
+		}
+	}
+}
+
+// renderFrame draws a frame's widgets top to bottom, dropping those that
+// don't fit.
+func (m *PluginMonitor) renderFrame(r *Renderer, body Region, f *PluginFrame) {
+	theme := m.Theme()
+	labelW := float64(body.W) * 0.3
+	y := body.Y
+	bottom := body.Y + body.H
+
+	for i, w := range f.Widgets {
+		fg, _ := theme.NamedColor(w.Color, theme.Colors.Text)
+		x := float64(body.X)
+		valueX := x + labelW + 5
+		valueW := float64(body.W) - labelW - 5
+
+		switch w.Type {
+		case "text":
+			if y+pluginTextH > bottom {
+				return
+			}
+			if w.Label == "" {
+				r.DrawTextFit(x, float64(y), float64(body.W), w.Text, m.fonts.Normal, fg, EllipsisEnd)
+			} else {
+				r.DrawTextFit(x, float64(y), labelW, w.Label, m.fonts.Normal, theme.Colors.TextDim, EllipsisEnd)
+				r.DrawTextFit(valueX, float64(y), valueW, w.Text, m.fonts.Normal, fg, EllipsisEnd)
+			}
+			y += pluginTextH
+
+		case "bar":
+			if y+pluginBarH > bottom {
+				return
+			}
+			hi := w.Max
+			if hi <= w.Min {
+				hi = w.Min + 100
+			}
+			r.DrawTextFit(x, float64(y), labelW, w.Label, m.fonts.Normal, theme.Colors.TextDim, EllipsisEnd)
+			textW := 0.0
+			if w.Text != "" {
+				textW = 80
+				r.DrawTextRightFit(valueX, float64(y), valueW, w.Text, m.fonts.Small, theme.Colors.Text, EllipsisEnd)
+			}
+			style := BarStyle{Border: true}
+			if w.Color != "" {
+				scale := NewScale(Stop{0, fg})
+				style.Scale = &scale
+			}
+			r.DrawBarStyled(Region{int(valueX), y, int(valueW - textW), pluginBarH - 6}, *w.Value, w.Min, hi, style)
+			y += pluginBarH
+
+		case "sparkline":
+			if y+pluginSparklineH > bottom {
+				return
+			}
+			values := w.Values
+			if len(values) == 0 {
+				values = m.sparks.Get(sparkKey(i, w)).Values()
+			}
+			style := GraphStyle{Kind: GraphLine, Min: w.Min, Max: w.Max}
+			if w.Color != "" {
+				style.Color = fg
+			}
+			if style.Max <= style.Min {
+				style.Min, style.Max = seriesRange(values)
+			}
+			r.DrawTextFit(x, float64(y), labelW, w.Label, m.fonts.Normal, theme.Colors.TextDim, EllipsisEnd)
+			if len(values) > 0 {
+				r.DrawTextFit(x, float64(y+16), labelW, formatPluginValue(values[len(values)-1]), m.fonts.Small, theme.Colors.Text, EllipsisEnd)
+			}
+			r.DrawGraph(Region{int(valueX), y, int(valueW), pluginSparklineH - 4}, values, style)
+			y += pluginSparklineH
+
+		case "table":
+			cols := len(w.Columns)
+			for _, row := range w.Rows {
+				cols = max(cols, len(row))
+			}
+			if cols == 0 {
+				continue
+			}
+			colW := float64(body.W) / float64(cols)
+			if len(w.Columns) > 0 {
+				if y+pluginTableRowH > bottom {
+					return
+				}
+				for c, name := range w.Columns {
+					r.DrawTextFit(x+float64(c)*colW, float64(y), colW-4, name, m.fonts.Small, theme.Colors.Header, EllipsisEnd)
+				}
+				y += pluginTableRowH
+			}
+			for ri, row := range w.Rows {
+				if y+pluginTableRowH > bottom {
+					return
+				}
+				rowFG := fg
+				if ri < len(w.RowColors) {
+					rowFG, _ = theme.NamedColor(w.RowColors[ri], fg)
+				}
+				for c, cell := range row {
+					r.DrawTextFit(x+float64(c)*colW, float64(y), colW-4, cell, m.fonts.Small, rowFG, EllipsisEnd)
+				}
+				y += pluginTableRowH
+			}
+		}
+	}
+}
+
+// seriesRange returns a graph range that fits values, padded so a flat
+// series is drawn mid-height.
+func seriesRange(values []float64) (lo, hi float64) {
+	if len(values) == 0 {
+		return 0, 1
+	}
+	lo, hi = values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	if hi == lo {
+		return lo - 1, hi + 1
+	}
+	return lo, hi
+}
+
+// formatPluginValue formats a sample compactly.
+func formatPluginValue(v float64) string {
+	if v == float64(int64(v)) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,169 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+)
+
+// TestPluginHelper is run as the plugin command by the tests below.
+func TestPluginHelper(t *testing.T) {
+	if os.Getenv("PLUGIN_HELPER") != "1" {
+		return
+	}
+	switch os.Args[len(os.Args)-1] {
+	case "frame":
+		fmt.Println(`{"title": "Builds", "widgets": [{"type": "text", "label": "Queue", "text": "12"}]}`)
+	case "stream":
+		fmt.Println(`{"title": "one", "widgets": []}`)
+		fmt.Println()
+		fmt.Println(`{"title": "two", "widgets": []}`)
+	case "fail":
+		fmt.Fprintln(os.Stderr, "connecting")
+		fmt.Fprintln(os.Stderr, "db unreachable")
+		os.Exit(3)
+	case "hang":
+		time.Sleep(10 * time.Second)
+	}
+	os.Exit(0)
+}
+
+func newTestPlugin(t *testing.T, mode string, timeout time.Duration) *PluginMonitor {
+	t.Helper()
+	t.Setenv("PLUGIN_HELPER", "1")
+	m, err := NewPluginMonitor(lcd.NewSimulated(320, 480), 50, time.Second, nil, PluginConfig{
+		Command: []string{os.Args[0], "-test.run=^TestPluginHelper$", "--", mode},
+		Stream:  mode == "stream",
+		Timeout: timeout,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestParsePluginFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr string
+	}{
+		{"all widgets", `{"widgets": [
+			{"type": "text", "text": "ok", "color": "bar_high"},
+			{"type": "bar", "value": 3, "color": "#0f0"},
+			{"type": "sparkline", "values": [1, 2], "color": "waiting"},
+			{"type": "table", "rows": [["a"]], "row_colors": ["error"]}]}`, ""},
+		{"not json", `uptime 5d`, "invalid frame"},
+		{"unknown type", `{"widgets": [{"type": "pie"}]}`, `widgets[0]: unknown type "pie"`},
+		{"bar without value", `{"widgets": [{"type": "text"}, {"type": "bar"}]}`, "widgets[1]: bar has no value"},
+		{"empty sparkline", `{"widgets": [{"type": "sparkline"}]}`, "no value or values"},
+		{"bad color", `{"widgets": [{"type": "text", "color": "teal"}]}`, `color "teal"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePluginFrame([]byte(tt.in))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ParsePluginFrame() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParsePluginFrame() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPluginExec(t *testing.T) {
+	tests := []struct {
+		mode    string
+		timeout time.Duration
+		want    string
+		wantErr string
+	}{
+		{"frame", 0, "Builds", ""},
+		{"fail", 0, "", "db unreachable"},
+		{"hang", 100 * time.Millisecond, "", "timed out after 100ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			m := newTestPlugin(t, tt.mode, tt.timeout)
+			f, err := m.runOnce(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("runOnce() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runOnce() error = %v", err)
+			}
+			if f.Title != tt.want {
+				t.Errorf("title = %q, want %q", f.Title, tt.want)
+			}
+		})
+	}
+}
+
+func TestPluginStream(t *testing.T) {
+	m := newTestPlugin(t, "stream", 0)
+	frames, err := m.streamOnce(context.Background())
+	if err != nil {
+		t.Fatalf("streamOnce() error = %v", err)
+	}
+	if frames != 2 || m.seq != 2 {
+		t.Errorf("frames = %d, seq = %d, want 2", frames, m.seq)
+	}
+	if m.frame.Title != "two" {
+		t.Errorf("title = %q, want two", m.frame.Title)
+	}
+}
+
+func TestPluginRender(t *testing.T) {
+	m := newTestPlugin(t, "stream", time.Hour)
+	f, err := ParsePluginFrame([]byte(`{"title": "Replication", "widgets": [
+		{"type": "text", "label": "Primary", "text": "db1"},
+		{"type": "bar", "label": "Lag", "value": 42, "text": "42s", "color": "bar_high"},
+		{"type": "sparkline", "label": "Queue", "value": 3},
+		{"type": "table", "columns": ["host", "lag"], "rows": [["db2", "1s"], ["db3", "9s"]]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.setResult(f, nil)
+	if err := m.update(context.Background()); err != nil {
+		t.Fatalf("update() error = %v", err)
+	}
+	if got := m.sparks.Get("2_Queue").Values(); len(got) != 1 || got[0] != 3 {
+		t.Errorf("sparkline history = %v, want [3]", got)
+	}
+
+	// A failing plugin keeps the last frame and reports in the status line
+	m.setResult(nil, fmt.Errorf("exit status 1"))
+	if err := m.update(context.Background()); err != nil {
+		t.Fatalf("update() error = %v", err)
+	}
+	if m.Changed("status", "exit status 1") {
+		t.Error("status line does not show the plugin error")
+	}
+}
+
+func TestPluginMonitorStopBeforeRun(t *testing.T) {
+	m, err := NewPluginMonitor(newRecordingScreen(320, 480), 50, time.Hour, nil, PluginConfig{Command: []string{"true"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+	done := make(chan error)
+	go func() { done <- m.Run() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() after Stop() did not return")
+	}
+}
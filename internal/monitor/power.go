@@ -0,0 +1,333 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func init() {
+	Register("power", func() PowerOptions { return PowerOptions{} },
+		func(p Params, opts PowerOptions) (Monitor, error) {
+			m := NewPowerMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+			m.zones = opts.Zones
+			return m, nil
+		})
+}
+
+// PowerOptions are the config options for the "power" monitor.
+type PowerOptions struct {
+	Zones []string `json:"zones,omitempty"` // RAPL zone names to show, e.g. package-0, core; empty shows all
+}
+
+const (
+	maxBatteries    = 2
+	batteryHeight   = 58
+	raplRowHeight   = 24
+	minGraphHeight  = 40
+	defaultPackageW = 15.0 // Bar range of zones without a power limit, until exceeded
+)
+
+// PowerMonitor displays battery charge, AC state and CPU power draw.
+type PowerMonitor struct {
+	*Base
+	zones []string
+
+	sampler   *sysinfo.RAPLSampler
+	history   *History
+	peak      map[string]float64 // Highest draw seen per zone without a limit
+	batteries int
+	raplY     int
+	raplRows  int
+	graphY    int
+
+	// Readers, replaceable in tests
+	readSupplies func() (sysinfo.PowerSupplies, error)
+	readRAPL     func() ([]sysinfo.RAPLZone, error)
+	now          func() time.Time
+}
+
+// NewPowerMonitor creates a new power monitor.
+func NewPowerMonitor(screen lcd.Screen, brightness int, interval time.Duration, logger *slog.Logger) *PowerMonitor {
+	base := NewBase(Config{
+		Screen:   screen,
+		Theme:    DefaultTheme(),
+		Fonts:    DefaultFontConfig(),
+		Interval: interval,
+		Logger:   logger,
+	})
+
+	return &PowerMonitor{
+		Base:         base,
+		sampler:      sysinfo.NewRAPLSampler(),
+		peak:         make(map[string]float64),
+		readSupplies: sysinfo.GetPowerSupplies,
+		readRAPL:     sysinfo.GetRAPL,
+		now:          time.Now,
+	}
+}
+
+// Name returns the monitor name.
+func (m *PowerMonitor) Name() string { return "Power" }
+
+// Run starts the power monitor loop.
+func (m *PowerMonitor) Run() error {
+	m.SetRunning(true)
+
+	// Calculate layout
+	m.setupLayout()
+
+	// Initial draw
+	m.ClearBuffer()
+	m.drawStatic()
+	if err := m.DrawFullBuffer(); err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	m.Logger().Info("started", "monitor", m.Name())
+
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+
+	for m.Running() {
+		select {
+		case <-ticker.C:
+			if err := m.Tick(m.update); err != nil {
+				m.Logger().Error("update failed", "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the monitor.
+func (m *PowerMonitor) Stop() {
+	m.SetRunning(false)
+}
+
+// setupLayout sizes the sections to the batteries and RAPL zones present
+// at startup: batteries at the top, then a row per zone, and a graph of
+// the CPU package power in the space left.
+func (m *PowerMonitor) setupLayout() {
+	ps, _ := m.readSupplies()
+	m.batteries = min(len(ps.Batteries), maxBatteries)
+	zones, _ := m.readRAPL()
+	m.raplRows = len(m.shownZones(zones))
+
+	m.raplY = 42 + m.batteries*batteryHeight
+	m.graphY = m.Height()
+	if m.raplRows > 0 {
+		m.graphY = m.raplY + 20 + m.raplRows*raplRowHeight + 6
+		if m.Height()-5-m.graphY < minGraphHeight {
+			m.graphY = m.Height()
+		}
+	}
+	m.history = NewHistory(m.Width() - 10)
+}
+
+// batteryRegion returns the region of battery i.
+func (m *PowerMonitor) batteryRegion(i int) Region {
+	return Region{0, 42 + i*batteryHeight, m.Width(), batteryHeight - 4}
+}
+
+// zoneRegion returns the region of RAPL row i.
+func (m *PowerMonitor) zoneRegion(i int) Region {
+	return Region{0, m.raplY + 20 + i*raplRowHeight, m.Width(), raplRowHeight - 2}
+}
+
+// graphRegion returns the region of the package power graph.
+func (m *PowerMonitor) graphRegion() Region {
+	return Region{5, m.graphY, m.Width() - 10, m.Height() - 5 - m.graphY}
+}
+
+func (m *PowerMonitor) drawStatic() {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	r.DrawText(5, 8, "Power", m.fonts.Large, m.Colors().Header)
+	r.DrawLine(0, 34, float64(m.Width()))
+	if m.raplRows > 0 {
+		r.DrawText(5, float64(m.raplY), "CPU power", m.fonts.Small, m.Colors().Header)
+	}
+	if m.batteries == 0 && m.raplRows == 0 {
+		r.DrawWrapped(5, 45, float64(m.Width()-10), "No battery or RAPL power readings (RAPL needs root on recent kernels)",
+			m.fonts.Normal, m.fonts.Normal+4, 4, m.Colors().TextDim)
+	}
+}
+
+// shownZones filters RAPL zones by name.
+func (m *PowerMonitor) shownZones(zones []sysinfo.RAPLZone) []sysinfo.RAPLZone {
+	if len(m.zones) == 0 {
+		return zones
+	}
+	var shown []sysinfo.RAPLZone
+	for _, z := range zones {
+		if contains(m.zones, z.Name) {
+			shown = append(shown, z)
+		}
+	}
+	return shown
+}
+
+func (m *PowerMonitor) update() error {
+	ps, err := m.readSupplies()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	zones, err := m.readRAPL()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	power := m.sampler.Sample(m.shownZones(zones), m.now())
+
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	var updates []Region
+
+	// Power source, and the battery draw when running on it
+	source, sourceColor := "", m.Colors().BarLow
+	switch {
+	case ps.ACOnline:
+		source = "AC"
+	case len(ps.Batteries) > 0:
+		source, sourceColor = "On battery", m.Colors().BarMed
+		var draw float64
+		for _, b := range ps.Batteries {
+			if b.Status == "Discharging" {
+				draw += b.PowerW
+			}
+		}
+		if draw > 0 {
+			source += fmt.Sprintf(" · %.1f W", draw)
+		}
+	}
+	if m.Changed("source", source) {
+		reg := Region{130, 8, m.Width() - 135, 24}
+		r.Clear(reg)
+		r.DrawTextRightFit(float64(reg.X), float64(reg.Y)+2, float64(reg.W), source, m.fonts.Normal, sourceColor, EllipsisEnd)
+		updates = append(updates, reg)
+	}
+
+	// Batteries
+	for i := 0; i < m.batteries; i++ {
+		reg := m.batteryRegion(i)
+		key := fmt.Sprintf("battery_%d", i)
+		if i >= len(ps.Batteries) {
+			if m.Changed(key, "") {
+				r.Clear(reg)
+				updates = append(updates, reg)
+			}
+			continue
+		}
+		b := ps.Batteries[i]
+		if m.Changed(key, fmt.Sprint(b.Name, b.Capacity, batteryDetail(b))) {
+			m.renderBattery(r, reg, b)
+			updates = append(updates, reg)
+		}
+	}
+
+	// RAPL zones
+	for i := 0; i < m.raplRows; i++ {
+		reg := m.zoneRegion(i)
+		key := fmt.Sprintf("zone_%d", i)
+		if i >= len(power) {
+			if m.Changed(key, "") {
+				r.Clear(reg)
+				updates = append(updates, reg)
+			}
+			continue
+		}
+		p := power[i]
+		if m.ChangedFloat(key, p.Watts, 0.1) {
+			m.renderZone(r, reg, p)
+			updates = append(updates, reg)
+		}
+	}
+
+	// Package power graph
+	if reg := m.graphRegion(); reg.H >= minGraphHeight {
+		var pkg float64
+		for _, p := range power {
+			if strings.HasPrefix(p.Name, "package") {
+				pkg += p.Watts
+			}
+		}
+		m.history.Push(pkg)
+		// Scale in 10 W steps, redrawing the graph when the step changes
+		scale := math.Ceil(max(m.history.Max(), defaultPackageW)/10) * 10
+		if m.Changed("power_scale", scale) {
+			m.Forget("power_graph")
+		}
+		m.UpdateGraph(r, "power_graph", reg, m.history.Values(), GraphStyle{Kind: GraphArea, Min: 0, Max: scale})
+		updates = append(updates, reg)
+	}
+
+	// Push updates to display
+	for _, reg := range updates {
+		if err := m.DrawRegion(reg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batteryDetail describes a battery's status, time left, rate and health.
+func batteryDetail(b sysinfo.Battery) string {
+	parts := []string{b.Status}
+	if b.TimeRemaining > 0 {
+		left := fmt.Sprintf("%dh %02dm", int(b.TimeRemaining.Hours()), int(b.TimeRemaining.Minutes())%60)
+		if b.Status == "Charging" {
+			parts = append(parts, left+" to full")
+		} else {
+			parts = append(parts, left+" left")
+		}
+	}
+	if b.PowerW > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f W", b.PowerW))
+	}
+	if h := b.Health(); h > 0 {
+		parts = append(parts, fmt.Sprintf("health %.0f%%", h))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// renderBattery draws a battery's charge bar, where low charge is red,
+// with its status underneath.
+func (m *PowerMonitor) renderBattery(r *Renderer, reg Region, b sysinfo.Battery) {
+	r.Clear(reg)
+	colors := m.Colors()
+	y := float64(reg.Y)
+	scale := m.Theme().DescendingScale()
+
+	r.DrawTextFit(5, y+2, 80, b.Name, m.fonts.Normal, colors.Text, EllipsisEnd)
+	r.DrawBarStyled(Region{90, reg.Y + 2, reg.W - 175, 22}, b.Capacity, 0, 100, BarStyle{Scale: &scale, Border: true})
+	r.DrawTextRight(float64(reg.W-80), y+2, 75, fmt.Sprintf("%.0f%%", b.Capacity), m.fonts.Normal, scale.Color(b.Capacity))
+	r.DrawTextFit(90, y+30, float64(reg.W-95), batteryDetail(b), m.fonts.Small-2, colors.TextDim, EllipsisEnd)
+}
+
+// renderZone draws a RAPL zone's draw against its power limit, or the
+// highest draw seen without one.
+func (m *PowerMonitor) renderZone(r *Renderer, reg Region, p sysinfo.RAPLPower) {
+	r.Clear(reg)
+	colors := m.Colors()
+	y := float64(reg.Y)
+
+	limit := p.LimitW
+	if limit == 0 {
+		m.peak[p.Name] = max(m.peak[p.Name], p.Watts, defaultPackageW)
+		limit = m.peak[p.Name]
+	}
+	r.DrawTextFit(5, y+2, 80, p.Name, m.fonts.Small, colors.Text, EllipsisEnd)
+	r.DrawBarStyled(Region{90, reg.Y + 4, reg.W - 175, reg.H - 8}, p.Watts, 0, limit, BarStyle{Border: true})
+	r.DrawTextRight(float64(reg.W-80), y+2, 75, fmt.Sprintf("%.1f W", p.Watts), m.fonts.Small, colors.Text)
+}
@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func TestBatteryDetail(t *testing.T) {
+	tests := []struct {
+		name string
+		b    sysinfo.Battery
+		want string
+	}{
+		{"discharging", sysinfo.Battery{Status: "Discharging", PowerW: 8, TimeRemaining: 150 * time.Minute, FullWh: 44, DesignWh: 50},
+			"Discharging · 2h 30m left · 8.0 W · health 88%"},
+		{"charging", sysinfo.Battery{Status: "Charging", PowerW: 30.5, TimeRemaining: 45 * time.Minute},
+			"Charging · 0h 45m to full · 30.5 W"},
+		{"full", sysinfo.Battery{Status: "Full"}, "Full"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := batteryDetail(tt.b); got != tt.want {
+				t.Errorf("batteryDetail() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPowerMonitorUpdate(t *testing.T) {
+	m := NewPowerMonitor(newRecordingScreen(320, 480), 50, time.Second, nil)
+	m.readSupplies = func() (sysinfo.PowerSupplies, error) {
+		return sysinfo.PowerSupplies{
+			Batteries: []sysinfo.Battery{{Name: "BAT0", Status: "Discharging", Capacity: 64, PowerW: 9.5}},
+			HasAC:     true,
+		}, nil
+	}
+	energy := uint64(1_000_000)
+	m.readRAPL = func() ([]sysinfo.RAPLZone, error) {
+		return []sysinfo.RAPLZone{
+			{Name: "package-0", Path: "intel-rapl:0", EnergyUJ: energy, LimitW: 28},
+			{Name: "core", Path: "intel-rapl:0:0", EnergyUJ: energy / 2},
+		}, nil
+	}
+	start := time.Unix(1000, 0)
+	m.now = func() time.Time { return start }
+	m.zones = []string{"package-0"}
+
+	m.setupLayout()
+	if m.batteries != 1 || m.raplRows != 1 {
+		t.Fatalf("layout has %d batteries, %d zones, want 1 and 1", m.batteries, m.raplRows)
+	}
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.cache["source"]; got != "On battery · 9.5 W" {
+		t.Errorf("source = %v, want on battery", got)
+	}
+
+	energy += 12_000_000
+	m.now = func() time.Time { return start.Add(2 * time.Second) }
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.cache["zone_0"]; got != 6.0 {
+		t.Errorf("package power = %v, want 6", got)
+	}
+
+	// Batteries going away and RAPL becoming unreadable clear their rows
+	m.readSupplies = func() (sysinfo.PowerSupplies, error) { return sysinfo.PowerSupplies{}, os.ErrNotExist }
+	m.readRAPL = func() ([]sysinfo.RAPLZone, error) { return nil, os.ErrNotExist }
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if m.cache["battery_0"] != "" || m.cache["zone_0"] != "" || m.cache["source"] != "" {
+		t.Errorf("rows not cleared: %v, %v, %v", m.cache["battery_0"], m.cache["zone_0"], m.cache["source"])
+	}
+}
@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+// ProcessGroupOptions are the process grouping options of monitors that
+// list processes.
+type ProcessGroupOptions struct {
+	GroupBy string             `json:"group_by,omitempty"` // name (default), unit, cgroup or container
+	Groups  []ProcessGroupRule `json:"groups,omitempty"`   // Rules tried in order before the built-in ones
+}
+
+// ProcessGroupRule is a configured sysinfo.GroupRule.
+type ProcessGroupRule struct {
+	Name    string `json:"name"`              // Group name, may use $1 for submatches
+	Comm    string `json:"comm,omitempty"`    // Process name regexp
+	Exe     string `json:"exe,omitempty"`     // Executable path regexp
+	Cmdline string `json:"cmdline,omitempty"` // Space-joined arguments regexp
+}
+
+// groupModes maps group_by values to grouping modes.
+var groupModes = map[string]sysinfo.GroupBy{
+	"":          sysinfo.GroupByName,
+	"name":      sysinfo.GroupByName,
+	"unit":      sysinfo.GroupByUnit,
+	"cgroup":    sysinfo.GroupByCgroup,
+	"container": sysinfo.GroupByContainer,
+}
+
+// newProcessGrouper builds the grouper configured by opts.
+func newProcessGrouper(opts ProcessGroupOptions) (*sysinfo.ProcessGrouper, error) {
+	by, ok := groupModes[opts.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("group_by: unknown value %q (want name, unit, cgroup or container)", opts.GroupBy)
+	}
+	rules := make([]sysinfo.GroupRule, len(opts.Groups))
+	for i, r := range opts.Groups {
+		rules[i] = sysinfo.GroupRule{Name: r.Name, Comm: r.Comm, Exe: r.Exe, Cmdline: r.Cmdline}
+	}
+	g, err := sysinfo.NewProcessGrouper(by, rules)
+	if err != nil {
+		return nil, fmt.Errorf("groups: %w", err)
+	}
+	g.ContainerName = sysinfo.ContainerName
+	return g, nil
+}
@@ -2,19 +2,73 @@ package monitor
 
 import (
 	"fmt"
+	"image/color"
 	"log/slog"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
 	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
 )
 
+func init() {
+	Register("ram", func() RAMOptions {
+		return RAMOptions{Processes: 5, HogGrowthMB: 1024, HogWindow: Duration(30 * time.Second)}
+	},
+		func(p Params, opts RAMOptions) (Monitor, error) {
+			if opts.Processes < 1 {
+				return nil, fmt.Errorf("processes: must be at least 1")
+			}
+			if opts.Events < 0 {
+				return nil, fmt.Errorf("events: must not be negative, got %d", opts.Events)
+			}
+			if opts.HogGrowthMB < 1 || opts.HogWindow <= 0 {
+				return nil, fmt.Errorf("hog_growth_mb and hog_window must be positive")
+			}
+			grouper, err := newProcessGrouper(opts.ProcessGroupOptions)
+			if err != nil {
+				return nil, err
+			}
+			m := NewRAMMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+			m.numRows = opts.Processes
+			m.numEvents = opts.Events
+			m.watcher.GrowthBytes = uint64(opts.HogGrowthMB) << 20
+			m.watcher.GrowthWindow = time.Duration(opts.HogWindow)
+			m.SetProcessGrouper(grouper)
+			return m, nil
+		})
+}
+
+// RAMOptions are the config options for the "ram" monitor.
+type RAMOptions struct {
+	Processes   int      `json:"processes"`     // Rows in the process list
+	Events      int      `json:"events"`        // Rows of memory event history, 0 for none
+	HogGrowthMB int      `json:"hog_growth_mb"` // Process group growth reported as a memory hog
+	HogWindow   Duration `json:"hog_window"`    // Time within which hog_growth_mb counts
+	ProcessGroupOptions
+}
+
 // RAMMonitor displays memory usage information.
 type RAMMonitor struct {
 	*Base
 	processListY int
 	rowHeight    int
 	numRows      int
+	history      *History
+
+	// OOM kills and memory hogs, newest first, and the one on the banner
+	watcher     *sysinfo.MemWatcher
+	events      []sysinfo.MemEvent
+	numEvents   int
+	eventsY     int
+	banner      *sysinfo.MemEvent
+	bannerUntil time.Time
+
+	// Readers, replaceable in tests
+	readMem   func() (*sysinfo.MemInfo, error)
+	readProcs func(n int) ([]sysinfo.ProcessMemInfo, error)
+	now       func() time.Time
 }
 
 // NewRAMMonitor creates a new RAM monitor.
@@ -26,21 +80,28 @@ func NewRAMMonitor(screen lcd.Screen, brightness int, interval time.Duration, lo
 
 	base := NewBase(Config{
 		Screen:   screen,
-		Colors:   DefaultColors(),
+		Theme:    DefaultTheme(),
 		Fonts:    fonts,
 		Interval: interval,
 		Logger:   logger,
 	})
 
 	return &RAMMonitor{
-		Base:    base,
-		numRows: 5,
+		Base:      base,
+		numRows:   5,
+		watcher:   sysinfo.NewMemWatcher(),
+		readMem:   sysinfo.GetMemInfo,
+		readProcs: sysinfo.GetTopProcesses,
+		now:       time.Now,
 	}
 }
 
 // Name returns the monitor name.
 func (m *RAMMonitor) Name() string { return "RAM" }
 
+// SetProcessGrouper sets how the process list groups processes.
+func (m *RAMMonitor) SetProcessGrouper(g *sysinfo.ProcessGrouper) { m.readProcs = g.TopMemory }
+
 // Run starts the RAM monitor loop.
 func (m *RAMMonitor) Run() error {
 	m.SetRunning(true)
@@ -63,7 +124,7 @@ func (m *RAMMonitor) Run() error {
 	for m.Running() {
 		select {
 		case <-ticker.C:
-			if err := m.update(); err != nil {
+			if err := m.Tick(m.update); err != nil {
 				m.Logger().Error("update failed", "error", err)
 			}
 		}
@@ -78,51 +139,88 @@ func (m *RAMMonitor) Stop() {
 }
 
 func (m *RAMMonitor) setupLayout() {
-	// Process list starts after RAM/Swap bars and header
-	m.processListY = 138
+	// Process list starts after RAM/Swap bars, details and header
+	m.processListY = 160
+
+	// Event history at the bottom, taking the place of process rows that
+	// no longer fit
+	m.eventsY = m.Height()
+	if m.numEvents > 0 {
+		m.eventsY = m.Height() - 4 - m.numEvents*eventRowHeight
+		m.numRows = max(min(m.numRows, (m.eventsY-6-m.processListY)/28), 1)
+	}
 
 	// Calculate row height
-	availableHeight := m.Height() - m.processListY - 10
+	availableHeight := m.eventsY - m.processListY - 10
 	m.rowHeight = availableHeight / m.numRows
 	if m.rowHeight < 28 {
 		m.rowHeight = 28
 	}
+
+	m.history = NewHistory(m.graphRegion().W)
+}
+
+// graphRegion returns the region of the memory usage history graph.
+func (m *RAMMonitor) graphRegion() Region {
+	return Region{m.Width() - 155, 6, 150, 26}
 }
 
 func (m *RAMMonitor) drawStatic() {
 	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
-	r := NewRenderer(dc, m.Colors(), m.fonts)
+	r := NewRenderer(dc, m.Theme(), m.fonts)
 
 	// Separator lines
 	r.DrawLine(0, 35, float64(m.Width()))
 	r.DrawLine(0, float64(m.processListY-5), float64(m.Width()))
+	if m.numEvents > 0 {
+		r.DrawLine(0, float64(m.eventsY-4), float64(m.Width()))
+	}
 }
 
 func (m *RAMMonitor) update() error {
-	memInfo, err := sysinfo.GetMemInfo()
+	memInfo, err := m.readMem()
 	if err != nil {
 		return err
 	}
 
-	procs, err := sysinfo.GetTopProcesses(m.numRows)
+	// The watcher looks at every group, so one jumping from nowhere into
+	// the top rows has a baseline
+	procs, err := m.readProcs(math.MaxInt)
 	if err != nil {
 		return err
 	}
+	m.recordEvents(m.watcher.Check(procs, m.now()))
+	procs = procs[:min(len(procs), m.numRows)]
 
 	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
-	r := NewRenderer(dc, m.Colors(), m.fonts)
+	r := NewRenderer(dc, m.Theme(), m.fonts)
 
 	var updates []Region
 
+	// Alert banner over the header
+	bannerReg, bannerChanged := m.updateBanner(r)
+	if bannerChanged {
+		updates = append(updates, bannerReg)
+	}
+
 	// Header
-	header := fmt.Sprintf("RAM Monitor - %s total", sysinfo.FormatBytes(memInfo.Total))
-	if m.Changed("header", header) {
-		reg := Region{5, 8, m.Width() - 10, 24}
+	header := fmt.Sprintf("RAM - %s total", sysinfo.FormatBytes(memInfo.Total))
+	if m.banner == nil && m.Changed("header", header) {
+		reg := Region{5, 8, m.Width() - 170, 24}
 		r.Clear(reg)
-		r.DrawText(float64(reg.X), float64(reg.Y), header, m.fonts.Large, m.Colors().Header)
+		r.DrawTextFit(float64(reg.X), float64(reg.Y), float64(reg.W), header, m.fonts.Large, m.Colors().Header, EllipsisEnd)
 		updates = append(updates, reg)
 	}
 
+	// Usage history graph
+	m.history.Push(memInfo.UsedPercent)
+	if m.banner == nil {
+		graphReg := m.graphRegion()
+		m.UpdateGraph(r, "ram_graph", graphReg, m.history.Values(),
+			GraphStyle{Kind: GraphArea, Min: 0, Max: 100})
+		updates = append(updates, graphReg)
+	}
+
 	// RAM label
 	if m.Changed("ram_label", true) {
 		reg := Region{5, 40, 45, 20}
@@ -131,10 +229,14 @@ func (m *RAMMonitor) update() error {
 		updates = append(updates, reg)
 	}
 
-	// RAM bar
-	if m.ChangedFloat("ram_pct", memInfo.UsedPercent, 0.5) {
+	// RAM bar: used, then buffers and page cache
+	cachePct := float64(memInfo.Buffers+memInfo.Cached) / float64(max(memInfo.Total, 1)) * 100
+	if m.Changed("ram_bar", fmt.Sprintf("%.0f %.0f", memInfo.UsedPercent*2, cachePct*2)) {
 		reg := Region{55, 40, 250, 24}
-		r.DrawBar(reg, memInfo.UsedPercent, 0, 100, true)
+		r.DrawStackedBar(reg, []BarSegment{
+			{memInfo.UsedPercent, m.Theme().Scale().Color(memInfo.UsedPercent)},
+			{cachePct, m.Colors().Border},
+		}, 100, true)
 		updates = append(updates, reg)
 	}
 
@@ -158,7 +260,10 @@ func (m *RAMMonitor) update() error {
 	// Swap bar
 	if m.ChangedFloat("swap_pct", memInfo.SwapPercent, 0.5) {
 		reg := Region{55, 75, m.Width() - 180, 20}
-		r.DrawBar(reg, memInfo.SwapPercent, 0, 100, true)
+		// Any swap use is worth noticing
+		c := m.Colors()
+		scale := NewScale(Stop{0, c.BarLow}, Stop{1, c.BarMed}, Stop{25, c.BarHigh})
+		r.DrawBarStyled(reg, memInfo.SwapPercent, 0, 100, BarStyle{Scale: &scale, Border: true})
 		updates = append(updates, reg)
 	}
 
@@ -176,9 +281,23 @@ func (m *RAMMonitor) update() error {
 		updates = append(updates, reg)
 	}
 
+	// Cache, compressed swap and hugepage details
+	details := memDetails(memInfo)
+	if m.Changed("details", details) {
+		reg := Region{5, 103, m.Width() - 125, 20}
+		r.Clear(reg)
+		r.DrawTextFit(float64(reg.X), float64(reg.Y)+1, float64(reg.W), details, m.fonts.Small-2, m.Colors().TextDim, EllipsisEnd)
+		updates = append(updates, reg)
+	}
+
+	// Memory pressure
+	if reg, ok := m.updatePressure(r, memInfo.Pressure); ok {
+		updates = append(updates, reg)
+	}
+
 	// Process header
 	if m.Changed("proc_header", true) {
-		reg := Region{5, 110, m.Width() - 10, 22}
+		reg := Region{5, 132, m.Width() - 10, 22}
 		r.Clear(reg)
 		r.DrawText(float64(reg.X), float64(reg.Y), "PROCESS                    MEM        %     #", m.fonts.Normal, m.Colors().Header)
 		updates = append(updates, reg)
@@ -199,11 +318,8 @@ func (m *RAMMonitor) update() error {
 				// Name
 				nameReg := Region{5, rowY, 160, m.rowHeight}
 				r.Clear(nameReg)
-				name := proc.Name
-				if len(name) > 18 {
-					name = name[:18]
-				}
-				r.DrawText(float64(nameReg.X), float64(nameReg.Y), name, m.fonts.Normal, m.Colors().TextDim)
+				r.DrawTextFit(float64(nameReg.X), float64(nameReg.Y), float64(nameReg.W),
+					proc.Name, m.fonts.Normal, m.Colors().TextDim, EllipsisEnd)
 				updates = append(updates, nameReg)
 
 				// Bar
@@ -245,6 +361,9 @@ func (m *RAMMonitor) update() error {
 		}
 	}
 
+	// Event history
+	updates = append(updates, m.updateEvents(r)...)
+
 	// Send updates to display
 	for _, reg := range updates {
 		if err := m.DrawRegion(reg); err != nil {
@@ -258,3 +377,68 @@ func (m *RAMMonitor) update() error {
 
 	return nil
 }
+
+// Memory pressure levels of the "some" and "full" 10 second averages.
+// Full stalls mean every task is waiting on reclaim, which comes before the
+// OOM killer, so they count for more.
+const (
+	psiSomeWarn = 10
+	psiSomeCrit = 40
+	psiFullWarn = 1
+	psiFullCrit = 10
+)
+
+// pressureColor returns the indicator color for a memory pressure reading.
+func pressureColor(c Colors, psi *sysinfo.PSI) color.Color {
+	switch {
+	case psi.Some10 >= psiSomeCrit || psi.Full10 >= psiFullCrit:
+		return c.BarHigh
+	case psi.Some10 >= psiSomeWarn || psi.Full10 >= psiFullWarn:
+		return c.BarMed
+	}
+	return c.BarLow
+}
+
+// updatePressure redraws the memory pressure indicator if it changed. It
+// shows nothing on kernels without PSI.
+func (m *RAMMonitor) updatePressure(r *Renderer, psi *sysinfo.PSI) (Region, bool) {
+	text := ""
+	var c color.Color
+	if psi != nil {
+		text = fmt.Sprintf("PSI %.0f%%", psi.Some10)
+		if psi.Full10 >= 0.5 {
+			text += fmt.Sprintf(" %.0f%%", psi.Full10)
+		}
+		c = pressureColor(m.Colors(), psi)
+	}
+	reg := Region{m.Width() - 115, 103, 110, 20}
+	// The level can change while the rounded text stays the same
+	if !m.Changed("psi", fmt.Sprint(text, c)) {
+		return reg, false
+	}
+	r.Clear(reg)
+	if psi != nil {
+		r.DrawCircle(float64(reg.X+6), float64(reg.Y+10), 5, c)
+		r.DrawTextRight(float64(reg.X+14), float64(reg.Y), float64(reg.W-14), text, m.fonts.Small, c)
+	}
+	return reg, true
+}
+
+// memDetails describes the page cache, compressed swap and hugepages,
+// leaving out what the system doesn't use.
+func memDetails(info *sysinfo.MemInfo) string {
+	parts := []string{"cache " + sysinfo.FormatBytes(info.Buffers+info.Cached)}
+	if dirty := info.Dirty + info.Writeback; dirty >= 1<<20 {
+		parts = append(parts, "dirty "+sysinfo.FormatBytes(dirty))
+	}
+	if ratio := info.ZramRatio(); ratio > 0 {
+		parts = append(parts, fmt.Sprintf("zram %.1fx", ratio))
+	}
+	if info.ZswapPool > 0 {
+		parts = append(parts, "zswap "+sysinfo.FormatBytes(info.ZswapPool))
+	}
+	if info.HugeTotal > 0 {
+		parts = append(parts, fmt.Sprintf("huge %s/%s", sysinfo.FormatBytes(info.HugeUsed), sysinfo.FormatBytes(info.HugeTotal)))
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,116 @@
+package monitor
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+const (
+	maxMemEvents   = 20               // Events kept in the history
+	bannerDuration = 15 * time.Second // Time an alert stays on the banner
+	eventRowHeight = 18
+)
+
+// recordEvents adds new events to the history and puts the most serious
+// on the banner. OOM kills outrank growth, and a banner showing an OOM kill
+// isn't replaced by growth before it expires.
+func (m *RAMMonitor) recordEvents(events []sysinfo.MemEvent) {
+	for i := range events {
+		e := events[i]
+		m.events = append([]sysinfo.MemEvent{e}, m.events...)
+		showing := m.banner != nil && m.now().Before(m.bannerUntil)
+		if showing && m.banner.Kind == sysinfo.MemEventOOMKill && e.Kind != sysinfo.MemEventOOMKill {
+			continue
+		}
+		m.banner = &e
+		m.bannerUntil = m.now().Add(bannerDuration)
+	}
+	if len(m.events) > maxMemEvents {
+		m.events = m.events[:maxMemEvents]
+	}
+}
+
+// memEventText describes an event in a line.
+func memEventText(e sysinfo.MemEvent) string {
+	if e.Kind == sysinfo.MemEventGrowth {
+		return fmt.Sprintf("%s grew %s to %s", e.Name, sysinfo.FormatBytes(e.Growth), sysinfo.FormatBytes(e.RSS))
+	}
+	text := "OOM kill"
+	if e.Count > 1 {
+		text = fmt.Sprintf("%d OOM kills", e.Count)
+	}
+	if e.Name != "" {
+		text += " in " + path.Base(e.Name)
+	}
+	return text
+}
+
+// memEventColor returns the color of an event: OOM kills are high, growth
+// medium.
+func (m *RAMMonitor) memEventColor(e sysinfo.MemEvent) Colors {
+	c := m.Colors()
+	if e.Kind == sysinfo.MemEventOOMKill {
+		c.Text = c.BarHigh
+	} else {
+		c.Text = c.BarMed
+	}
+	return c
+}
+
+// updateBanner draws the alert banner over the header while an event is
+// on it, and hands the header back once it expires.
+func (m *RAMMonitor) updateBanner(r *Renderer) (Region, bool) {
+	reg := Region{0, 0, m.Width(), 34}
+	if m.banner != nil && !m.now().Before(m.bannerUntil) {
+		m.banner = nil
+		m.Forget("header", "ram_graph")
+	}
+
+	text := ""
+	if m.banner != nil {
+		text = m.banner.Time.Format("15:04 ") + memEventText(*m.banner)
+	}
+	if !m.Changed("banner", text) {
+		return reg, false
+	}
+	r.Clear(reg)
+	if m.banner != nil {
+		r.dc.SetColor(m.memEventColor(*m.banner).Text)
+		r.dc.DrawRectangle(float64(reg.X), float64(reg.Y), float64(reg.W), float64(reg.H-1))
+		r.dc.Fill()
+		r.DrawTextFit(float64(reg.X+5), float64(reg.Y+6), float64(reg.W-10), text, m.fonts.Normal, m.Colors().BG, EllipsisEnd)
+	}
+	return reg, true
+}
+
+// updateEvents redraws the rows of the event history that changed.
+func (m *RAMMonitor) updateEvents(r *Renderer) []Region {
+	var updates []Region
+	for i := 0; i < m.numEvents; i++ {
+		reg := Region{5, m.eventsY + i*eventRowHeight, m.Width() - 10, eventRowHeight}
+		var e *sysinfo.MemEvent
+		text := ""
+		switch {
+		case i < len(m.events):
+			e = &m.events[i]
+			text = e.Time.Format("15:04:05 ") + memEventText(*e)
+		case i == 0:
+			text = "No OOM kills or memory hogs"
+		}
+		if !m.Changed(fmt.Sprintf("event_%d", i), text) {
+			continue
+		}
+		r.Clear(reg)
+		if e == nil {
+			r.DrawText(float64(reg.X), float64(reg.Y), text, m.fonts.Small-2, m.Colors().TextDim)
+		} else {
+			r.DrawText(float64(reg.X), float64(reg.Y), e.Time.Format("15:04:05"), m.fonts.Small-2, m.Colors().TextDim)
+			r.DrawTextFit(float64(reg.X+80), float64(reg.Y), float64(reg.W-80), memEventText(*e), m.fonts.Small-2, m.memEventColor(*e).Text, EllipsisEnd)
+		}
+		updates = append(updates, reg)
+	}
+	return updates
+}
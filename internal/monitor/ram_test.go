@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func TestPressureColor(t *testing.T) {
+	c := DefaultColors()
+	tests := []struct {
+		psi  sysinfo.PSI
+		want string
+	}{
+		{sysinfo.PSI{}, "low"},
+		{sysinfo.PSI{Some10: 12}, "med"},
+		{sysinfo.PSI{Some10: 5, Full10: 2}, "med"},
+		{sysinfo.PSI{Some10: 45}, "high"},
+		{sysinfo.PSI{Some10: 20, Full10: 15}, "high"},
+	}
+	names := map[any]string{c.BarLow: "low", c.BarMed: "med", c.BarHigh: "high"}
+	for _, tt := range tests {
+		if got := names[pressureColor(c, &tt.psi)]; got != tt.want {
+			t.Errorf("pressureColor(%+v) = %s, want %s", tt.psi, got, tt.want)
+		}
+	}
+}
+
+func TestUpdatePressure(t *testing.T) {
+	m := NewRAMMonitor(newRecordingScreen(320, 480), 50, time.Second, nil)
+	r := NewRenderer(m.NewContext(Region{0, 0, m.Width(), m.Height()}), m.Theme(), m.fonts)
+
+	// 9.6 and 10.4 both show as 10%, but the second crosses the warning level
+	for _, tt := range []struct {
+		some   float64
+		redraw bool
+	}{{9.6, true}, {10.4, true}, {10.3, false}, {39.6, true}, {40.2, true}} {
+		if _, redrawn := m.updatePressure(r, &sysinfo.PSI{Some10: tt.some}); redrawn != tt.redraw {
+			t.Errorf("updatePressure(%v) redrawn = %v, want %v", tt.some, redrawn, tt.redraw)
+		}
+	}
+}
+
+func TestMemDetails(t *testing.T) {
+	tests := []struct {
+		info sysinfo.MemInfo
+		want string
+	}{
+		{sysinfo.MemInfo{Cached: 3 << 30, Buffers: 1 << 30, Dirty: 4 << 10}, "cache 4.0G"},
+		{sysinfo.MemInfo{Cached: 1 << 30, Dirty: 30 << 20, ZramOrig: 900, ZramCompr: 300, HugeTotal: 2 << 30, HugeUsed: 1 << 30},
+			"cache 1.0G · dirty 30M · zram 3.0x · huge 1.0G/2.0G"},
+	}
+	for _, tt := range tests {
+		if got := memDetails(&tt.info); got != tt.want {
+			t.Errorf("memDetails() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestMemEventText(t *testing.T) {
+	tests := []struct {
+		e    sysinfo.MemEvent
+		want string
+	}{
+		{sysinfo.MemEvent{Kind: sysinfo.MemEventOOMKill, Count: 1}, "OOM kill"},
+		{sysinfo.MemEvent{Kind: sysinfo.MemEventOOMKill, Count: 3, Name: "system.slice/postgres.service"}, "3 OOM kills in postgres.service"},
+		{sysinfo.MemEvent{Kind: sysinfo.MemEventGrowth, Name: "chrome", Growth: 2 << 30, RSS: 5 << 30}, "chrome grew 2.0G to 5.0G"},
+	}
+	for _, tt := range tests {
+		if got := memEventText(tt.e); got != tt.want {
+			t.Errorf("memEventText(%+v) = %q, want %q", tt.e, got, tt.want)
+		}
+	}
+}
+
+func TestRecordEvents(t *testing.T) {
+	m := NewRAMMonitor(newRecordingScreen(320, 480), 50, time.Second, nil)
+	now := time.Unix(1000, 0)
+	m.now = func() time.Time { return now }
+
+	oom := sysinfo.MemEvent{Time: now, Kind: sysinfo.MemEventOOMKill, Count: 1}
+	growth := sysinfo.MemEvent{Time: now, Kind: sysinfo.MemEventGrowth, Name: "chrome"}
+	m.recordEvents([]sysinfo.MemEvent{oom})
+	m.recordEvents([]sysinfo.MemEvent{growth})
+	if len(m.events) != 2 || m.events[0].Kind != sysinfo.MemEventGrowth {
+		t.Fatalf("events = %+v, want growth then OOM kill", m.events)
+	}
+	if m.banner == nil || m.banner.Kind != sysinfo.MemEventOOMKill {
+		t.Errorf("banner = %+v, want the OOM kill to stay on it", m.banner)
+	}
+
+	// Once the OOM kill expires, growth takes the banner
+	now = now.Add(bannerDuration)
+	m.recordEvents([]sysinfo.MemEvent{growth})
+	if m.banner == nil || m.banner.Kind != sysinfo.MemEventGrowth {
+		t.Errorf("banner = %+v, want growth", m.banner)
+	}
+
+	for i := 0; i < maxMemEvents; i++ {
+		m.recordEvents([]sysinfo.MemEvent{growth})
+	}
+	if len(m.events) != maxMemEvents {
+		t.Errorf("len(events) = %d, want %d", len(m.events), maxMemEvents)
+	}
+}
+
+func TestRAMMonitorWatchesAllGroups(t *testing.T) {
+	m := NewRAMMonitor(newRecordingScreen(320, 480), 50, time.Second, nil)
+	now := time.Unix(1000, 0)
+	m.now = func() time.Time { return now }
+	m.readMem = func() (*sysinfo.MemInfo, error) {
+		return &sysinfo.MemInfo{Total: 32 << 30, Used: 8 << 30, Available: 24 << 30}, nil
+	}
+	// Many groups of 100 MiB, and a small one that leaks 2 GiB at once
+	leak := uint64(10 << 20)
+	m.readProcs = func(n int) ([]sysinfo.ProcessMemInfo, error) {
+		var procs []sysinfo.ProcessMemInfo
+		if leak > 100<<20 {
+			procs = append(procs, sysinfo.ProcessMemInfo{Name: "leak", RSS: leak})
+		}
+		for i := 0; i < 40; i++ {
+			procs = append(procs, sysinfo.ProcessMemInfo{Name: fmt.Sprintf("worker-%d", i), RSS: 100 << 20})
+		}
+		if leak <= 100<<20 {
+			procs = append(procs, sysinfo.ProcessMemInfo{Name: "leak", RSS: leak})
+		}
+		return procs[:min(n, len(procs))], nil
+	}
+	m.setupLayout()
+
+	for _, rss := range []uint64{10 << 20, 10 << 20, 2 << 30} {
+		leak = rss
+		if err := m.update(); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Second)
+	}
+	if len(m.events) != 1 || m.events[0].Name != "leak" {
+		t.Errorf("events = %+v, want the leak", m.events)
+	}
+}
@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+)
+
+// Params are the settings common to every monitor, supplied by the daemon.
+type Params struct {
+	Screen     lcd.Screen
+	Brightness int
+	Interval   time.Duration
+	Logger     *slog.Logger
+}
+
+// registration is a registered monitor type.
+type registration struct {
+	decode func(raw json.RawMessage) (any, error)
+	build  func(p Params, opts any) (Monitor, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]registration)
+)
+
+// Register makes a monitor type available by name. defaults returns the
+// options used for keys the config leaves out; factory builds the monitor.
+// Monitor packages call Register from init, so importing a package (even
+// with a blank import) is enough to make its monitors configurable.
+// Register panics if name is already registered.
+func Register[O any](name string, defaults func() O, factory func(p Params, opts O) (Monitor, error)) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic("monitor: Register called twice for " + name)
+	}
+	registry[name] = registration{
+		decode: func(raw json.RawMessage) (any, error) {
+			opts := defaults()
+			if len(bytes.TrimSpace(raw)) == 0 || string(bytes.TrimSpace(raw)) == "null" {
+				return opts, nil
+			}
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&opts); err != nil {
+				return nil, err
+			}
+			return opts, nil
+		},
+		build: func(p Params, opts any) (Monitor, error) {
+			return factory(p, opts.(O))
+		},
+	}
+}
+
+// Registered returns the names of all registered monitor types.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New builds a registered monitor from JSON options.
+func New(name string, p Params, options json.RawMessage) (Monitor, error) {
+	registryMu.RLock()
+	reg, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown monitor %q (registered: %v)", name, Registered())
+	}
+
+	opts, err := reg.decode(options)
+	if err != nil {
+		return nil, fmt.Errorf("monitor %q options: %w", name, err)
+	}
+	m, err := reg.build(p, opts)
+	if err != nil {
+		return nil, fmt.Errorf("monitor %q: %w", name, err)
+	}
+	return m, nil
+}
+
+// Spec is the config entry for one screen's monitor:
+//
+//	{"monitor": "cpu", "theme": "amber", "options": {"layout": "heatmap"}}
+type Spec struct {
+	Monitor string          `json:"monitor"`
+	Theme   string          `json:"theme,omitempty"`   // Built-in name or theme file path
+	Options json.RawMessage `json:"options,omitempty"` // Decoded into the monitor's options
+}
+
+// Build creates the monitor described by the spec and applies its theme.
+func (s Spec) Build(p Params) (Monitor, error) {
+	var theme Theme
+	if s.Theme != "" {
+		t, err := LoadTheme(s.Theme)
+		if err != nil {
+			return nil, fmt.Errorf("monitor %q theme: %w", s.Monitor, err)
+		}
+		theme = t
+	}
+
+	m, err := New(s.Monitor, p, s.Options)
+	if err != nil {
+		return nil, err
+	}
+	if s.Theme != "" {
+		ts, ok := m.(interface{ SetTheme(Theme) })
+		if !ok {
+			return nil, fmt.Errorf("monitor %q does not support themes", s.Monitor)
+		}
+		ts.SetTheme(theme)
+	}
+	return m, nil
+}
+
+// Duration is a time.Duration that decodes from JSON as either a Go duration
+// string ("1m30s") or a number of seconds.
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		s, err := strconv.Unquote(string(b))
+		if err != nil {
+			return err
+		}
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = Duration(v)
+		return nil
+	}
+	secs, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return fmt.Errorf("invalid duration %s", b)
+	}
+	*d = Duration(secs * float64(time.Second))
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
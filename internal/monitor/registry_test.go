@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+)
+
+func TestRegistryNew(t *testing.T) {
+	p := Params{Screen: lcd.NewSimulated(320, 480), Brightness: 50, Interval: time.Second}
+
+	tests := []struct {
+		name    string
+		monitor string
+		options string
+		wantErr string
+	}{
+		{"defaults", "cpu", "", ""},
+		{"null options", "ram", "null", ""},
+		{"typed options", "cpu", `{"layout": "heatmap"}`, ""},
+		{"unknown monitor", "nope", "", `unknown monitor "nope"`},
+		{"unknown key", "cpu", `{"layuot": "bars"}`, `"layuot"`},
+		{"wrong type", "agent", `{"rows": "five"}`, `monitor "agent" options`},
+		{"bad value", "cpu", `{"layout": "pie"}`, `"pie"`},
+		{"group rules", "ram", `{"group_by": "unit", "groups": [{"name": "tests", "cmdline": "go test"}]}`, ""},
+		{"bad group rule", "ram", `{"groups": [{"name": "x", "comm": "("}]}`, `groups: group rule "x"`},
+		{"process table", "cpu", `{"top_processes": 7}`, ""},
+		{"process table too tall", "cpu", `{"top_processes": 8}`, "at most 7"},
+		{"bad group mode", "cpu", `{"group_by": "pid"}`, `"pid"`},
+		{"cgroup filters", "cgroups", `{"sort": "memory", "kinds": ["container"], "exclude": ["*/init.scope"]}`, ""},
+		{"bad cgroup kind", "cgroups", `{"kinds": ["vm"]}`, `"vm"`},
+		{"container socket", "containers", `{"socket": "/run/user/1000/podman/podman.sock", "sort": "name"}`, ""},
+		{"bad container sort", "containers", `{"sort": "age"}`, `"age"`},
+		{"systemd watch list", "systemd", `{"watch": ["nginx.service", "postgresql@*"], "user_units": ["syncthing.service"]}`, ""},
+		{"bad unit glob", "systemd", `{"ignore": ["[fwupd"]}`, `"[fwupd"`},
+		{"rapl zones", "power", `{"zones": ["package-0", "core"]}`, ""},
+		{"clock zones", "clock", `{"face": "analog", "zones": [{"name": "Tokyo", "tz": "Asia/Tokyo"}], "countdowns": [{"name": "Standup", "at": "09:30", "days": ["mon", "fri"]}]}`, ""},
+		{"bad clock face", "clock", `{"face": "sundial"}`, `"sundial"`},
+		{"bad time zone", "clock", `{"zones": [{"tz": "Europe/Berlin"}, {"tz": "Mars/Olympus_Mons"}]}`, "zones[1]"},
+		{"bad countdown", "clock", `{"countdowns": [{"name": "Standup", "at": "soon"}]}`, "countdowns[0]"},
+		{"nested pane", "compositor", `{"panes": [{"monitor": "cpu"}, {"monitor": "ram", "weight": 2}]}`, ""},
+		{"nested error", "carousel", `{"pages": [{"monitor": "cpu", "options": {"bogus": 1}}]}`, `pages[0]: monitor "cpu" options`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(tt.monitor, p, json.RawMessage(tt.options))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("New() error = %v, want containing %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if m == nil {
+				t.Fatal("New() returned nil monitor")
+			}
+		})
+	}
+}
+
+func TestSpecBuild(t *testing.T) {
+	var spec Spec
+	if err := json.Unmarshal([]byte(`{"monitor": "cpu", "theme": "amber", "options": {"layout": "bars"}}`), &spec); err != nil {
+		t.Fatal(err)
+	}
+	m, err := spec.Build(Params{Screen: lcd.NewSimulated(320, 480)})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	cpu := m.(*CPUMonitor)
+	if cpu.Theme().Name != "amber" {
+		t.Errorf("theme = %q, want amber", cpu.Theme().Name)
+	}
+	if cpu.layout != CPULayoutBars {
+		t.Errorf("layout = %v, want bars", cpu.layout)
+	}
+
+	spec.Theme = "no-such-theme"
+	if _, err := spec.Build(Params{Screen: lcd.NewSimulated(320, 480)}); err == nil {
+		t.Error("Build() with unknown theme returned no error")
+	}
+}
+
+func TestDurationJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{`"1m30s"`, 90 * time.Second, false},
+		{`15`, 15 * time.Second, false},
+		{`0.5`, 500 * time.Millisecond, false},
+		{`"soon"`, 0, true},
+		{`true`, 0, true},
+	}
+	for _, tt := range tests {
+		var d Duration
+		err := json.Unmarshal([]byte(tt.in), &d)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if time.Duration(d) != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.in, time.Duration(d), tt.want)
+		}
+	}
+}
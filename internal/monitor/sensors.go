@@ -0,0 +1,385 @@
+package monitor
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func init() {
+	Register("sensors", func() SensorsOptions { return SensorsOptions{PageInterval: Duration(5 * time.Second)} },
+		func(p Params, opts SensorsOptions) (Monitor, error) {
+			sels := append(append([]string(nil), opts.Include...), opts.Exclude...)
+			for sel := range opts.Crit {
+				sels = append(sels, sel)
+			}
+			for _, sel := range sels {
+				if _, err := path.Match(sel, ""); err != nil {
+					return nil, fmt.Errorf("sensor selector %q: %w", sel, err)
+				}
+			}
+			m := NewSensorsMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+			m.include, m.exclude, m.crit = opts.Include, opts.Exclude, opts.Crit
+			m.pageInterval = time.Duration(opts.PageInterval)
+			return m, nil
+		})
+}
+
+// SensorsOptions are the config options for the "sensors" monitor.
+// Selectors are "chip/label" globs such as "nvme*/Composite".
+type SensorsOptions struct {
+	Include      []string           `json:"include,omitempty"` // Sensors to show; empty shows all
+	Exclude      []string           `json:"exclude,omitempty"` // Sensors to hide
+	Crit         map[string]float64 `json:"crit,omitempty"`    // Limits overriding the driver's, see sensorLevel
+	PageInterval Duration           `json:"page_interval"`     // Time per page when sensors don't fit
+}
+
+// Sensor alarm levels.
+const (
+	sensorOK = iota
+	sensorWarn
+	sensorCrit
+)
+
+// sensorWarnMargin is how far below a temperature's critical limit the
+// reading is shown as a warning.
+const sensorWarnMargin = 10
+
+// SensorsMonitor displays every hwmon temperature, fan and voltage.
+type SensorsMonitor struct {
+	*Base
+	include      []string
+	exclude      []string
+	crit         map[string]float64
+	pageInterval time.Duration
+
+	rowHeight int
+	perCol    int
+	page      int
+	pageStart time.Time
+
+	// Readers, replaceable in tests
+	readSensors func() ([]sysinfo.Sensor, error)
+	now         func() time.Time
+}
+
+// NewSensorsMonitor creates a new sensors monitor.
+func NewSensorsMonitor(screen lcd.Screen, brightness int, interval time.Duration, logger *slog.Logger) *SensorsMonitor {
+	base := NewBase(Config{
+		Screen:   screen,
+		Theme:    DefaultTheme(),
+		Fonts:    DefaultFontConfig(),
+		Interval: interval,
+		Logger:   logger,
+	})
+
+	return &SensorsMonitor{
+		Base:         base,
+		pageInterval: 5 * time.Second,
+		readSensors:  sysinfo.GetSensors,
+		now:          time.Now,
+	}
+}
+
+// Name returns the monitor name.
+func (m *SensorsMonitor) Name() string { return "Sensors" }
+
+// Run starts the sensors monitor loop.
+func (m *SensorsMonitor) Run() error {
+	m.SetRunning(true)
+
+	// Calculate layout
+	m.setupLayout()
+
+	// Initial draw
+	m.ClearBuffer()
+	m.drawStatic()
+	if err := m.DrawFullBuffer(); err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	m.Logger().Info("started", "monitor", m.Name())
+
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+
+	for m.Running() {
+		select {
+		case <-ticker.C:
+			if err := m.Tick(m.update); err != nil {
+				m.Logger().Error("update failed", "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the monitor.
+func (m *SensorsMonitor) Stop() {
+	m.SetRunning(false)
+}
+
+func (m *SensorsMonitor) setupLayout() {
+	m.rowHeight = 19
+	m.perCol = (m.Height() - 40) / m.rowHeight
+}
+
+// sensorCols is the number of sensor columns.
+const sensorCols = 2
+
+// rowRegion returns the region of slot i of a page, filled column by column.
+func (m *SensorsMonitor) rowRegion(i int) Region {
+	colW := m.Width() / sensorCols
+	return Region{(i / m.perCol) * colW, 40 + (i%m.perCol)*m.rowHeight, colW, m.rowHeight}
+}
+
+func (m *SensorsMonitor) drawStatic() {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	r.DrawText(5, 8, "Sensors", m.fonts.Large, m.Colors().Header)
+
+	// Separator lines
+	r.DrawLine(0, 34, float64(m.Width()))
+}
+
+// shown filters sensors by the include and exclude selectors and applies
+// configured limits.
+func (m *SensorsMonitor) shown(sensors []sysinfo.Sensor) []sysinfo.Sensor {
+	sels := make([]string, 0, len(m.crit))
+	for sel := range m.crit {
+		sels = append(sels, sel)
+	}
+	sort.Strings(sels)
+
+	var shown []sysinfo.Sensor
+	for _, s := range sensors {
+		if len(m.include) > 0 && !matchSensor(s, m.include) {
+			continue
+		}
+		if matchSensor(s, m.exclude) {
+			continue
+		}
+		for _, sel := range sels {
+			if s.Match(sel) {
+				applyLimit(&s, m.crit[sel])
+				break
+			}
+		}
+		shown = append(shown, s)
+	}
+	return shown
+}
+
+// matchSensor reports whether a sensor matches any of the selectors.
+func matchSensor(s sysinfo.Sensor, selectors []string) bool {
+	for _, sel := range selectors {
+		if s.Match(sel) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyLimit sets a configured limit: the critical temperature, the minimum
+// fan speed or the maximum voltage.
+func applyLimit(s *sysinfo.Sensor, v float64) {
+	switch s.Kind {
+	case sysinfo.SensorTemp:
+		s.Crit = v
+	case sysinfo.SensorFan:
+		s.Min = v
+	case sysinfo.SensorVoltage:
+		s.Max = v
+	}
+}
+
+// sensorLevel returns how alarming a reading is. Temperatures warn within
+// sensorWarnMargin of their critical limit (or maximum, if the driver gives
+// no critical one); fans alarm below their minimum and voltages outside
+// their range.
+func sensorLevel(s sysinfo.Sensor) int {
+	switch s.Kind {
+	case sysinfo.SensorTemp:
+		limit := s.Crit
+		if limit == 0 {
+			limit = s.Max
+		}
+		switch {
+		case limit <= 0:
+		case s.Value >= limit:
+			return sensorCrit
+		case s.Value >= limit-sensorWarnMargin:
+			return sensorWarn
+		}
+	case sysinfo.SensorFan:
+		if s.Min > 0 && s.Value < s.Min {
+			return sensorCrit
+		}
+	case sysinfo.SensorVoltage:
+		if (s.Min > 0 && s.Value < s.Min) || (s.Max > 0 && s.Value > s.Max) {
+			return sensorCrit
+		}
+	}
+	return sensorOK
+}
+
+// formatSensor formats a reading and its limit in the sensor's unit.
+func formatSensor(s sysinfo.Sensor) (value, limit string) {
+	switch s.Kind {
+	case sysinfo.SensorFan:
+		value = fmt.Sprintf("%.0f RPM", s.Value)
+		if s.Min > 0 {
+			limit = fmt.Sprintf("min %.0f", s.Min)
+		}
+	case sysinfo.SensorVoltage:
+		value = fmt.Sprintf("%.2f V", s.Value)
+		if s.Max > 0 {
+			limit = fmt.Sprintf("max %.2f", s.Max)
+		}
+	default:
+		value = fmt.Sprintf("%.1f°C", s.Value)
+		if s.Crit > 0 {
+			limit = fmt.Sprintf("crit %.0f", s.Crit)
+		} else if s.Max > 0 {
+			limit = fmt.Sprintf("max %.0f", s.Max)
+		}
+	}
+	return value, limit
+}
+
+// sensorRow is one slot of a page: a chip heading or a sensor.
+type sensorRow struct {
+	chip   string
+	sensor *sysinfo.Sensor
+}
+
+// paginateSensors lays sensors out in pages of slots, each chip preceded by
+// a heading. A heading never ends a column, and a chip continued on a new
+// page gets its heading again.
+func paginateSensors(sensors []sysinfo.Sensor, perPage, perCol int) [][]sensorRow {
+	var pages [][]sensorRow
+	var page []sensorRow
+	chip := ""
+	for i := range sensors {
+		s := &sensors[i]
+		if len(page) == perPage {
+			pages, page = append(pages, page), nil
+		}
+		if s.Chip != chip || len(page) == 0 {
+			if len(page)%perCol == perCol-1 {
+				page = append(page, sensorRow{}) // Keep heading with its sensors
+			}
+			if len(page) >= perPage-1 {
+				pages, page = append(pages, page), nil
+			}
+			page = append(page, sensorRow{chip: s.Chip})
+			chip = s.Chip
+		}
+		page = append(page, sensorRow{sensor: s})
+	}
+	if len(page) > 0 {
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+func (m *SensorsMonitor) update() error {
+	sensors, err := m.readSensors()
+	if err != nil {
+		return err
+	}
+	pages := paginateSensors(m.shown(sensors), m.perCol*sensorCols, m.perCol)
+
+	// Rotate pages
+	if m.pageStart.IsZero() {
+		m.pageStart = m.now()
+	}
+	if m.pageInterval > 0 && m.now().Sub(m.pageStart) >= m.pageInterval {
+		m.page++
+		m.pageStart = m.now()
+	}
+	if m.page >= len(pages) {
+		m.page = 0
+	}
+
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	var updates []Region
+
+	// Page indicator
+	indicator := ""
+	if len(pages) > 1 {
+		indicator = fmt.Sprintf("%d/%d", m.page+1, len(pages))
+	}
+	if m.Changed("page", indicator) {
+		reg := Region{m.Width() - 85, 8, 80, 24}
+		r.Clear(reg)
+		r.DrawTextRight(float64(reg.X), float64(reg.Y)+2, float64(reg.W), indicator, m.fonts.Normal, m.Colors().TextDim)
+		updates = append(updates, reg)
+	}
+
+	var rows []sensorRow
+	if len(pages) > 0 {
+		rows = pages[m.page]
+	}
+	for i := 0; i < m.perCol*sensorCols; i++ {
+		reg := m.rowRegion(i)
+		var row sensorRow
+		if i < len(rows) {
+			row = rows[i]
+		}
+		key := fmt.Sprintf("row_%d", i)
+		if row.sensor == nil {
+			if m.Changed(key, row.chip) {
+				r.Clear(reg)
+				if row.chip != "" {
+					r.DrawTextFit(float64(reg.X+5), float64(reg.Y+2), float64(reg.W-10), row.chip, m.fonts.Small, m.Colors().Header, EllipsisEnd)
+				}
+				updates = append(updates, reg)
+			}
+			continue
+		}
+		s := *row.sensor
+		value, limit := formatSensor(s)
+		if m.Changed(key, fmt.Sprint(s.ID(), value, limit, sensorLevel(s))) {
+			m.renderSensorRow(r, reg, s, value, limit)
+			updates = append(updates, reg)
+		}
+	}
+
+	// Push updates to display
+	for _, reg := range updates {
+		if err := m.DrawRegion(reg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderSensorRow draws a sensor's label, reading and limit.
+func (m *SensorsMonitor) renderSensorRow(r *Renderer, reg Region, s sysinfo.Sensor, value, limit string) {
+	r.Clear(reg)
+	colors := m.Colors()
+	x, y := float64(reg.X), float64(reg.Y+2)
+
+	valueColor := colors.Text
+	switch sensorLevel(s) {
+	case sensorWarn:
+		valueColor = colors.BarMed
+	case sensorCrit:
+		valueColor = colors.BarHigh
+	}
+
+	r.DrawTextFit(x+12, y, 85, s.Label, m.fonts.Small, colors.TextDim, EllipsisEnd)
+	r.DrawTextRight(x+95, y, 72, value, m.fonts.Small, valueColor)
+	r.DrawTextRightFit(x+170, y+1, float64(reg.W-175), limit, m.fonts.Small-2, colors.TextDim, EllipsisEnd)
+}
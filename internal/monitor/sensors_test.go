@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func TestSensorLevel(t *testing.T) {
+	tests := []struct {
+		s    sysinfo.Sensor
+		want int
+	}{
+		{sysinfo.Sensor{Kind: sysinfo.SensorTemp, Value: 70}, sensorOK},
+		{sysinfo.Sensor{Kind: sysinfo.SensorTemp, Value: 70, Crit: 85}, sensorOK},
+		{sysinfo.Sensor{Kind: sysinfo.SensorTemp, Value: 76, Crit: 85}, sensorWarn},
+		{sysinfo.Sensor{Kind: sysinfo.SensorTemp, Value: 85, Crit: 85}, sensorCrit},
+		{sysinfo.Sensor{Kind: sysinfo.SensorTemp, Value: 81, Max: 80}, sensorCrit},
+		{sysinfo.Sensor{Kind: sysinfo.SensorFan, Value: 0, Min: 300}, sensorCrit},
+		{sysinfo.Sensor{Kind: sysinfo.SensorFan, Value: 0}, sensorOK},
+		{sysinfo.Sensor{Kind: sysinfo.SensorVoltage, Value: 1.5, Max: 1.4}, sensorCrit},
+		{sysinfo.Sensor{Kind: sysinfo.SensorVoltage, Value: 11.2, Min: 11.4, Max: 12.6}, sensorCrit},
+	}
+	for _, tt := range tests {
+		if got := sensorLevel(tt.s); got != tt.want {
+			t.Errorf("sensorLevel(%+v) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestPaginateSensors(t *testing.T) {
+	var sensors []sysinfo.Sensor
+	for _, chip := range []struct {
+		name string
+		n    int
+	}{{"k10temp", 2}, {"nvme", 1}, {"nct6798", 4}} {
+		for i := 1; i <= chip.n; i++ {
+			sensors = append(sensors, sysinfo.Sensor{Chip: chip.name, Label: fmt.Sprint(i)})
+		}
+	}
+
+	// Two columns of three slots per page
+	pages := paginateSensors(sensors, 6, 3)
+	var got []string
+	for _, page := range pages {
+		var slots []string
+		for _, row := range page {
+			switch {
+			case row.sensor != nil:
+				slots = append(slots, row.sensor.Label)
+			case row.chip != "":
+				slots = append(slots, row.chip)
+			default:
+				slots = append(slots, "-")
+			}
+		}
+		got = append(got, strings.Join(slots, " "))
+	}
+	want := []string{
+		"k10temp 1 2 nvme 1 -", // nct6798 heading would end the column
+		"nct6798 1 2 3 4",
+	}
+	if strings.Join(got, " | ") != strings.Join(want, " | ") {
+		t.Errorf("pages = %q, want %q", got, want)
+	}
+
+	// A chip split across pages repeats its heading
+	pages = paginateSensors(sensors[3:], 4, 2)
+	if len(pages) != 2 || pages[1][0].chip != "nct6798" {
+		t.Errorf("continued page starts with %+v, want nct6798 heading", pages[len(pages)-1][0])
+	}
+}
@@ -0,0 +1,328 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+	"github.com/aleksclark/go-turing-smart-screen/pkg/agentstat"
+)
+
+// Value is a sampled data source value: a number, or text when the source
+// produced something that doesn't parse as one.
+type Value struct {
+	Num   float64
+	Str   string
+	IsNum bool
+}
+
+// NumValue returns a numeric value.
+func NumValue(v float64) Value {
+	return Value{Num: v, Str: strconv.FormatFloat(v, 'f', -1, 64), IsNum: true}
+}
+
+// TextValue returns a value for text, numeric if the text is a number.
+func TextValue(s string) Value {
+	s = strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return Value{Num: f, Str: s, IsNum: true}
+	}
+	return Value{Str: s}
+}
+
+// Format formats the value. format is a fmt verb string such as "%.1f%%",
+// or "bytes" for a human-readable byte count. An empty format prints the
+// value as is.
+func (v Value) Format(format string) string {
+	switch {
+	case format == "":
+		return v.Str
+	case format == "bytes" && v.IsNum:
+		return sysinfo.FormatBytes(uint64(v.Num))
+	case v.IsNum && !strings.Contains(format, "%s") && !strings.Contains(format, "%v"):
+		if strings.Contains(format, "%d") {
+			return fmt.Sprintf(format, int64(v.Num))
+		}
+		return fmt.Sprintf(format, v.Num)
+	}
+	return fmt.Sprintf(format, v.Str)
+}
+
+// SourceRef is a parsed data source expression such as cpu.overall,
+// agents.count{status=waiting}, file:/path or cmd:command.
+type SourceRef struct {
+	Name   string
+	Labels map[string]string
+}
+
+// String returns the expression the reference was parsed from.
+func (s SourceRef) String() string {
+	switch s.Name {
+	case "file":
+		return "file:" + s.Labels["path"]
+	case "cmd":
+		return "cmd:" + s.Labels["run"]
+	}
+	if len(s.Labels) == 0 {
+		return s.Name
+	}
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + s.Labels[k]
+	}
+	return s.Name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sourceFunc reads one source from a sample.
+type sourceFunc func(s *Sampler, labels map[string]string) (Value, error)
+
+// sources lists the named data sources and the labels each accepts.
+var sources = map[string]struct {
+	labels []string
+	read   sourceFunc
+}{
+	"cpu.overall": {nil, cpuSource(func(c *sysinfo.CPUInfo) float64 { return c.Overall })},
+	"cpu.freq":    {nil, cpuSource(func(c *sysinfo.CPUInfo) float64 { return c.Freq })},
+	"cpu.temp":    {nil, cpuSource(func(c *sysinfo.CPUInfo) float64 { return c.Temp })},
+	"cpu.load1":   {nil, cpuSource(func(c *sysinfo.CPUInfo) float64 { return c.Load1 })},
+	"cpu.load5":   {nil, cpuSource(func(c *sysinfo.CPUInfo) float64 { return c.Load5 })},
+	"cpu.load15":  {nil, cpuSource(func(c *sysinfo.CPUInfo) float64 { return c.Load15 })},
+	"cpu.cores":   {nil, cpuSource(func(c *sysinfo.CPUInfo) float64 { return float64(c.CoreCount) })},
+	"cpu.core":    {[]string{"n"}, readCPUCore},
+
+	"mem.used_percent":  {nil, memSource(func(m *sysinfo.MemInfo) float64 { return m.UsedPercent })},
+	"mem.used":          {nil, memSource(func(m *sysinfo.MemInfo) float64 { return float64(m.Used) })},
+	"mem.total":         {nil, memSource(func(m *sysinfo.MemInfo) float64 { return float64(m.Total) })},
+	"mem.available":     {nil, memSource(func(m *sysinfo.MemInfo) float64 { return float64(m.Available) })},
+	"swap.used_percent": {nil, memSource(func(m *sysinfo.MemInfo) float64 { return m.SwapPercent })},
+	"swap.used":         {nil, memSource(func(m *sysinfo.MemInfo) float64 { return float64(m.SwapUsed) })},
+	"swap.total":        {nil, memSource(func(m *sysinfo.MemInfo) float64 { return float64(m.SwapTotal) })},
+
+	"agents.count": {[]string{"status", "agent", "stale"}, readAgentCount},
+
+	"file": {[]string{"path"}, readFileSource},
+	"cmd":  {[]string{"run"}, readCmdSource},
+}
+
+// SourceNames returns the names of the built-in data sources.
+func SourceNames() []string {
+	return sortedKeys(sources)
+}
+
+// ParseSource parses a data source expression and checks that the source
+// and its labels exist.
+func ParseSource(expr string) (SourceRef, error) {
+	expr = strings.TrimSpace(expr)
+	if path, ok := strings.CutPrefix(expr, "file:"); ok {
+		return SourceRef{Name: "file", Labels: map[string]string{"path": path}}, nil
+	}
+	if run, ok := strings.CutPrefix(expr, "cmd:"); ok {
+		return SourceRef{Name: "cmd", Labels: map[string]string{"run": run}}, nil
+	}
+
+	ref := SourceRef{Name: expr}
+	if open := strings.IndexByte(expr, '{'); open >= 0 {
+		if !strings.HasSuffix(expr, "}") {
+			return SourceRef{}, fmt.Errorf("source %q: missing closing brace", expr)
+		}
+		ref.Name = expr[:open]
+		ref.Labels = make(map[string]string)
+		for _, pair := range strings.Split(expr[open+1:len(expr)-1], ",") {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return SourceRef{}, fmt.Errorf("source %q: label %q is not key=value", expr, pair)
+			}
+			ref.Labels[strings.TrimSpace(k)] = strings.Trim(strings.TrimSpace(v), `"`)
+		}
+	}
+
+	src, ok := sources[ref.Name]
+	if !ok {
+		return SourceRef{}, fmt.Errorf("unknown source %q", ref.Name)
+	}
+	for k := range ref.Labels {
+		if !contains(src.labels, k) {
+			return SourceRef{}, fmt.Errorf("source %q: unknown label %q", ref.Name, k)
+		}
+	}
+	return ref, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Sampler reads data sources for one refresh, fetching each underlying
+// system reading at most once however many widgets bind to it.
+type Sampler struct {
+	// Readers, replaceable in tests
+	ReadCPU    func() (*sysinfo.CPUInfo, error)
+	ReadMem    func() (*sysinfo.MemInfo, error)
+	ReadAgents func() ([]agentstat.Status, error)
+
+	// CmdTimeout limits cmd: sources, default 5s.
+	CmdTimeout time.Duration
+
+	cpu    *sysinfo.CPUInfo
+	mem    *sysinfo.MemInfo
+	agents []agentstat.Status
+	values map[string]Value
+	errs   map[string]error
+}
+
+// NewSampler creates a sampler reading the live system.
+func NewSampler() *Sampler {
+	return &Sampler{
+		ReadCPU: sysinfo.GetCPUInfo,
+		ReadMem: sysinfo.GetMemInfo,
+		ReadAgents: func() ([]agentstat.Status, error) {
+			return agentstat.ReadAll(agentstat.StaleThreshold)
+		},
+		CmdTimeout: 5 * time.Second,
+	}
+}
+
+// Reset discards the previous refresh's readings.
+func (s *Sampler) Reset() {
+	s.cpu, s.mem, s.agents = nil, nil, nil
+	s.values = make(map[string]Value)
+	s.errs = make(map[string]error)
+}
+
+// Get returns the current value of a source.
+func (s *Sampler) Get(ref SourceRef) (Value, error) {
+	if s.values == nil {
+		s.Reset()
+	}
+	key := ref.String()
+	if v, ok := s.values[key]; ok {
+		return v, nil
+	}
+	if err, ok := s.errs[key]; ok {
+		return Value{}, err
+	}
+	v, err := sources[ref.Name].read(s, ref.Labels)
+	if err != nil {
+		s.errs[key] = err
+		return Value{}, err
+	}
+	s.values[key] = v
+	return v, nil
+}
+
+func (s *Sampler) cpuInfo() (*sysinfo.CPUInfo, error) {
+	if s.cpu == nil {
+		info, err := s.ReadCPU()
+		if err != nil {
+			return nil, err
+		}
+		s.cpu = info
+	}
+	return s.cpu, nil
+}
+
+func (s *Sampler) memInfo() (*sysinfo.MemInfo, error) {
+	if s.mem == nil {
+		info, err := s.ReadMem()
+		if err != nil {
+			return nil, err
+		}
+		s.mem = info
+	}
+	return s.mem, nil
+}
+
+func cpuSource(field func(*sysinfo.CPUInfo) float64) sourceFunc {
+	return func(s *Sampler, _ map[string]string) (Value, error) {
+		info, err := s.cpuInfo()
+		if err != nil {
+			return Value{}, err
+		}
+		return NumValue(field(info)), nil
+	}
+}
+
+func memSource(field func(*sysinfo.MemInfo) float64) sourceFunc {
+	return func(s *Sampler, _ map[string]string) (Value, error) {
+		info, err := s.memInfo()
+		if err != nil {
+			return Value{}, err
+		}
+		return NumValue(field(info)), nil
+	}
+}
+
+func readCPUCore(s *Sampler, labels map[string]string) (Value, error) {
+	n, err := strconv.Atoi(labels["n"])
+	if err != nil {
+		return Value{}, fmt.Errorf("cpu.core: label n must be a CPU number")
+	}
+	info, err := s.cpuInfo()
+	if err != nil {
+		return Value{}, err
+	}
+	if n < 0 || n >= len(info.PerCPU) {
+		return Value{}, fmt.Errorf("cpu.core: no CPU %d", n)
+	}
+	return NumValue(info.PerCPU[n]), nil
+}
+
+func readAgentCount(s *Sampler, labels map[string]string) (Value, error) {
+	if s.agents == nil {
+		agents, err := s.ReadAgents()
+		if err != nil {
+			return Value{}, err
+		}
+		s.agents = agents
+	}
+	n := 0
+	for _, a := range s.agents {
+		if st, ok := labels["status"]; ok && a.Status != st {
+			continue
+		}
+		if name, ok := labels["agent"]; ok && a.Agent != name {
+			continue
+		}
+		if stale, ok := labels["stale"]; ok && strconv.FormatBool(a.Stale) != stale {
+			continue
+		}
+		n++
+	}
+	return NumValue(float64(n)), nil
+}
+
+func readFileSource(_ *Sampler, labels map[string]string) (Value, error) {
+	data, err := os.ReadFile(labels["path"])
+	if err != nil {
+		return Value{}, err
+	}
+	return TextValue(string(data)), nil
+}
+
+func readCmdSource(s *Sampler, labels map[string]string) (Value, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.CmdTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", labels["run"])
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	if err != nil {
+		return Value{}, fmt.Errorf("cmd %q: %w", labels["run"], err)
+	}
+	return TextValue(string(out)), nil
+}
@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+	"github.com/aleksclark/go-turing-smart-screen/pkg/agentstat"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{"cpu.overall", "cpu.overall", ""},
+		{" agents.count{status=waiting} ", "agents.count{status=waiting}", ""},
+		{`agents.count{stale=false, agent="claude"}`, "agents.count{agent=claude,stale=false}", ""},
+		{"file:/sys/class/net/eth0/speed", "file:/sys/class/net/eth0/speed", ""},
+		{"cmd:echo 4", "cmd:echo 4", ""},
+		{"cpu.overal", "", `unknown source "cpu.overal"`},
+		{"agents.count{host=a}", "", `unknown label "host"`},
+		{"agents.count{status}", "", "not key=value"},
+		{"agents.count{status=idle", "", "missing closing brace"},
+	}
+	for _, tt := range tests {
+		ref, err := ParseSource(tt.in)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseSource(%q) error = %v, want containing %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSource(%q) error = %v", tt.in, err)
+			continue
+		}
+		if ref.String() != tt.want {
+			t.Errorf("ParseSource(%q) = %q, want %q", tt.in, ref.String(), tt.want)
+		}
+	}
+}
+
+func TestValueFormat(t *testing.T) {
+	tests := []struct {
+		v      Value
+		format string
+		want   string
+	}{
+		{NumValue(42.5), "", "42.5"},
+		{NumValue(42.5), "%.0f%%", "42%"},
+		{NumValue(3), "%d agents", "3 agents"},
+		{NumValue(2 * 1024 * 1024 * 1024), "bytes", "2.0G"},
+		{TextValue(" 12\n"), "%s jobs", "12 jobs"},
+		{TextValue("ok"), "%d", "%!d(string=ok)"},
+	}
+	for _, tt := range tests {
+		if got := tt.v.Format(tt.format); got != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestSamplerReadsOncePerRefresh(t *testing.T) {
+	cpuReads := 0
+	s := NewSampler()
+	s.ReadCPU = func() (*sysinfo.CPUInfo, error) {
+		cpuReads++
+		return &sysinfo.CPUInfo{Overall: 37, PerCPU: []float64{10, 64}}, nil
+	}
+	s.ReadAgents = func() ([]agentstat.Status, error) {
+		return []agentstat.Status{
+			{Agent: "a", Status: "waiting"},
+			{Agent: "b", Status: "working"},
+			{Agent: "c", Status: "waiting", Stale: true},
+		}, nil
+	}
+
+	path := filepath.Join(t.TempDir(), "depth")
+	if err := os.WriteFile(path, []byte("17\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]float64{
+		"cpu.overall":                  37,
+		"cpu.core{n=1}":                64,
+		"agents.count":                 3,
+		"agents.count{status=waiting}": 2,
+		"agents.count{status=waiting,stale=false}": 1,
+		"file:" + path: 17,
+	}
+	s.Reset()
+	for expr, n := range want {
+		ref, err := ParseSource(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := s.Get(ref)
+		if err != nil {
+			t.Errorf("Get(%s) error = %v", expr, err)
+			continue
+		}
+		if !v.IsNum || v.Num != n {
+			t.Errorf("Get(%s) = %+v, want %v", expr, v, n)
+		}
+	}
+	if cpuReads != 1 {
+		t.Errorf("CPU read %d times in one refresh, want 1", cpuReads)
+	}
+
+	ref, _ := ParseSource("cpu.core{n=9}")
+	if _, err := s.Get(ref); err == nil {
+		t.Error("Get() of missing core returned no error")
+	}
+}
@@ -0,0 +1,463 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func init() {
+	Register("systemd", func() SystemdOptions { return SystemdOptions{} },
+		func(p Params, opts SystemdOptions) (Monitor, error) {
+			for _, globs := range [][]string{opts.Watch, opts.UserUnits, opts.Ignore} {
+				for _, g := range globs {
+					if _, err := path.Match(g, ""); err != nil {
+						return nil, fmt.Errorf("pattern %q: %w", g, err)
+					}
+				}
+			}
+			m := NewSystemdMonitor(p.Screen, p.Brightness, p.Interval, p.Logger)
+			m.watch, m.userUnits, m.ignore = opts.Watch, opts.UserUnits, opts.Ignore
+			return m, nil
+		})
+}
+
+// SystemdOptions are the config options for the "systemd" monitor.
+type SystemdOptions struct {
+	Watch     []string `json:"watch,omitempty"`      // System unit names or globs always shown, with restarts
+	UserUnits []string `json:"user_units,omitempty"` // Units of the user's manager shown, names or globs
+	Ignore    []string `json:"ignore,omitempty"`     // Unit globs whose failures aren't shown
+}
+
+const (
+	unitRowHeight  = 28
+	systemdTimeout = 5 * time.Second // Limit on one round of queries
+	recentRestart  = time.Hour       // Time a restart is shown as recent
+)
+
+// unitKey identifies a unit of the system or the user manager.
+type unitKey struct {
+	user bool
+	name string
+}
+
+// unitRow is a unit shown on screen.
+type unitRow struct {
+	sysinfo.UnitStatus
+	user        bool
+	restarts    int
+	lastRestart time.Time // When the restart count last rose, zero if not seen
+}
+
+// SystemdMonitor displays failed and starting systemd units, and the state
+// and restarts of watched system and user units.
+type SystemdMonitor struct {
+	*Base
+	watch     []string
+	userUnits []string
+	ignore    []string
+
+	backends map[bool]sysinfo.SystemdBackend // System and user managers, opened on first use
+	restarts map[unitKey]unitRow             // Restart counts of watched units
+	numRows  int
+
+	ctx    context.Context // Cancelled by Stop to abort queries
+	cancel context.CancelFunc
+
+	// Readers, replaceable in tests
+	openBackend func(user bool) (sysinfo.SystemdBackend, error)
+	now         func() time.Time
+}
+
+// NewSystemdMonitor creates a new systemd monitor.
+func NewSystemdMonitor(screen lcd.Screen, brightness int, interval time.Duration, logger *slog.Logger) *SystemdMonitor {
+	base := NewBase(Config{
+		Screen:   screen,
+		Theme:    DefaultTheme(),
+		Fonts:    DefaultFontConfig(),
+		Interval: interval,
+		Logger:   logger,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SystemdMonitor{
+		Base:        base,
+		backends:    make(map[bool]sysinfo.SystemdBackend),
+		restarts:    make(map[unitKey]unitRow),
+		ctx:         ctx,
+		cancel:      cancel,
+		openBackend: sysinfo.OpenSystemd,
+		now:         time.Now,
+	}
+}
+
+// Name returns the monitor name.
+func (m *SystemdMonitor) Name() string { return "Systemd" }
+
+// Run starts the systemd monitor loop.
+func (m *SystemdMonitor) Run() error {
+	m.SetRunning(true)
+	defer m.closeBackends()
+
+	// Calculate layout
+	m.setupLayout()
+
+	// Initial draw
+	m.ClearBuffer()
+	m.drawStatic()
+	if err := m.DrawFullBuffer(); err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	m.Logger().Info("started", "monitor", m.Name())
+
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+
+	for m.Running() {
+		select {
+		case <-ticker.C:
+			if err := m.Tick(m.update); err != nil {
+				m.Logger().Error("update failed", "error", err)
+			}
+		case <-m.ctx.Done():
+			// Stopped, possibly before Run set running
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the monitor and aborts queries in flight.
+func (m *SystemdMonitor) Stop() {
+	m.SetRunning(false)
+	m.cancel()
+}
+
+// closeBackends closes the connections to the managers.
+func (m *SystemdMonitor) closeBackends() {
+	for user, b := range m.backends {
+		b.Close()
+		delete(m.backends, user)
+	}
+}
+
+func (m *SystemdMonitor) setupLayout() {
+	m.numRows = (m.statusRegion().Y - 40) / unitRowHeight
+}
+
+// rowRegion returns the region of row i.
+func (m *SystemdMonitor) rowRegion(i int) Region {
+	return Region{0, 40 + i*unitRowHeight, m.Width(), unitRowHeight - 2}
+}
+
+// statusRegion returns the line at the bottom where query errors are shown.
+func (m *SystemdMonitor) statusRegion() Region {
+	return Region{5, m.Height() - 20, m.Width() - 10, 18}
+}
+
+func (m *SystemdMonitor) drawStatic() {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	r.DrawText(5, 8, "Systemd", m.fonts.Large, m.Colors().Header)
+	r.DrawLine(0, 34, float64(m.Width()))
+}
+
+// units queries a manager's units. A backend that fails is closed, to be
+// reopened on the next update.
+func (m *SystemdMonitor) units(ctx context.Context, user bool) ([]sysinfo.UnitStatus, error) {
+	b, ok := m.backends[user]
+	if !ok {
+		var err error
+		if b, err = m.openBackend(user); err != nil {
+			return nil, err
+		}
+		m.backends[user] = b
+	}
+	units, err := b.Units(ctx)
+	if err != nil {
+		b.Close()
+		delete(m.backends, user)
+		return nil, err
+	}
+	return units, nil
+}
+
+// watched returns the units matching patterns, in the order listed. Names
+// without glob characters that the manager hasn't loaded are inactive.
+func watched(units []sysinfo.UnitStatus, patterns []string) []sysinfo.UnitStatus {
+	var matched []sysinfo.UnitStatus
+	found := make(map[string]bool)
+	for _, u := range units {
+		if matchAny(patterns, u.Name) {
+			matched = append(matched, u)
+			found[u.Name] = true
+		}
+	}
+	for _, p := range patterns {
+		if !strings.ContainsAny(p, "*?[") && !found[p] {
+			matched = append(matched, sysinfo.UnitStatus{Name: p, LoadState: "not-found", ActiveState: "inactive", SubState: "dead"})
+		}
+	}
+	return matched
+}
+
+// problemUnits returns the failed units and then the starting ones, less
+// those ignored.
+func (m *SystemdMonitor) problemUnits(units []sysinfo.UnitStatus) []sysinfo.UnitStatus {
+	var failed, starting []sysinfo.UnitStatus
+	for _, u := range units {
+		if matchAny(m.ignore, u.Name) {
+			continue
+		}
+		switch u.ActiveState {
+		case "failed":
+			failed = append(failed, u)
+		case "activating":
+			starting = append(starting, u)
+		}
+	}
+	return append(failed, starting...)
+}
+
+// trackRestarts reads a watched unit's restart count and notes when it
+// rose.
+func (m *SystemdMonitor) trackRestarts(ctx context.Context, row *unitRow) {
+	b, ok := m.backends[row.user]
+	if !ok {
+		return
+	}
+	n, err := b.Restarts(ctx, row.Name)
+	if err != nil {
+		m.Logger().Debug("restart count unavailable", "unit", row.Name, "error", err)
+		return
+	}
+	key := unitKey{row.user, row.Name}
+	prev, seen := m.restarts[key]
+	row.restarts, row.lastRestart = n, prev.lastRestart
+	if seen && n > prev.restarts {
+		row.lastRestart = m.now()
+	}
+	m.restarts[key] = *row
+}
+
+// rows queries the managers and returns the units to show, problems first,
+// and the errors of managers that couldn't be queried.
+func (m *SystemdMonitor) rows() ([]unitRow, []string) {
+	ctx, cancel := context.WithTimeout(m.ctx, systemdTimeout)
+	defer cancel()
+
+	var rows []unitRow
+	var errs []string
+	shown := make(map[unitKey]bool)
+	add := func(u sysinfo.UnitStatus, user, track bool) {
+		key := unitKey{user, u.Name}
+		if shown[key] {
+			return
+		}
+		shown[key] = true
+		row := unitRow{UnitStatus: u, user: user}
+		if track {
+			m.trackRestarts(ctx, &row)
+		}
+		rows = append(rows, row)
+	}
+
+	system, sysErr := m.units(ctx, false)
+	if sysErr != nil {
+		errs = append(errs, "system: "+sysErr.Error())
+	}
+	var user []sysinfo.UnitStatus
+	if len(m.userUnits) > 0 {
+		var err error
+		if user, err = m.units(ctx, true); err != nil {
+			errs = append(errs, "user: "+err.Error())
+		}
+	}
+
+	for _, u := range m.problemUnits(system) {
+		add(u, false, matchAny(m.watch, u.Name))
+	}
+	for _, u := range watched(user, m.userUnits) {
+		if u.ActiveState == "failed" || u.ActiveState == "activating" {
+			add(u, true, true)
+		}
+	}
+	// Watched units aren't known to be inactive if the manager didn't answer
+	if sysErr == nil {
+		for _, u := range watched(system, m.watch) {
+			add(u, false, true)
+		}
+	}
+	if len(user) > 0 {
+		for _, u := range watched(user, m.userUnits) {
+			add(u, true, true)
+		}
+	}
+	return rows, errs
+}
+
+func (m *SystemdMonitor) update() error {
+	rows, errs := m.rows()
+	if m.ctx.Err() != nil {
+		return nil
+	}
+
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	var updates []Region
+
+	// Summary of failed and starting units
+	failed, starting := 0, 0
+	for _, u := range rows {
+		switch u.ActiveState {
+		case "failed":
+			failed++
+		case "activating":
+			starting++
+		}
+	}
+	var parts []string
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", failed))
+	}
+	if starting > 0 {
+		parts = append(parts, fmt.Sprintf("%d starting", starting))
+	}
+	summary := strings.Join(parts, " · ")
+	if summary == "" && len(errs) == 0 {
+		summary = "all OK"
+	}
+	if m.Changed("summary", summary) {
+		reg := Region{150, 8, m.Width() - 155, 24}
+		r.Clear(reg)
+		c := m.Colors().BarLow
+		switch {
+		case failed > 0:
+			c = m.Colors().BarHigh
+		case starting > 0:
+			c = m.Colors().BarMed
+		}
+		r.DrawTextRightFit(float64(reg.X), float64(reg.Y)+2, float64(reg.W), summary, m.fonts.Normal, c, EllipsisEnd)
+		updates = append(updates, reg)
+	}
+
+	// Unit rows
+	for i := 0; i < m.numRows; i++ {
+		reg := m.rowRegion(i)
+		key := fmt.Sprintf("unit_%d", i)
+		if i >= len(rows) {
+			if m.Changed(key, "") {
+				r.Clear(reg)
+				updates = append(updates, reg)
+			}
+			continue
+		}
+		u := rows[i]
+		if m.Changed(key, fmt.Sprint(u.user, u.Name, u.ActiveState, u.SubState, m.restartText(u))) {
+			m.renderRow(r, reg, u)
+			updates = append(updates, reg)
+		}
+	}
+
+	// Managers that couldn't be queried
+	status := strings.Join(errs, "; ")
+	if m.Changed("status", status) {
+		reg := m.statusRegion()
+		r.Clear(reg)
+		r.DrawTextFit(float64(reg.X), float64(reg.Y), float64(reg.W), status, m.fonts.Small, m.Colors().BarHigh, EllipsisEnd)
+		updates = append(updates, reg)
+	}
+
+	// Push updates to display
+	for _, reg := range updates {
+		if err := m.DrawRegion(reg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restartedRecently reports whether a unit's restart was seen within the
+// last recentRestart.
+func (m *SystemdMonitor) restartedRecently(u unitRow) bool {
+	return !u.lastRestart.IsZero() && m.now().Sub(u.lastRestart) < recentRestart
+}
+
+// restartText describes a unit's restarts: how long ago the last one was
+// if it was seen recently, otherwise the count.
+func (m *SystemdMonitor) restartText(u unitRow) string {
+	if m.restartedRecently(u) {
+		return "restarted " + formatAgo(m.now().Sub(u.lastRestart)) + " ago"
+	}
+	switch u.restarts {
+	case 0:
+		return ""
+	case 1:
+		return "1 restart"
+	}
+	return fmt.Sprintf("%d restarts", u.restarts)
+}
+
+// formatAgo formats a time span coarsely, as in "40s", "5m" or "3h".
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh", int(d.Hours()))
+}
+
+// renderRow draws a unit's name in the color of its state, the state, and
+// its restarts.
+func (m *SystemdMonitor) renderRow(r *Renderer, reg Region, u unitRow) {
+	r.Clear(reg)
+	colors := m.Colors()
+	y := float64(reg.Y)
+
+	nameColor := colors.Text
+	switch u.ActiveState {
+	case "failed":
+		nameColor = colors.BarHigh
+	case "activating", "deactivating", "reloading":
+		nameColor = colors.BarMed
+	case "inactive":
+		nameColor = colors.TextDim
+	}
+	name := strings.TrimSuffix(u.Name, ".service")
+	if u.user {
+		name = "user/" + name
+	}
+	r.DrawTextFit(5, y+2, 185, name, m.fonts.Normal, nameColor, EllipsisMiddle)
+	r.DrawTextFit(195, y+4, 150, unitState(u.UnitStatus), m.fonts.Small-2, colors.TextDim, EllipsisEnd)
+
+	c := colors.TextDim
+	if m.restartedRecently(u) {
+		c = colors.BarMed
+	}
+	r.DrawTextRightFit(350, y+4, float64(reg.W-355), m.restartText(u), m.fonts.Small-2, c, EllipsisEnd)
+}
+
+// unitState describes a unit's state, e.g. "active (running)". Services
+// waiting to be restarted after failing are just "auto-restart".
+func unitState(u sysinfo.UnitStatus) string {
+	switch {
+	case u.SubState == "" || u.SubState == u.ActiveState:
+		return u.ActiveState
+	case u.SubState == "auto-restart":
+		return u.SubState
+	}
+	return u.ActiveState + " (" + u.SubState + ")"
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+// fakeSystemd is a SystemdBackend with canned units.
+type fakeSystemd struct {
+	units    []sysinfo.UnitStatus
+	restarts map[string]int
+	err      error
+	closed   bool
+}
+
+func (f *fakeSystemd) Units(context.Context) ([]sysinfo.UnitStatus, error) { return f.units, f.err }
+
+func (f *fakeSystemd) Restarts(_ context.Context, unit string) (int, error) {
+	return f.restarts[unit], nil
+}
+
+func (f *fakeSystemd) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestSystemdMonitorRows(t *testing.T) {
+	system := &fakeSystemd{
+		units: []sysinfo.UnitStatus{
+			{Name: "nginx.service", ActiveState: "active", SubState: "running"},
+			{Name: "backup.service", ActiveState: "activating", SubState: "start"},
+			{Name: "fwupd-refresh.service", ActiveState: "failed", SubState: "failed"},
+			{Name: "postgresql@16-main.service", ActiveState: "failed", SubState: "failed"},
+			{Name: "ssh.socket", ActiveState: "active", SubState: "listening"},
+		},
+		restarts: map[string]int{"nginx.service": 2},
+	}
+	user := &fakeSystemd{units: []sysinfo.UnitStatus{
+		{Name: "syncthing.service", ActiveState: "active", SubState: "running"},
+	}}
+	m := NewSystemdMonitor(newRecordingScreen(320, 480), 50, time.Second, nil)
+	now := time.Unix(1000, 0)
+	m.now = func() time.Time { return now }
+	m.openBackend = func(u bool) (sysinfo.SystemdBackend, error) {
+		if u {
+			return user, nil
+		}
+		return system, nil
+	}
+	m.watch = []string{"nginx.service", "postgresql@*", "caddy.service"}
+	m.userUnits = []string{"syncthing.service"}
+	m.ignore = []string{"fwupd-*"}
+
+	names := func(rows []unitRow) []string {
+		var got []string
+		for _, u := range rows {
+			got = append(got, u.Name+" "+u.ActiveState+" "+m.restartText(u))
+		}
+		return got
+	}
+
+	rows, errs := m.rows()
+	want := []string{
+		"postgresql@16-main.service failed ",
+		"backup.service activating ",
+		"nginx.service active 2 restarts",
+		"caddy.service inactive ",
+		"syncthing.service active ",
+	}
+	if !reflect.DeepEqual(names(rows), want) || len(errs) != 0 {
+		t.Errorf("rows =\n%q\nwant\n%q\nerrors %v", names(rows), want, errs)
+	}
+
+	// A restart since the previous update is shown as recent
+	system.restarts["nginx.service"] = 3
+	now = now.Add(5 * time.Minute)
+	rows, _ = m.rows()
+	now = now.Add(3 * time.Minute)
+	if got := m.restartText(rows[2]); got != "restarted 3m ago" {
+		t.Errorf("restart text = %q, want %q", got, "restarted 3m ago")
+	}
+
+	// A failing manager is closed and reported, and reopened next time
+	user.err = errors.New("connection closed")
+	rows, errs = m.rows()
+	if len(errs) != 1 || errs[0] != "user: connection closed" || !user.closed {
+		t.Errorf("errors = %v, closed %v", errs, user.closed)
+	}
+	if len(rows) != 4 {
+		t.Errorf("got %d rows with the user manager down, want 4", len(rows))
+	}
+	user.err = nil
+	if rows, errs = m.rows(); len(rows) != 5 || len(errs) != 0 {
+		t.Errorf("got %d rows and errors %v after the user manager came back", len(rows), errs)
+	}
+}
+
+func TestSystemdMonitorStopBeforeRun(t *testing.T) {
+	m := NewSystemdMonitor(newRecordingScreen(320, 480), 50, time.Hour, nil)
+	m.Stop()
+	done := make(chan error)
+	go func() { done <- m.Run() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() after Stop() did not return")
+	}
+}
@@ -0,0 +1,495 @@
+package monitor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+)
+
+func init() {
+	Register("template", func() TemplateOptions { return TemplateOptions{} },
+		func(p Params, opts TemplateOptions) (Monitor, error) {
+			if opts.Path == "" {
+				return nil, errors.New("path: a layout file is required")
+			}
+			return NewTemplateMonitor(p.Screen, p.Brightness, p.Interval, p.Logger, opts.Path)
+		})
+}
+
+// TemplateOptions are the config options for the "template" monitor.
+type TemplateOptions struct {
+	Path string `json:"path"` // YAML layout file, reloaded when it changes
+}
+
+// Template is a screen layout read from a YAML file:
+//
+//	theme: amber
+//	widgets:
+//	  - {type: text, x: 5, y: 8, w: 200, h: 24, text: Overview, size: large, color: header}
+//	  - {type: value, x: 5, y: 40, w: 120, h: 20, source: cpu.overall, format: "CPU %.0f%%",
+//	     thresholds: [{from: 50, color: bar_med}, {from: 80, color: bar_high}]}
+//	  - {type: bar, x: 130, y: 40, w: 180, h: 20, source: mem.used_percent, fill: gradient}
+//	  - {type: graph, x: 5, y: 70, w: 310, h: 40, source: cpu.overall, kind: area}
+type Template struct {
+	Theme   string           `yaml:"theme"` // Built-in name or theme file path, relative to the layout
+	Widgets []TemplateWidget `yaml:"widgets"`
+}
+
+// TemplateWidget places one widget. Which fields apply depends on Type:
+//
+//	text   static Text
+//	value  Source formatted with Format
+//	bar    Source between Min and Max
+//	graph  history of Source between Min and Max
+//	line   horizontal separator at Y
+type TemplateWidget struct {
+	Type       string              `yaml:"type"`
+	X          int                 `yaml:"x"`
+	Y          int                 `yaml:"y"`
+	W          int                 `yaml:"w"`
+	H          int                 `yaml:"h"`
+	Text       string              `yaml:"text"`
+	Source     string              `yaml:"source"`
+	Format     string              `yaml:"format"` // fmt verbs, or "bytes"
+	Size       string              `yaml:"size"`   // small, normal, large or pixels
+	Align      string              `yaml:"align"`  // left, center or right
+	Color      string              `yaml:"color"`  // Palette key, agent status or hex
+	Thresholds []TemplateThreshold `yaml:"thresholds"`
+	Min        float64             `yaml:"min"`
+	Max        float64             `yaml:"max"`    // Default 100
+	Fill       string              `yaml:"fill"`   // bar: solid, gradient or segmented
+	Border     *bool               `yaml:"border"` // bar: default true
+	Kind       string              `yaml:"kind"`   // graph: line, area or heat
+}
+
+// TemplateThreshold colors a value or bar from a level upwards.
+type TemplateThreshold struct {
+	From  float64 `yaml:"from"`
+	Color string  `yaml:"color"`
+}
+
+// tmplWidget is a validated template widget.
+type tmplWidget struct {
+	TemplateWidget
+	reg  Region
+	ref  SourceRef
+	fill BarFill
+	kind GraphKind
+}
+
+var (
+	barFills   = map[string]BarFill{"": FillSolid, "solid": FillSolid, "gradient": FillGradient, "segmented": FillSegmented}
+	graphKinds = map[string]GraphKind{"": GraphLine, "line": GraphLine, "area": GraphArea, "heat": GraphHeat}
+)
+
+// ParseTemplate decodes a YAML layout. Unknown keys are errors.
+func ParseTemplate(data []byte) (*Template, error) {
+	var t Template
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// compile validates a template's widgets against a screen size.
+func (t *Template) compile(width, height int) ([]tmplWidget, error) {
+	theme := DefaultTheme()
+	widgets := make([]tmplWidget, len(t.Widgets))
+	for i, w := range t.Widgets {
+		cw := tmplWidget{TemplateWidget: w, reg: Region{w.X, w.Y, w.W, w.H}}
+		fail := func(field string, err error) error {
+			return fmt.Errorf("widgets[%d].%s: %w", i, field, err)
+		}
+
+		switch w.Type {
+		case "text":
+		case "line":
+			cw.reg.H = 1
+		case "value", "bar", "graph":
+			if w.Source == "" {
+				return nil, fail("source", errors.New("required"))
+			}
+			ref, err := ParseSource(w.Source)
+			if err != nil {
+				return nil, fail("source", err)
+			}
+			cw.ref = ref
+		default:
+			return nil, fail("type", fmt.Errorf("unknown widget type %q", w.Type))
+		}
+
+		if cw.reg.W <= 0 || cw.reg.H <= 0 || !cw.reg.Bounds().In(Region{0, 0, width, height}.Bounds()) {
+			return nil, fmt.Errorf("widgets[%d]: region %v outside %dx%d screen", i, cw.reg, width, height)
+		}
+		if _, err := theme.NamedColor(w.Color, nil); err != nil {
+			return nil, fail("color", fmt.Errorf("%q: %w", w.Color, err))
+		}
+		if _, err := fontSize(w.Size, DefaultFontConfig()); err != nil {
+			return nil, fail("size", err)
+		}
+		switch w.Align {
+		case "", "left", "center", "right":
+		default:
+			return nil, fail("align", fmt.Errorf("unknown value %q", w.Align))
+		}
+
+		var ok bool
+		if cw.fill, ok = barFills[w.Fill]; !ok {
+			return nil, fail("fill", fmt.Errorf("unknown value %q", w.Fill))
+		}
+		if cw.kind, ok = graphKinds[w.Kind]; !ok {
+			return nil, fail("kind", fmt.Errorf("unknown value %q", w.Kind))
+		}
+		if cw.Max == 0 && cw.Min == 0 {
+			cw.Max = 100
+		}
+		if cw.Max <= cw.Min {
+			return nil, fail("max", fmt.Errorf("must be greater than min"))
+		}
+		for j, th := range w.Thresholds {
+			if _, err := theme.NamedColor(th.Color, nil); err != nil || th.Color == "" {
+				return nil, fail(fmt.Sprintf("thresholds[%d].color", j), fmt.Errorf("%q: %w", th.Color, ErrInvalidColor))
+			}
+		}
+		widgets[i] = cw
+	}
+	return widgets, nil
+}
+
+// fontSize resolves a size name or pixel count.
+func fontSize(s string, fonts FontConfig) (float64, error) {
+	switch s {
+	case "", "normal":
+		return fonts.Normal, nil
+	case "small":
+		return fonts.Small, nil
+	case "large":
+		return fonts.Large, nil
+	}
+	px, err := strconv.ParseFloat(s, 64)
+	if err != nil || px < 6 || px > 200 {
+		return 0, fmt.Errorf("%q is not small, normal, large or a pixel size", s)
+	}
+	return px, nil
+}
+
+// thresholdScale builds the color scale for a widget's thresholds, in the
+// units of the value (or, for bars, percent of the bar's range).
+func (w *tmplWidget) thresholdScale(theme Theme, def color.Color, pct bool) *Scale {
+	if len(w.Thresholds) == 0 {
+		return nil
+	}
+	// Values below the first threshold use def; bars start at their minimum
+	base := math.Inf(-1)
+	if pct {
+		base = 0
+	}
+	stops := []Stop{{base, def}}
+	for _, th := range w.Thresholds {
+		c, _ := theme.NamedColor(th.Color, def)
+		at := th.From
+		if pct {
+			at = (at - w.Min) / (w.Max - w.Min) * 100
+		}
+		stops = append(stops, Stop{at, c})
+	}
+	s := NewScale(stops...)
+	return &s
+}
+
+// TemplateMonitor draws a screen described by a YAML layout file and
+// reloads it when the file changes.
+type TemplateMonitor struct {
+	*Base
+	path    string
+	sampler *Sampler
+	history *HistoryStore
+
+	widgets     []tmplWidget
+	layoutTheme *Theme // Theme named by the layout, if any
+	baseTheme   Theme  // Configured theme, used when the layout names none
+	modTime     time.Time
+	size        int64
+	loadErr     error
+}
+
+// NewTemplateMonitor creates a template monitor, returning an error if the
+// layout can't be loaded.
+func NewTemplateMonitor(screen lcd.Screen, brightness int, interval time.Duration, logger *slog.Logger, path string) (*TemplateMonitor, error) {
+	base := NewBase(Config{
+		Screen:   screen,
+		Theme:    DefaultTheme(),
+		Fonts:    DefaultFontConfig(),
+		Interval: interval,
+		Logger:   logger,
+	})
+
+	m := &TemplateMonitor{
+		Base:      base,
+		path:      path,
+		sampler:   NewSampler(),
+		history:   NewHistoryStore(base.Width()),
+		baseTheme: base.Theme(),
+	}
+	if _, err := m.load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Name returns the monitor name.
+func (m *TemplateMonitor) Name() string { return "Template" }
+
+// Run starts the template monitor loop.
+func (m *TemplateMonitor) Run() error {
+	m.SetRunning(true)
+
+	if err := m.redrawAll(); err != nil {
+		return fmt.Errorf("initial draw: %w", err)
+	}
+
+	m.Logger().Info("started", "monitor", m.Name(), "layout", m.path)
+
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+
+	for m.Running() {
+		select {
+		case <-ticker.C:
+			if err := m.Tick(m.update); err != nil {
+				m.Logger().Error("update failed", "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the monitor.
+func (m *TemplateMonitor) Stop() {
+	m.SetRunning(false)
+}
+
+// SetTheme sets the theme used when the layout doesn't name one. It must be
+// called before Run.
+func (m *TemplateMonitor) SetTheme(t Theme) {
+	m.baseTheme = t
+	if m.layoutTheme == nil {
+		m.Base.SetTheme(t)
+	}
+}
+
+// load reads the layout file if it changed since the last successful load,
+// so a failed load is retried until it succeeds, whichever file it failed
+// on. It reports whether a new layout was installed; on error the previous
+// layout stays.
+func (m *TemplateMonitor) load() (bool, error) {
+	fi, err := os.Stat(m.path)
+	if err != nil {
+		return false, err
+	}
+	if fi.ModTime().Equal(m.modTime) && fi.Size() == m.size {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		return false, err
+	}
+	t, err := ParseTemplate(data)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", m.path, err)
+	}
+	widgets, err := t.compile(m.Width(), m.Height())
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", m.path, err)
+	}
+
+	var theme *Theme
+	if t.Theme != "" {
+		name := t.Theme
+		if _, builtin := BuiltinTheme(name); !builtin && !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(m.path), name)
+		}
+		th, err := LoadTheme(name)
+		if err != nil {
+			return false, fmt.Errorf("%s: theme: %w", m.path, err)
+		}
+		theme = &th
+	}
+
+	m.widgets = widgets
+	m.layoutTheme = theme
+	m.history = NewHistoryStore(m.Width())
+	m.modTime, m.size = fi.ModTime(), fi.Size()
+	return true, nil
+}
+
+// redrawAll applies the layout's theme and repaints the whole screen.
+func (m *TemplateMonitor) redrawAll() error {
+	if m.layoutTheme != nil {
+		m.Base.SetTheme(*m.layoutTheme)
+	} else {
+		m.Base.SetTheme(m.baseTheme)
+	}
+	m.ResetChanged()
+	m.ClearBuffer()
+	m.drawStatic()
+	m.render()
+	return m.DrawFullBuffer()
+}
+
+// errorRegion returns the strip used to report layout errors.
+func (m *TemplateMonitor) errorRegion() Region {
+	return Region{0, m.Height() - 20, m.Width(), 20}
+}
+
+func (m *TemplateMonitor) drawStatic() {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+
+	for _, w := range m.widgets {
+		switch w.Type {
+		case "text":
+			c, _ := m.Theme().NamedColor(w.Color, m.Colors().Text)
+			m.drawAligned(r, w, w.Text, c)
+		case "line":
+			r.DrawLine(float64(w.X), float64(w.Y), float64(w.X+w.W))
+		}
+	}
+}
+
+// drawAligned draws text in a widget's region with its size and alignment.
+func (m *TemplateMonitor) drawAligned(r *Renderer, w tmplWidget, text string, c color.Color) {
+	size, _ := fontSize(w.Size, m.fonts)
+	x, y, width := float64(w.X), float64(w.Y), float64(w.W)
+	switch w.Align {
+	case "right":
+		r.DrawTextRightFit(x, y, width, text, size, c, EllipsisEnd)
+	case "center":
+		text = r.TruncateText(text, width, size, EllipsisEnd)
+		r.DrawText(x+(width-r.MeasureText(text, size))/2, y, text, size, c)
+	default:
+		r.DrawTextFit(x, y, width, text, size, c, EllipsisEnd)
+	}
+}
+
+func (m *TemplateMonitor) update() error {
+	// Hot reload; a broken edit keeps the last good layout on screen
+	switch changed, err := m.load(); {
+	case err != nil:
+		if m.loadErr == nil || m.loadErr.Error() != err.Error() {
+			m.Logger().Error("layout reload failed", "error", err)
+		}
+		m.loadErr = err
+	case changed:
+		m.Logger().Info("layout reloaded", "layout", m.path)
+		m.loadErr = nil
+		return m.redrawAll()
+	}
+
+	for _, reg := range m.render() {
+		if err := m.DrawRegion(reg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render draws the widgets whose values changed into the buffer and returns
+// the regions to send.
+func (m *TemplateMonitor) render() []Region {
+	dc := m.NewContext(Region{0, 0, m.Width(), m.Height()})
+	r := NewRenderer(dc, m.Theme(), m.fonts)
+	theme := m.Theme()
+
+	var updates []Region
+	m.sampler.Reset()
+
+	for i, w := range m.widgets {
+		if w.ref.Name == "" {
+			continue
+		}
+		key := fmt.Sprintf("w%d", i)
+		v, err := m.sampler.Get(w.ref)
+		if err != nil {
+			if m.Changed(key+"_err", err.Error()) {
+				m.Logger().Debug("source failed", "source", w.ref.String(), "error", err)
+			}
+		} else {
+			m.Changed(key+"_err", "")
+		}
+
+		switch w.Type {
+		case "value":
+			text, c := "--", theme.Colors.TextDim
+			if err == nil {
+				text = v.Format(w.Format)
+				c, _ = theme.NamedColor(w.Color, theme.Colors.Text)
+				if s := w.thresholdScale(theme, c, false); s != nil && v.IsNum {
+					c = s.Color(v.Num)
+				}
+			}
+			if m.Changed(key, fmt.Sprint(text, c)) {
+				r.Clear(w.reg)
+				m.drawAligned(r, w, text, c)
+				updates = append(updates, w.reg)
+			}
+
+		case "bar":
+			if err != nil || !v.IsNum {
+				continue
+			}
+			if m.ChangedFloat(key, v.Num, (w.Max-w.Min)/200) {
+				style := BarStyle{Fill: w.fill, Border: w.Border == nil || *w.Border}
+				if c, _ := theme.NamedColor(w.Color, nil); c != nil {
+					s := NewScale(Stop{0, c})
+					style.Scale = &s
+				}
+				if s := w.thresholdScale(theme, theme.Colors.BarLow, true); s != nil {
+					style.Scale = s
+				}
+				r.DrawBarStyled(w.reg, v.Num, w.Min, w.Max, style)
+				updates = append(updates, w.reg)
+			}
+
+		case "graph":
+			if err != nil || !v.IsNum {
+				continue
+			}
+			h := m.history.Get(key)
+			h.Push(v.Num)
+			style := GraphStyle{Kind: w.kind, Min: w.Min, Max: w.Max}
+			style.Color, _ = theme.NamedColor(w.Color, nil)
+			style.Scale = w.thresholdScale(theme, theme.Colors.BarLow, true)
+			m.UpdateGraph(r, key, w.reg, h.Values(), style)
+			updates = append(updates, w.reg)
+		}
+	}
+
+	// Layout error strip
+	msg := ""
+	if m.loadErr != nil {
+		msg = m.loadErr.Error()
+	}
+	if m.Changed("load_error", msg) && msg != "" {
+		reg := m.errorRegion()
+		r.Clear(reg)
+		r.DrawTextFit(float64(reg.X+5), float64(reg.Y+2), float64(reg.W-10), msg, m.fonts.Small, theme.Colors.BarHigh, EllipsisStart)
+		updates = append(updates, reg)
+	}
+	return updates
+}
@@ -0,0 +1,145 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aleksclark/go-turing-smart-screen/internal/lcd"
+	"github.com/aleksclark/go-turing-smart-screen/internal/sysinfo"
+)
+
+func TestTemplateCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{"valid", `
+theme: amber
+widgets:
+  - {type: text, x: 5, y: 8, w: 200, h: 24, text: Overview, size: large, color: header}
+  - {type: value, x: 5, y: 40, w: 120, h: 20, source: cpu.overall, format: "%.0f%%", align: right,
+     thresholds: [{from: 80, color: bar_high}]}
+  - {type: bar, x: 130, y: 40, w: 180, h: 20, source: "agents.count{status=waiting}", max: 5, fill: segmented}
+  - {type: graph, x: 5, y: 70, w: 310, h: 40, source: mem.used_percent, kind: area}
+  - {type: line, x: 0, y: 32, w: 320}
+`, ""},
+		{"unknown key", "widgets:\n  - {type: text, x: 0, y: 0, w: 10, h: 10, colour: red}", "field colour not found"},
+		{"unknown type", "widgets:\n  - {type: dial, x: 0, y: 0, w: 10, h: 10}", `widgets[0].type: unknown widget type "dial"`},
+		{"missing source", "widgets:\n  - {type: bar, x: 0, y: 0, w: 10, h: 10}", "widgets[0].source: required"},
+		{"bad source", "widgets:\n  - {type: value, x: 0, y: 0, w: 10, h: 10, source: gpu.load}", `unknown source "gpu.load"`},
+		{"off screen", "widgets:\n  - {type: text, x: 300, y: 0, w: 100, h: 10}", "outside 320x480 screen"},
+		{"bad color", "widgets:\n  - {type: text, x: 0, y: 0, w: 10, h: 10, color: teal}", `widgets[0].color: "teal"`},
+		{"bad size", "widgets:\n  - {type: text, x: 0, y: 0, w: 10, h: 10, size: huge}", "widgets[0].size"},
+		{"bad threshold", "widgets:\n  - {type: value, x: 0, y: 0, w: 10, h: 10, source: cpu.temp, thresholds: [{from: 90}]}", "thresholds[0].color"},
+		{"bad range", "widgets:\n  - {type: bar, x: 0, y: 0, w: 10, h: 10, source: cpu.temp, min: 50, max: 20}", "widgets[0].max"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate([]byte(tt.yaml))
+			if err == nil {
+				_, err = tmpl.compile(320, 480)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplateHotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "screen.yaml")
+	write := func(content string, age time.Duration) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mt := time.Now().Add(-age)
+		if err := os.Chtimes(path, mt, mt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("widgets:\n  - {type: value, x: 5, y: 5, w: 100, h: 20, source: cpu.overall}\n", time.Hour)
+
+	if _, err := NewTemplateMonitor(lcd.NewSimulated(320, 480), 50, time.Second, nil, path+".missing"); err == nil {
+		t.Error("NewTemplateMonitor() with missing layout returned no error")
+	}
+
+	screen := newRecordingScreen(320, 480)
+	m, err := NewTemplateMonitor(screen, 50, time.Second, nil, path)
+	if err != nil {
+		t.Fatalf("NewTemplateMonitor() error = %v", err)
+	}
+	cpu := 10.0
+	m.sampler.ReadCPU = func() (*sysinfo.CPUInfo, error) { return &sysinfo.CPUInfo{Overall: cpu}, nil }
+	if err := m.redrawAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only changed values are sent
+	before := screen.count()
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if n := screen.count() - before; n != 0 {
+		t.Errorf("unchanged value sent %d regions", n)
+	}
+	cpu = 55
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if n := screen.count() - before; n != 1 {
+		t.Errorf("changed value sent %d regions, want 1", n)
+	}
+
+	// A broken edit keeps the old layout
+	write("widgets:\n  - {type: value, x: 5, y: 5, w: 100, h: 20, source: cpu.overal}\n", 30*time.Minute)
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if m.loadErr == nil || len(m.widgets) != 1 {
+		t.Errorf("broken layout: loadErr = %v, widgets = %d", m.loadErr, len(m.widgets))
+	}
+
+	// A fixed edit is picked up
+	write("widgets:\n  - {type: value, x: 5, y: 5, w: 100, h: 20, source: cpu.overall}\n"+
+		"  - {type: line, x: 0, y: 30, w: 320}\n", 0)
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if m.loadErr != nil || len(m.widgets) != 2 {
+		t.Errorf("fixed layout: loadErr = %v, widgets = %d", m.loadErr, len(m.widgets))
+	}
+
+	// A broken theme is retried until the theme file is fixed, even though
+	// the layout file doesn't change again
+	themePath := filepath.Join(filepath.Dir(path), "mine.json")
+	if err := os.WriteFile(themePath, []byte(`{"colors": {"bg": "#zzz"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	write("theme: mine.json\nwidgets:\n  - {type: line, x: 0, y: 30, w: 320}\n", 0)
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if m.loadErr == nil {
+		t.Error("broken theme: no loadErr")
+	}
+	if err := os.WriteFile(themePath, []byte(`{"colors": {"bg": "#101010"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.update(); err != nil {
+		t.Fatal(err)
+	}
+	if m.loadErr != nil || len(m.widgets) != 1 || m.layoutTheme == nil {
+		t.Errorf("fixed theme: loadErr = %v, widgets = %d", m.loadErr, len(m.widgets))
+	}
+}
@@ -0,0 +1,239 @@
+package monitor
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/fogleman/gg"
+)
+
+// EllipsisMode selects where TruncateText elides overflowing text.
+type EllipsisMode int
+
+const (
+	EllipsisEnd    EllipsisMode = iota // "long te…"
+	EllipsisStart                      // "…ng text"
+	EllipsisMiddle                     // "lon…ext"
+)
+
+// ellipsis is appended or inserted when text is truncated.
+const ellipsis = "…"
+
+// setFont loads the font face for a size. If the font file cannot be loaded
+// the context keeps its current face, so measurement still works.
+func (r *Renderer) setFont(fontSize float64) error {
+	return r.dc.LoadFontFace(r.fonts.Path, fontSize)
+}
+
+// MeasureText returns the rendered width of text in pixels.
+func (r *Renderer) MeasureText(text string, fontSize float64) float64 {
+	r.setFont(fontSize)
+	w, _ := r.dc.MeasureString(text)
+	return w
+}
+
+// TruncateText shortens text so it renders within width pixels, replacing the
+// removed runes with an ellipsis. Text that already fits is returned as is.
+func (r *Renderer) TruncateText(text string, width, fontSize float64, mode EllipsisMode) string {
+	r.setFont(fontSize)
+	return r.truncate(text, width, mode)
+}
+
+// truncate is TruncateText without reloading the font face.
+func (r *Renderer) truncate(text string, width float64, mode EllipsisMode) string {
+	if w, _ := r.dc.MeasureString(text); w <= width {
+		return text
+	}
+	runes := []rune(text)
+
+	// Binary search for the largest number of kept runes that fits
+	lo, hi := 0, len(runes)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if w, _ := r.dc.MeasureString(elide(runes, mid, mode)); w <= width {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo == 0 {
+		if w, _ := r.dc.MeasureString(ellipsis); w > width {
+			return ""
+		}
+	}
+	return elide(runes, lo, mode)
+}
+
+// elide keeps n runes of text and inserts an ellipsis according to mode.
+func elide(runes []rune, n int, mode EllipsisMode) string {
+	switch mode {
+	case EllipsisStart:
+		return ellipsis + strings.TrimLeft(string(runes[len(runes)-n:]), " ")
+	case EllipsisMiddle:
+		head := (n + 1) / 2
+		tail := n - head
+		return strings.TrimRight(string(runes[:head]), " ") + ellipsis + string(runes[len(runes)-tail:])
+	default:
+		return strings.TrimRight(string(runes[:n]), " ") + ellipsis
+	}
+}
+
+// WrapText breaks text into at most maxLines lines that each fit within width
+// pixels. Words wider than a line are split between runes. If the text does not
+// fit in maxLines, the last line ends with an ellipsis. A maxLines of 0 or less
+// means no limit.
+func (r *Renderer) WrapText(text string, width, fontSize float64, maxLines int) []string {
+	r.setFont(fontSize)
+
+	var lines []string
+	var line string
+	fits := func(s string) bool {
+		w, _ := r.dc.MeasureString(s)
+		return w <= width
+	}
+
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if fits(candidate) {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		// Split words that cannot fit on a line of their own
+		line = ""
+		for _, ch := range word {
+			if !fits(line+string(ch)) && line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			line += string(ch)
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	if maxLines > 0 && len(lines) > maxLines {
+		rest := strings.Join(lines[maxLines-1:], " ")
+		lines = append(lines[:maxLines-1], r.truncate(rest, width, EllipsisEnd))
+	}
+	return lines
+}
+
+// DrawTextFit draws text truncated to width pixels.
+func (r *Renderer) DrawTextFit(x, y, width float64, text string, fontSize float64, c color.Color, mode EllipsisMode) {
+	if err := r.setFont(fontSize); err != nil {
+		return
+	}
+	r.dc.SetColor(c)
+	r.dc.DrawString(r.truncate(text, width, mode), x, y+fontSize)
+}
+
+// DrawTextRightFit draws right-aligned text truncated to width pixels.
+func (r *Renderer) DrawTextRightFit(x, y, width float64, text string, fontSize float64, c color.Color, mode EllipsisMode) {
+	if err := r.setFont(fontSize); err != nil {
+		return
+	}
+	text = r.truncate(text, width, mode)
+	tw, _ := r.dc.MeasureString(text)
+	r.dc.SetColor(c)
+	r.dc.DrawString(text, x+width-tw, y+fontSize)
+}
+
+// DrawWrapped draws text wrapped to width pixels over at most maxLines lines,
+// spaced lineHeight pixels apart. It returns the number of lines drawn.
+func (r *Renderer) DrawWrapped(x, y, width float64, text string, fontSize, lineHeight float64, maxLines int, c color.Color) int {
+	lines := r.WrapText(text, width, fontSize, maxLines)
+	r.dc.SetColor(c)
+	for i, line := range lines {
+		r.dc.DrawString(line, x, y+float64(i)*lineHeight+fontSize)
+	}
+	return len(lines)
+}
+
+// Marquee scrolls text horizontally when it is wider than its region.
+// It is advanced once per monitor tick with Tick and drawn with
+// Renderer.DrawMarquee; text that fits is drawn statically and never scrolls.
+type Marquee struct {
+	Step float64 // Pixels scrolled per tick
+	Gap  float64 // Spacing between the end of the text and its repeat
+	Hold int     // Ticks to pause at the start of each cycle
+
+	text     string
+	offset   float64
+	hold     int
+	cycle    float64
+	overflow bool
+}
+
+// NewMarquee creates a marquee with the given scroll step, gap and hold.
+func NewMarquee(step, gap float64, hold int) *Marquee {
+	return &Marquee{Step: step, Gap: gap, Hold: hold, hold: hold}
+}
+
+// SetText sets the marquee text, restarting the scroll if it changed.
+// It returns true if the text changed.
+func (m *Marquee) SetText(text string) bool {
+	if text == m.text {
+		return false
+	}
+	m.text = text
+	m.offset = 0
+	m.hold = m.Hold
+	m.overflow = false
+	return true
+}
+
+// Text returns the marquee text.
+func (m *Marquee) Text() string { return m.text }
+
+// Tick advances the scroll position and returns true if the marquee needs
+// to be redrawn. Marquees whose text fits never need redrawing.
+func (m *Marquee) Tick() bool {
+	if !m.overflow {
+		return false
+	}
+	if m.hold > 0 {
+		m.hold--
+		return false
+	}
+	m.offset += m.Step
+	if m.offset >= m.cycle {
+		m.offset = 0
+		m.hold = m.Hold
+	}
+	return true
+}
+
+// DrawMarquee draws the marquee text into reg at the current scroll offset,
+// replacing the region's previous contents.
+func (r *Renderer) DrawMarquee(reg Region, m *Marquee, fontSize float64, c color.Color) {
+	if err := r.setFont(fontSize); err != nil {
+		return
+	}
+	tw, _ := r.dc.MeasureString(m.text)
+	m.overflow = tw > float64(reg.W)
+	m.cycle = tw + m.Gap
+	if !m.overflow {
+		m.offset = 0
+	}
+
+	// Render offscreen: gg's Pop does not restore the clip mask
+	tmp := gg.NewContext(reg.W, reg.H)
+	tmp.SetColor(r.colors.BG)
+	tmp.Clear()
+	if err := tmp.LoadFontFace(r.fonts.Path, fontSize); err != nil {
+		return
+	}
+	tmp.SetColor(c)
+	tmp.DrawString(m.text, -m.offset, fontSize)
+	if m.overflow {
+		tmp.DrawString(m.text, m.cycle-m.offset, fontSize)
+	}
+	r.dc.DrawImage(tmp.Image(), reg.X, reg.Y)
+}
@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fogleman/gg"
+)
+
+func newTestRenderer(w, h int) *Renderer {
+	return NewRenderer(gg.NewContext(w, h), DefaultTheme(), DefaultFontConfig())
+}
+
+func TestTruncateText(t *testing.T) {
+	r := newTestRenderer(200, 50)
+	const size = 16
+
+	tests := []struct {
+		name   string
+		text   string
+		width  float64
+		mode   EllipsisMode
+		prefix string
+		suffix string
+	}{
+		{"fits unchanged", "gopls", 200, EllipsisEnd, "gopls", "gopls"},
+		{"end", "rust-analyzer-proc-macro-srv", 100, EllipsisEnd, "rust", ellipsis},
+		{"start", "/home/user/src/project", 100, EllipsisStart, ellipsis, "project"},
+		{"middle", "abcdefghijklmnopqrstuvwxyz", 100, EllipsisMiddle, "abc", "xyz"},
+		{"multibyte", "日本語のプロセス名がとても長い", 100, EllipsisEnd, "日本", ellipsis},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.TruncateText(tt.text, tt.width, size, tt.mode)
+			if !utf8.ValidString(got) {
+				t.Fatalf("TruncateText() = %q, not valid UTF-8", got)
+			}
+			if w := r.MeasureText(got, size); w > tt.width {
+				t.Errorf("TruncateText() = %q, width %.0f > %.0f", got, w, tt.width)
+			}
+			if !strings.HasPrefix(got, tt.prefix) || !strings.HasSuffix(got, tt.suffix) {
+				t.Errorf("TruncateText() = %q, want prefix %q and suffix %q", got, tt.prefix, tt.suffix)
+			}
+		})
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	r := newTestRenderer(200, 50)
+	const size, width = 14, 120
+	text := "Refactoring the renderer so long agent tasks wrap instead of running off the panel"
+
+	lines := r.WrapText(text, width, size, 0)
+	if len(lines) < 3 {
+		t.Fatalf("WrapText() = %d lines, want at least 3", len(lines))
+	}
+	for _, line := range lines {
+		if w := r.MeasureText(line, size); w > width {
+			t.Errorf("line %q width %.0f > %d", line, w, width)
+		}
+	}
+	if got := strings.Join(lines, " "); got != text {
+		t.Errorf("unlimited WrapText() lost text: %q", got)
+	}
+
+	limited := r.WrapText(text, width, size, 2)
+	if len(limited) != 2 {
+		t.Fatalf("WrapText(maxLines=2) = %d lines, want 2", len(limited))
+	}
+	if !strings.HasSuffix(limited[1], ellipsis) {
+		t.Errorf("last line %q should end with an ellipsis", limited[1])
+	}
+}
+
+func TestMarqueeTick(t *testing.T) {
+	r := newTestRenderer(300, 40)
+	reg := Region{0, 0, 60, 20}
+
+	m := NewMarquee(5, 10, 1)
+	m.SetText("short")
+	r.DrawMarquee(Region{0, 0, 300, 20}, m, 14, DefaultColors().Text)
+	if m.Tick() {
+		t.Error("Tick() = true for text that fits")
+	}
+
+	m.SetText("a task description that is much wider than its region")
+	r.DrawMarquee(reg, m, 14, DefaultColors().Text)
+	if m.Tick() {
+		t.Error("Tick() = true during hold")
+	}
+	if !m.Tick() {
+		t.Error("Tick() = false after hold for overflowing text")
+	}
+	if m.SetText(m.Text()) {
+		t.Error("SetText() with unchanged text reported a change")
+	}
+}
@@ -0,0 +1,458 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aleksclark/go-turing-smart-screen/pkg/agentstat"
+)
+
+// Theme is a complete color scheme for a monitor: the base palette, the agent
+// status indicator colors and the points at which bars change color.
+type Theme struct {
+	Name          string
+	Colors        Colors
+	Status        map[string]color.Color
+	StatusDefault color.Color
+	Thresholds    Thresholds
+}
+
+// Thresholds are the bar fill percentages at which the bar color switches
+// from BarLow to BarMed and from BarMed to BarHigh.
+type Thresholds struct {
+	Medium float64
+	High   float64
+}
+
+// StatusColor returns the indicator color for an agent status.
+func (t Theme) StatusColor(status string) color.Color {
+	if c, ok := t.Status[status]; ok {
+		return c
+	}
+	return t.StatusDefault
+}
+
+// TempColor returns the text color for a temperature in Celsius: normal
+// below warm, the medium bar color below hot and the high bar color above.
+func (t Theme) TempColor(c, warm, hot float64) color.Color {
+	switch {
+	case c >= hot:
+		return t.Colors.BarHigh
+	case c >= warm:
+		return t.Colors.BarMed
+	}
+	return t.Colors.Text
+}
+
+// DefaultTheme returns the built-in htop-style green theme.
+func DefaultTheme() Theme {
+	return Theme{
+		Name:   "htop",
+		Colors: DefaultColors(),
+		Status: map[string]color.Color{
+			"idle":     color.RGBA{100, 100, 100, 255}, // Gray
+			"thinking": color.RGBA{255, 255, 0, 255},   // Yellow
+			"working":  color.RGBA{0, 255, 0, 255},     // Green
+			"waiting":  color.RGBA{0, 150, 255, 255},   // Blue
+			"error":    color.RGBA{255, 0, 0, 255},     // Red
+			"done":     color.RGBA{0, 255, 150, 255},   // Teal
+			"paused":   color.RGBA{255, 150, 0, 255},   // Orange
+		},
+		StatusDefault: color.RGBA{80, 80, 80, 255},
+		Thresholds:    Thresholds{Medium: 50, High: 80},
+	}
+}
+
+// builtinThemes holds the themes selectable by name.
+var builtinThemes = map[string]func() Theme{
+	"htop":          DefaultTheme,
+	"amber":         amberTheme,
+	"solarized":     solarizedTheme,
+	"high-contrast": highContrastTheme,
+	"colorblind":    colorblindTheme,
+}
+
+// ThemeNames returns the names of the built-in themes.
+func ThemeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuiltinTheme returns a built-in theme by name.
+func BuiltinTheme(name string) (Theme, bool) {
+	fn, ok := builtinThemes[name]
+	if !ok {
+		return Theme{}, false
+	}
+	return fn(), true
+}
+
+// amberTheme is a monochrome amber terminal palette.
+func amberTheme() Theme {
+	t := DefaultTheme()
+	t.Name = "amber"
+	t.Colors = Colors{
+		BG:      color.RGBA{0, 0, 0, 255},
+		Text:    color.RGBA{255, 176, 0, 255},
+		TextDim: color.RGBA{180, 120, 0, 255},
+		Header:  color.RGBA{255, 204, 0, 255},
+		BarLow:  color.RGBA{200, 130, 0, 255},
+		BarMed:  color.RGBA{255, 176, 0, 255},
+		BarHigh: color.RGBA{255, 80, 0, 255},
+		BarBG:   color.RGBA{40, 28, 0, 255},
+		Border:  color.RGBA{90, 60, 0, 255},
+	}
+	t.Status = map[string]color.Color{
+		"idle":     color.RGBA{100, 70, 0, 255},
+		"thinking": color.RGBA{255, 204, 0, 255},
+		"working":  color.RGBA{255, 176, 0, 255},
+		"waiting":  color.RGBA{255, 230, 150, 255},
+		"error":    color.RGBA{255, 60, 0, 255},
+		"done":     color.RGBA{200, 150, 50, 255},
+		"paused":   color.RGBA{160, 100, 0, 255},
+	}
+	t.StatusDefault = color.RGBA{70, 50, 0, 255}
+	return t
+}
+
+// solarizedTheme uses the Solarized dark palette.
+func solarizedTheme() Theme {
+	t := DefaultTheme()
+	t.Name = "solarized"
+	t.Colors = Colors{
+		BG:      color.RGBA{0, 43, 54, 255},     // base03
+		Text:    color.RGBA{147, 161, 161, 255}, // base1
+		TextDim: color.RGBA{101, 123, 131, 255}, // base00
+		Header:  color.RGBA{38, 139, 210, 255},  // blue
+		BarLow:  color.RGBA{133, 153, 0, 255},   // green
+		BarMed:  color.RGBA{181, 137, 0, 255},   // yellow
+		BarHigh: color.RGBA{220, 50, 47, 255},   // red
+		BarBG:   color.RGBA{7, 54, 66, 255},     // base02
+		Border:  color.RGBA{88, 110, 117, 255},  // base01
+	}
+	t.Status = map[string]color.Color{
+		"idle":     color.RGBA{88, 110, 117, 255},
+		"thinking": color.RGBA{181, 137, 0, 255},
+		"working":  color.RGBA{133, 153, 0, 255},
+		"waiting":  color.RGBA{38, 139, 210, 255},
+		"error":    color.RGBA{220, 50, 47, 255},
+		"done":     color.RGBA{42, 161, 152, 255},
+		"paused":   color.RGBA{203, 75, 22, 255},
+	}
+	t.StatusDefault = color.RGBA{7, 54, 66, 255}
+	return t
+}
+
+// highContrastTheme maximises legibility on dim or distant panels.
+func highContrastTheme() Theme {
+	t := DefaultTheme()
+	t.Name = "high-contrast"
+	t.Colors = Colors{
+		BG:      color.RGBA{0, 0, 0, 255},
+		Text:    color.RGBA{255, 255, 255, 255},
+		TextDim: color.RGBA{200, 200, 200, 255},
+		Header:  color.RGBA{255, 255, 0, 255},
+		BarLow:  color.RGBA{0, 255, 0, 255},
+		BarMed:  color.RGBA{255, 255, 0, 255},
+		BarHigh: color.RGBA{255, 0, 255, 255},
+		BarBG:   color.RGBA{60, 60, 60, 255},
+		Border:  color.RGBA{255, 255, 255, 255},
+	}
+	t.Status["idle"] = color.RGBA{160, 160, 160, 255}
+	t.StatusDefault = color.RGBA{120, 120, 120, 255}
+	return t
+}
+
+// colorblindTheme uses the Okabe-Ito palette, which stays distinguishable
+// under the common forms of color vision deficiency.
+func colorblindTheme() Theme {
+	t := DefaultTheme()
+	t.Name = "colorblind"
+	t.Colors = Colors{
+		BG:      color.RGBA{0, 0, 0, 255},
+		Text:    color.RGBA{240, 240, 240, 255},
+		TextDim: color.RGBA{170, 170, 170, 255},
+		Header:  color.RGBA{86, 180, 233, 255}, // sky blue
+		BarLow:  color.RGBA{0, 114, 178, 255},  // blue
+		BarMed:  color.RGBA{230, 159, 0, 255},  // orange
+		BarHigh: color.RGBA{213, 94, 0, 255},   // vermillion
+		BarBG:   color.RGBA{40, 40, 40, 255},
+		Border:  color.RGBA{80, 80, 80, 255},
+	}
+	t.Status = map[string]color.Color{
+		"idle":     color.RGBA{120, 120, 120, 255},
+		"thinking": color.RGBA{240, 228, 66, 255},  // yellow
+		"working":  color.RGBA{0, 158, 115, 255},   // bluish green
+		"waiting":  color.RGBA{86, 180, 233, 255},  // sky blue
+		"error":    color.RGBA{213, 94, 0, 255},    // vermillion
+		"done":     color.RGBA{0, 114, 178, 255},   // blue
+		"paused":   color.RGBA{204, 121, 167, 255}, // reddish purple
+	}
+	return t
+}
+
+// Theme loading errors.
+var (
+	ErrUnknownTheme   = errors.New("unknown theme")
+	ErrUnknownKey     = errors.New("unknown key")
+	ErrInvalidColor   = errors.New("invalid color (want #rgb, #rrggbb or #rrggbbaa)")
+	ErrInvalidNumber  = errors.New("invalid number")
+	ErrThresholdRange = errors.New("threshold must be between 0 and 100")
+	ErrThresholdOrder = errors.New("medium threshold must not exceed high")
+)
+
+// ThemeError reports a problem with a theme file, naming the offending key.
+type ThemeError struct {
+	File string
+	Key  string
+	Err  error
+}
+
+func (e *ThemeError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%s: %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %v", e.File, e.Key, e.Err)
+}
+
+func (e *ThemeError) Unwrap() error {
+	return e.Err
+}
+
+// colorKeys maps theme file keys to palette fields.
+var colorKeys = map[string]func(*Colors) *color.Color{
+	"bg":       func(c *Colors) *color.Color { return &c.BG },
+	"text":     func(c *Colors) *color.Color { return &c.Text },
+	"text_dim": func(c *Colors) *color.Color { return &c.TextDim },
+	"header":   func(c *Colors) *color.Color { return &c.Header },
+	"bar_low":  func(c *Colors) *color.Color { return &c.BarLow },
+	"bar_med":  func(c *Colors) *color.Color { return &c.BarMed },
+	"bar_high": func(c *Colors) *color.Color { return &c.BarHigh },
+	"bar_bg":   func(c *Colors) *color.Color { return &c.BarBG },
+	"border":   func(c *Colors) *color.Color { return &c.Border },
+}
+
+// LoadTheme resolves a theme by built-in name or, failing that, as a path to
+// a theme file. An empty name returns the default theme.
+func LoadTheme(nameOrPath string) (Theme, error) {
+	if nameOrPath == "" {
+		return DefaultTheme(), nil
+	}
+	if t, ok := BuiltinTheme(nameOrPath); ok {
+		return t, nil
+	}
+	data, err := os.ReadFile(nameOrPath)
+	if os.IsNotExist(err) && !strings.ContainsAny(nameOrPath, `/\.`) {
+		return Theme{}, fmt.Errorf("%w %q (built-in themes: %s)",
+			ErrUnknownTheme, nameOrPath, strings.Join(ThemeNames(), ", "))
+	}
+	if err != nil {
+		return Theme{}, err
+	}
+	t, err := ParseTheme(data)
+	if err != nil {
+		var tErr *ThemeError
+		if errors.As(err, &tErr) {
+			tErr.File = nameOrPath
+		}
+		return Theme{}, err
+	}
+	return t, nil
+}
+
+// ParseTheme parses a JSON theme. Themes start from the built-in theme named
+// by "base" (default "htop") and override any subset of its values:
+//
+//	{
+//	  "name": "my-theme",
+//	  "base": "solarized",
+//	  "colors": {"header": "#ff8800", "bar_high": "#f00"},
+//	  "status": {"waiting": "#00aaff", "default": "#444"},
+//	  "thresholds": {"medium": 60, "high": 90}
+//	}
+func ParseTheme(data []byte) (Theme, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return Theme{}, fmt.Errorf("invalid theme JSON: %w", err)
+	}
+
+	t := DefaultTheme()
+	if raw, ok := top["base"]; ok {
+		var base string
+		if err := json.Unmarshal(raw, &base); err != nil {
+			return Theme{}, &ThemeError{Key: "base", Err: err}
+		}
+		bt, ok := BuiltinTheme(base)
+		if !ok {
+			return Theme{}, &ThemeError{Key: "base", Err: fmt.Errorf("%w %q", ErrUnknownTheme, base)}
+		}
+		t = bt
+	}
+	// Don't share the base theme's status map
+	status := make(map[string]color.Color, len(t.Status))
+	for k, v := range t.Status {
+		status[k] = v
+	}
+	t.Status = status
+
+	for _, key := range sortedKeys(top) {
+		raw := top[key]
+		switch key {
+		case "base":
+		case "name":
+			if err := json.Unmarshal(raw, &t.Name); err != nil {
+				return Theme{}, &ThemeError{Key: key, Err: err}
+			}
+		case "colors":
+			colors, err := parseColorMap(key, raw)
+			if err != nil {
+				return Theme{}, err
+			}
+			for k, c := range colors {
+				field, ok := colorKeys[k]
+				if !ok {
+					return Theme{}, &ThemeError{Key: key + "." + k, Err: ErrUnknownKey}
+				}
+				*field(&t.Colors) = c
+			}
+		case "status":
+			colors, err := parseColorMap(key, raw)
+			if err != nil {
+				return Theme{}, err
+			}
+			for k, c := range colors {
+				switch {
+				case k == "default":
+					t.StatusDefault = c
+				case isAgentStatus(k):
+					t.Status[k] = c
+				default:
+					return Theme{}, &ThemeError{Key: key + "." + k, Err: ErrUnknownKey}
+				}
+			}
+		case "thresholds":
+			if err := parseThresholds(key, raw, &t.Thresholds); err != nil {
+				return Theme{}, err
+			}
+		default:
+			return Theme{}, &ThemeError{Key: key, Err: ErrUnknownKey}
+		}
+	}
+
+	return t, nil
+}
+
+// parseColorMap decodes an object of hex color strings.
+func parseColorMap(key string, raw json.RawMessage) (map[string]color.Color, error) {
+	var m map[string]string
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, &ThemeError{Key: key, Err: err}
+	}
+	colors := make(map[string]color.Color, len(m))
+	for _, k := range sortedKeys(m) {
+		c, err := ParseColor(m[k])
+		if err != nil {
+			return nil, &ThemeError{Key: key + "." + k, Err: err}
+		}
+		colors[k] = c
+	}
+	return colors, nil
+}
+
+// parseThresholds decodes the bar thresholds object.
+func parseThresholds(key string, raw json.RawMessage, th *Thresholds) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return &ThemeError{Key: key, Err: err}
+	}
+	for _, k := range sortedKeys(m) {
+		var dst *float64
+		switch k {
+		case "medium":
+			dst = &th.Medium
+		case "high":
+			dst = &th.High
+		default:
+			return &ThemeError{Key: key + "." + k, Err: ErrUnknownKey}
+		}
+		var v float64
+		if err := json.Unmarshal(m[k], &v); err != nil {
+			return &ThemeError{Key: key + "." + k, Err: ErrInvalidNumber}
+		}
+		if v < 0 || v > 100 {
+			return &ThemeError{Key: key + "." + k, Err: ErrThresholdRange}
+		}
+		*dst = v
+	}
+	if th.Medium > th.High {
+		return &ThemeError{Key: key + ".medium", Err: ErrThresholdOrder}
+	}
+	return nil
+}
+
+// NamedColor resolves a color given in config as a palette key such as
+// "bar_high", an agent status such as "waiting", or a hex color. An empty
+// string returns def.
+func (t Theme) NamedColor(s string, def color.Color) (color.Color, error) {
+	if s == "" {
+		return def, nil
+	}
+	if field, ok := colorKeys[s]; ok {
+		return *field(&t.Colors), nil
+	}
+	if isAgentStatus(s) {
+		return t.StatusColor(s), nil
+	}
+	return ParseColor(s)
+}
+
+// ParseColor parses a #rgb, #rrggbb or #rrggbbaa hex color.
+func ParseColor(s string) (color.Color, error) {
+	hex, ok := strings.CutPrefix(strings.TrimSpace(s), "#")
+	if !ok {
+		return nil, ErrInvalidColor
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return nil, ErrInvalidColor
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, ErrInvalidColor
+	}
+	return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+func isAgentStatus(s string) bool {
+	for _, v := range agentstat.ValidStatuses {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns map keys in order so errors are reported deterministically.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltinThemesComplete(t *testing.T) {
+	for _, name := range ThemeNames() {
+		t.Run(name, func(t *testing.T) {
+			th, err := LoadTheme(name)
+			if err != nil {
+				t.Fatalf("LoadTheme(%q) error = %v", name, err)
+			}
+			if th.Name != name {
+				t.Errorf("Name = %q, want %q", th.Name, name)
+			}
+			c := th.Colors
+			for _, v := range []color.Color{c.BG, c.Text, c.TextDim, c.Header, c.BarLow, c.BarMed, c.BarHigh, c.BarBG, c.Border, th.StatusDefault} {
+				if v == nil {
+					t.Fatal("theme has an unset color")
+				}
+			}
+			for _, s := range []string{"idle", "thinking", "working", "waiting", "error", "done", "paused"} {
+				if _, ok := th.Status[s]; !ok {
+					t.Errorf("missing status color %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	th, err := ParseTheme([]byte(`{
+		"name": "custom",
+		"base": "solarized",
+		"colors": {"header": "#f80", "bar_high": "#ff000080"},
+		"status": {"waiting": "#00aaff", "default": "#444444"},
+		"thresholds": {"medium": 60, "high": 90}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseTheme() error = %v", err)
+	}
+	if th.Name != "custom" {
+		t.Errorf("Name = %q, want custom", th.Name)
+	}
+	if got := th.Colors.Header; got != (color.NRGBA{0xff, 0x88, 0x00, 0xff}) {
+		t.Errorf("Header = %v", got)
+	}
+	if got := th.Colors.BarHigh; got != (color.NRGBA{0xff, 0x00, 0x00, 0x80}) {
+		t.Errorf("BarHigh = %v", got)
+	}
+	base, _ := BuiltinTheme("solarized")
+	if th.Colors.BG != base.Colors.BG {
+		t.Errorf("BG = %v, want inherited %v", th.Colors.BG, base.Colors.BG)
+	}
+	if th.Thresholds != (Thresholds{Medium: 60, High: 90}) {
+		t.Errorf("Thresholds = %+v", th.Thresholds)
+	}
+	if th.StatusColor("unknown") != (color.NRGBA{0x44, 0x44, 0x44, 0xff}) {
+		t.Errorf("StatusDefault = %v", th.StatusDefault)
+	}
+}
+
+func TestParseThemeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		key     string
+		wantErr error
+	}{
+		{"unknown top-level key", `{"colours": {}}`, "colours", ErrUnknownKey},
+		{"unknown color key", `{"colors": {"bar_mid": "#fff"}}`, "colors.bar_mid", ErrUnknownKey},
+		{"bad color", `{"colors": {"text": "green"}}`, "colors.text", ErrInvalidColor},
+		{"bad hex", `{"colors": {"text": "#12345g"}}`, "colors.text", ErrInvalidColor},
+		{"unknown status", `{"status": {"sleeping": "#fff"}}`, "status.sleeping", ErrUnknownKey},
+		{"unknown base", `{"base": "neon"}`, "base", ErrUnknownTheme},
+		{"threshold range", `{"thresholds": {"high": 120}}`, "thresholds.high", ErrThresholdRange},
+		{"threshold order", `{"thresholds": {"medium": 90, "high": 70}}`, "thresholds.medium", ErrThresholdOrder},
+		{"threshold type", `{"thresholds": {"medium": "50"}}`, "thresholds.medium", ErrInvalidNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTheme([]byte(tt.json))
+			var tErr *ThemeError
+			if !errors.As(err, &tErr) {
+				t.Fatalf("ParseTheme() error = %v, want *ThemeError", err)
+			}
+			if tErr.Key != tt.key {
+				t.Errorf("Key = %q, want %q", tErr.Key, tt.key)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadThemeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mine.json")
+	if err := os.WriteFile(path, []byte(`{"colors": {"bg": "#zzz"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadTheme(path)
+	var tErr *ThemeError
+	if !errors.As(err, &tErr) || tErr.File != path {
+		t.Errorf("LoadTheme() error = %v, want ThemeError naming %s", err, path)
+	}
+
+	if _, err := LoadTheme("neon"); !errors.Is(err, ErrUnknownTheme) {
+		t.Errorf("LoadTheme(neon) error = %v, want ErrUnknownTheme", err)
+	}
+}
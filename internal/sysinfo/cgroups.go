@@ -0,0 +1,166 @@
+package sysinfo
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CgroupKind is the kind of workload a cgroup holds.
+type CgroupKind int
+
+const (
+	CgroupService   CgroupKind = iota // A systemd service or scope
+	CgroupContainer                   // A container
+	CgroupUser                        // A user's slice, with their sessions and user services
+)
+
+// String returns the kind's short name.
+func (k CgroupKind) String() string {
+	switch k {
+	case CgroupContainer:
+		return "container"
+	case CgroupUser:
+		return "user"
+	}
+	return "service"
+}
+
+// CgroupStats is a snapshot of a cgroup v2 group's CPU and memory use.
+type CgroupStats struct {
+	Path string // Relative to the cgroup root
+	Name string // Display name
+	Kind CgroupKind
+
+	CPUUsage      uint64  // Microseconds
+	Periods       uint64  // CPU bandwidth enforcement periods
+	Throttled     uint64  // Periods in which the group was throttled
+	ThrottledUsec uint64  // Time spent throttled
+	CPUMax        float64 // CPUs the group may use, 0 if unlimited
+
+	MemCurrent uint64
+	MemMax     uint64 // 0 if unlimited
+}
+
+// GetCgroups returns the services, containers and user slices of the
+// cgroup v2 hierarchy, with container names from runtime metadata.
+func GetCgroups() ([]CgroupStats, error) {
+	return ReadCgroups(filepath.Join(SysfsRoot, "fs/cgroup"), defaultContainerNames.Name)
+}
+
+// userSlice matches the slice systemd puts a user's processes in.
+var userSlice = regexp.MustCompile(`^user-([0-9]+)\.slice$`)
+
+// ReadCgroups walks the cgroup v2 hierarchy rooted at root and returns the
+// populated groups holding a workload, in walk order: containers, systemd
+// services and scopes, and user slices. Slices other than users' are
+// descended into rather than returned. containerName, if not nil, names
+// containers by runtime and ID.
+func ReadCgroups(root string, containerName func(runtime, id string) string) ([]CgroupStats, error) {
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+		return nil, fmt.Errorf("%s is not a cgroup v2 hierarchy: %w", root, err)
+	}
+
+	var groups []CgroupStats
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == root {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		g := CgroupStats{Path: rel}
+		base := d.Name()
+		if runtime, id := containerID(rel); id != "" && strings.Contains(base, id) {
+			g.Kind, g.Name = CgroupContainer, runtime+": "+id[:12]
+			if containerName != nil {
+				if name := containerName(runtime, id); name != "" {
+					g.Name = runtime + ": " + name
+				}
+			}
+		} else if m := userSlice.FindStringSubmatch(base); m != nil {
+			g.Kind, g.Name = CgroupUser, "user "+m[1]
+			if u, err := user.LookupId(m[1]); err == nil {
+				g.Name = "user " + u.Username
+			}
+		} else if strings.HasSuffix(base, ".service") || strings.HasSuffix(base, ".scope") {
+			g.Kind, g.Name = CgroupService, strings.TrimSuffix(base, ".service")
+		} else {
+			return nil
+		}
+
+		if readKeyValues(filepath.Join(path, "cgroup.events"), " ")["populated"] == "1" {
+			readCgroupStats(path, &g)
+			groups = append(groups, g)
+		}
+		return fs.SkipDir
+	})
+	return groups, err
+}
+
+// readCgroupStats fills the CPU and memory fields of g from the cgroup
+// directory dir. Files of controllers that aren't enabled are missing, and
+// leave their fields zero.
+func readCgroupStats(dir string, g *CgroupStats) {
+	cpu := readKeyValues(filepath.Join(dir, "cpu.stat"), " ")
+	g.CPUUsage, _ = strconv.ParseUint(cpu["usage_usec"], 10, 64)
+	g.Periods, _ = strconv.ParseUint(cpu["nr_periods"], 10, 64)
+	g.Throttled, _ = strconv.ParseUint(cpu["nr_throttled"], 10, 64)
+	g.ThrottledUsec, _ = strconv.ParseUint(cpu["throttled_usec"], 10, 64)
+
+	// cpu.max is "$MAX $PERIOD", with "max" for no limit
+	if fields := strings.Fields(readString(filepath.Join(dir, "cpu.max"))); len(fields) == 2 {
+		quota, err1 := strconv.ParseFloat(fields[0], 64)
+		period, err2 := strconv.ParseFloat(fields[1], 64)
+		if err1 == nil && err2 == nil && period > 0 {
+			g.CPUMax = quota / period
+		}
+	}
+
+	g.MemCurrent, _ = strconv.ParseUint(readString(filepath.Join(dir, "memory.current")), 10, 64)
+	g.MemMax, _ = strconv.ParseUint(readString(filepath.Join(dir, "memory.max")), 10, 64)
+}
+
+// CgroupRate is a cgroup's stats with its CPU use between two samples.
+type CgroupRate struct {
+	CgroupStats
+	CPUPercent   float64 // Share of one CPU
+	ThrottledPct float64 // Share of enforcement periods that were throttled
+}
+
+// CgroupSampler turns successive cgroup snapshots into rates.
+type CgroupSampler struct {
+	prev map[string]CgroupStats
+	at   time.Time
+}
+
+// NewCgroupSampler creates a sampler with no previous sample.
+func NewCgroupSampler() *CgroupSampler {
+	return &CgroupSampler{}
+}
+
+// Sample records stats taken at time at and returns each group's rates
+// since the previous sample, in the order given. Rates are zero on the
+// first sample and for new groups.
+func (s *CgroupSampler) Sample(stats []CgroupStats, at time.Time) []CgroupRate {
+	secs := at.Sub(s.at).Seconds()
+	rates := make([]CgroupRate, len(stats))
+	next := make(map[string]CgroupStats, len(stats))
+	for i, g := range stats {
+		r := CgroupRate{CgroupStats: g}
+		if p, ok := s.prev[g.Path]; ok && secs > 0 {
+			r.CPUPercent = counterRate(p.CPUUsage, g.CPUUsage, secs) / 1e4
+			if g.Periods > p.Periods && g.Throttled >= p.Throttled {
+				r.ThrottledPct = float64(g.Throttled-p.Throttled) / float64(g.Periods-p.Periods) * 100
+			}
+		}
+		rates[i] = r
+		next[g.Path] = g
+	}
+	s.prev, s.at = next, at
+	return rates
+}
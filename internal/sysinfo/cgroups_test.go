@@ -0,0 +1,121 @@
+package sysinfo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCgroups(t *testing.T) {
+	dockerID := strings.Repeat("ab12", 16)
+	podmanID := strings.Repeat("cd34", 16)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"cgroup.controllers":                                        "cpu memory",
+		"init.scope/cgroup.events":                                  "populated 1\nfrozen 0\n",
+		"system.slice/cgroup.events":                                "populated 1\n",
+		"system.slice/nginx.service/cgroup.events":                  "populated 1\n",
+		"system.slice/nginx.service/cpu.stat":                       "usage_usec 5000000\nuser_usec 4000000\nsystem_usec 1000000\nnr_periods 100\nnr_throttled 25\nthrottled_usec 80000\n",
+		"system.slice/nginx.service/cpu.max":                        "150000 100000\n",
+		"system.slice/nginx.service/memory.current":                 "104857600\n",
+		"system.slice/nginx.service/memory.max":                     "max\n",
+		"system.slice/stopped.service/cgroup.events":                "populated 0\n",
+		"system.slice/docker-" + dockerID + ".scope/cgroup.events":  "populated 1\n",
+		"system.slice/docker-" + dockerID + ".scope/memory.max":     "536870912\n",
+		"machine.slice/libpod-" + podmanID + ".scope/cgroup.events": "populated 1\n",
+		"user.slice/user-4242.slice/cgroup.events":                  "populated 1\n",
+		"user.slice/user-4242.slice/session-3.scope/cgroup.events":  "populated 1\n",
+	})
+
+	names := func(runtime, id string) string {
+		if runtime == "docker" && id == dockerID {
+			return "postgres"
+		}
+		return ""
+	}
+	groups, err := ReadCgroups(root, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, g := range groups {
+		got = append(got, fmt.Sprintf("%s %s", g.Kind, g.Name))
+	}
+	want := []string{"service init.scope", "container podman: cd34cd34cd34", "container docker: postgres", "service nginx", "user user 4242"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("groups =\n%q\nwant\n%q", got, want)
+	}
+
+	nginx := groups[3]
+	if nginx.CPUUsage != 5000000 || nginx.Periods != 100 || nginx.Throttled != 25 || nginx.CPUMax != 1.5 {
+		t.Errorf("nginx CPU = %+v", nginx)
+	}
+	if nginx.MemCurrent != 100<<20 || nginx.MemMax != 0 || groups[2].MemMax != 512<<20 {
+		t.Errorf("memory = %d/%d, docker max %d", nginx.MemCurrent, nginx.MemMax, groups[2].MemMax)
+	}
+
+	if _, err := ReadCgroups(t.TempDir(), nil); err == nil {
+		t.Error("ReadCgroups() of a non-cgroup directory returned no error")
+	}
+}
+
+func TestCgroupSampler(t *testing.T) {
+	s := NewCgroupSampler()
+	at := time.Unix(1000, 0)
+	s.Sample([]CgroupStats{{Path: "a.service", CPUUsage: 1e6, Periods: 10, Throttled: 2}}, at)
+	rates := s.Sample([]CgroupStats{
+		{Path: "a.service", CPUUsage: 4e6, Periods: 30, Throttled: 7},
+		{Path: "new.service", CPUUsage: 9e6},
+	}, at.Add(2*time.Second))
+
+	if rates[0].CPUPercent != 150 || rates[0].ThrottledPct != 25 {
+		t.Errorf("a.service rates = %.1f%% CPU, %.1f%% throttled, want 150 and 25", rates[0].CPUPercent, rates[0].ThrottledPct)
+	}
+	if rates[1].CPUPercent != 0 {
+		t.Errorf("new group CPU = %.1f%%, want 0 on its first sample", rates[1].CPUPercent)
+	}
+}
+
+func TestContainerNames(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"var/lib/docker/containers/d1/config.v2.json":                           `{"ID": "d1", "Name": "/web"}`,
+		"var/lib/containers/storage/overlay-containers/containers.json":         `[{"id": "p1", "names": ["db"]}, {"id": "k1", "names": ["k8s_app_pod"]}]`,
+		"var/lib/containers/storage/overlay-containers/k1/userdata/config.json": `{"annotations": {"io.kubernetes.pod.name": "api-7d9f", "io.kubernetes.container.name": "app"}}`,
+		"run/containerd/io.containerd.runtime.v2.task/k8s.io/c1/config.json":    `{"annotations": {"io.kubernetes.cri.sandbox-name": "dns", "io.kubernetes.cri.container-name": "coredns"}}`,
+		"run/containerd/io.containerd.runtime.v2.task/default/c2/config.json":   `{"annotations": {"nerdctl/name": "cache"}}`,
+	})
+
+	c := NewContainerNames(root)
+	tests := []struct {
+		runtime, id, want string
+	}{
+		{"docker", "d1", "web"},
+		{"podman", "p1", "db"},
+		{"crio", "k1", "api-7d9f/app"},
+		{"containerd", "c1", "dns/coredns"},
+		{"containerd", "c2", "cache"},
+		{"docker", "missing", ""},
+	}
+	for _, tt := range tests {
+		if got := c.Name(tt.runtime, tt.id); got != tt.want {
+			t.Errorf("Name(%s, %s) = %q, want %q", tt.runtime, tt.id, got, tt.want)
+		}
+	}
+
+	// Metadata written after the first lookup is found once the miss expires
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	c.Name("docker", "late")
+	writeFiles(t, root, map[string]string{
+		"var/lib/docker/containers/late/config.v2.json": `{"ID": "late", "Name": "/worker"}`,
+	})
+	if got := c.Name("docker", "late"); got != "" {
+		t.Errorf("Name() within the retry delay = %q, want the cached miss", got)
+	}
+	now = now.Add(containerNameRetry)
+	if got := c.Name("docker", "late"); got != "worker" {
+		t.Errorf("Name() after the retry delay = %q, want worker", got)
+	}
+}
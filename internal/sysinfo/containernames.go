@@ -0,0 +1,168 @@
+package sysinfo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContainerNames resolves container IDs to names from the metadata files
+// container runtimes keep on disk, caching the results. Files are only
+// readable by root, so names are missing when running unprivileged.
+type ContainerNames struct {
+	root string // Filesystem root the runtime directories are under
+
+	mu     sync.Mutex
+	names  map[string]string
+	misses map[string]time.Time // When IDs without a name were looked up
+	now    func() time.Time
+}
+
+const (
+	// maxContainerNames bounds the caches; each is emptied when full.
+	maxContainerNames = 1024
+	// containerNameRetry is how long an ID without a name is remembered as
+	// such, since runtimes may write its metadata just after creating it.
+	containerNameRetry = 30 * time.Second
+)
+
+// NewContainerNames creates a resolver reading runtime metadata under the
+// filesystem root, "/" outside tests.
+func NewContainerNames(root string) *ContainerNames {
+	return &ContainerNames{
+		root:   root,
+		names:  make(map[string]string),
+		misses: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+var defaultContainerNames = NewContainerNames("/")
+
+// ContainerName returns the name of a container as found in its runtime's
+// metadata files, or "" if unknown. It suits ProcessGrouper.ContainerName.
+func ContainerName(runtime, id string) string {
+	return defaultContainerNames.Name(runtime, id)
+}
+
+// Name returns the name of the container with the given runtime, as
+// returned for cgroups, and ID, or "" if its metadata doesn't name it.
+// Kubernetes containers are named "pod/container".
+func (c *ContainerNames) Name(runtime, id string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if name, ok := c.names[id]; ok {
+		return name
+	}
+	now := c.now()
+	if at, ok := c.misses[id]; ok && now.Sub(at) < containerNameRetry {
+		return ""
+	}
+
+	var name string
+	switch runtime {
+	case "docker":
+		name = c.dockerName(id)
+	case "podman", "crio":
+		name = c.storageName(id)
+	case "containerd":
+		name = c.containerdName(id)
+	}
+	if name == "" {
+		if len(c.misses) >= maxContainerNames {
+			clear(c.misses)
+		}
+		c.misses[id] = now
+		return ""
+	}
+	delete(c.misses, id)
+	if len(c.names) >= maxContainerNames {
+		clear(c.names)
+	}
+	c.names[id] = name
+	return name
+}
+
+// dockerName reads the name from the container's config.v2.json, where it
+// has a leading slash.
+func (c *ContainerNames) dockerName(id string) string {
+	var config struct{ Name string }
+	if !readJSON(filepath.Join(c.root, "var/lib/docker/containers", id, "config.v2.json"), &config) {
+		return ""
+	}
+	return strings.TrimPrefix(config.Name, "/")
+}
+
+// storageName reads the name of a Podman or CRI-O container from
+// containers/storage: the Kubernetes annotations of its OCI config if it
+// has them, otherwise the first name in containers.json.
+func (c *ContainerNames) storageName(id string) string {
+	dir := filepath.Join(c.root, "var/lib/containers/storage/overlay-containers")
+	var spec ociSpec
+	if readJSON(filepath.Join(dir, id, "userdata/config.json"), &spec) {
+		a := spec.Annotations
+		if name := kubeName(a["io.kubernetes.pod.name"], a["io.kubernetes.container.name"]); name != "" {
+			return name
+		}
+	}
+
+	var containers []struct {
+		ID    string
+		Names []string
+	}
+	if !readJSON(filepath.Join(dir, "containers.json"), &containers) {
+		return ""
+	}
+	for _, ctr := range containers {
+		if ctr.ID == id && len(ctr.Names) > 0 {
+			return ctr.Names[0]
+		}
+	}
+	return ""
+}
+
+// containerdName reads the annotations of the container's OCI bundle,
+// which is kept per namespace while the container runs. CRI sets
+// Kubernetes names and nerdctl its own.
+func (c *ContainerNames) containerdName(id string) string {
+	bundles, _ := filepath.Glob(filepath.Join(c.root, "run/containerd/io.containerd.runtime.v2.task/*", id, "config.json"))
+	for _, path := range bundles {
+		var spec ociSpec
+		if !readJSON(path, &spec) {
+			continue
+		}
+		a := spec.Annotations
+		if name := kubeName(a["io.kubernetes.cri.sandbox-name"], a["io.kubernetes.cri.container-name"]); name != "" {
+			return name
+		}
+		if name := a["nerdctl/name"]; name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// ociSpec is the part of an OCI runtime config holding annotations.
+type ociSpec struct {
+	Annotations map[string]string
+}
+
+// kubeName joins a pod and container name, or returns "" without both.
+func kubeName(pod, container string) string {
+	if pod == "" || container == "" {
+		return ""
+	}
+	return pod + "/" + container
+}
+
+// readJSON decodes a JSON file into v, reporting whether it succeeded.
+func readJSON(path string, v any) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
@@ -0,0 +1,164 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CoreType distinguishes the cores of hybrid CPUs.
+type CoreType int
+
+const (
+	CoreUnknown     CoreType = iota // Not a hybrid CPU, or undetectable
+	CorePerformance                 // Intel P-core, ARM big core
+	CoreEfficiency                  // Intel E-core, ARM LITTLE core
+)
+
+// String returns "P", "E" or "" for an unknown type.
+func (t CoreType) String() string {
+	switch t {
+	case CorePerformance:
+		return "P"
+	case CoreEfficiency:
+		return "E"
+	}
+	return ""
+}
+
+// CPUCore is the live state of one logical CPU.
+type CPUCore struct {
+	CPU       int // Logical CPU number
+	Type      CoreType
+	FreqMHz   float64 // Current frequency, 0 if cpufreq is unavailable
+	MaxMHz    float64
+	Governor  string
+	Throttles uint64 // Thermal and power-limit throttle events since boot, core and package
+}
+
+// throttleCounters are the thermal_throttle files summed into Throttles.
+var throttleCounters = []string{
+	"core_throttle_count",
+	"package_throttle_count",
+	"core_power_limit_count",
+	"package_power_limit_count",
+}
+
+// ReadCPUCores reads each online logical CPU's frequency, governor, core type
+// and throttle counters from a sysfs tree rooted at sysfs, ordered by CPU
+// number.
+func ReadCPUCores(sysfs string) ([]CPUCore, error) {
+	cpuDir := filepath.Join(sysfs, "devices/system/cpu")
+	dirs, err := filepath.Glob(filepath.Join(cpuDir, "cpu[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var cores []CPUCore
+	capacity := make(map[int]int64)
+	for _, dir := range dirs {
+		n, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "cpu"))
+		if err != nil {
+			continue
+		}
+		if online, err := readInt(filepath.Join(dir, "online")); err == nil && online == 0 {
+			continue
+		}
+		c := CPUCore{CPU: n, Governor: readString(filepath.Join(dir, "cpufreq/scaling_governor"))}
+		for _, f := range []string{"cpufreq/scaling_cur_freq", "cpufreq/cpuinfo_cur_freq"} {
+			if khz, err := readInt(filepath.Join(dir, f)); err == nil {
+				c.FreqMHz = float64(khz) / 1000
+				break
+			}
+		}
+		if khz, err := readInt(filepath.Join(dir, "cpufreq/cpuinfo_max_freq")); err == nil {
+			c.MaxMHz = float64(khz) / 1000
+		}
+		for _, f := range throttleCounters {
+			if count, err := readInt(filepath.Join(dir, "thermal_throttle", f)); err == nil {
+				c.Throttles += uint64(count)
+			}
+		}
+		if units, err := readInt(filepath.Join(dir, "cpu_capacity")); err == nil {
+			capacity[n] = units
+		}
+		cores = append(cores, c)
+	}
+	if len(cores) == 0 {
+		return nil, os.ErrNotExist
+	}
+	sort.Slice(cores, func(i, j int) bool { return cores[i].CPU < cores[j].CPU })
+
+	types := hybridTypes(sysfs, capacity)
+	for i := range cores {
+		cores[i].Type = types[cores[i].CPU]
+	}
+	return cores, nil
+}
+
+// hybridTypes returns the core type of each CPU of a hybrid CPU, or an
+// empty map otherwise. Intel hybrid parts register separate cpu_core and
+// cpu_atom PMUs listing their CPUs; ARM big.LITTLE parts report a lower
+// cpu_capacity for the LITTLE cores.
+func hybridTypes(sysfs string, capacity map[int]int64) map[int]CoreType {
+	types := make(map[int]CoreType)
+	pmus := map[string]CoreType{"cpu_core": CorePerformance, "cpu_atom": CoreEfficiency}
+	for pmu, t := range pmus {
+		cpus, err := parseCPUList(readString(filepath.Join(sysfs, "devices", pmu, "cpus")))
+		if err != nil {
+			continue
+		}
+		for _, c := range cpus {
+			types[c] = t
+		}
+	}
+	if len(types) > 0 {
+		return types
+	}
+
+	var maxCap int64
+	for _, c := range capacity {
+		maxCap = max(maxCap, c)
+	}
+	for cpu, c := range capacity {
+		if c < maxCap {
+			types[cpu] = CoreEfficiency
+		}
+	}
+	if len(types) == 0 {
+		return types // All cores alike
+	}
+	for cpu, c := range capacity {
+		if c == maxCap {
+			types[cpu] = CorePerformance
+		}
+	}
+	return types
+}
+
+// parseCPUList parses a kernel CPU list such as "0-7,16,18-19".
+func parseCPUList(s string) ([]int, error) {
+	if s == "" {
+		return nil, os.ErrNotExist
+	}
+	var cpus []int
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil {
+				return nil, err
+			}
+		}
+		for c := first; c <= last; c++ {
+			cpus = append(cpus, c)
+		}
+	}
+	return cpus, nil
+}
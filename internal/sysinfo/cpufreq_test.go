@@ -0,0 +1,67 @@
+package sysinfo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadCPUCores(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"devices/cpu_core/cpus": "0-1\n",
+		"devices/cpu_atom/cpus": "2,4\n",
+
+		"devices/system/cpu/cpu0/cpufreq/scaling_cur_freq":                "4800000\n",
+		"devices/system/cpu/cpu0/cpufreq/cpuinfo_max_freq":                "5400000\n",
+		"devices/system/cpu/cpu0/cpufreq/scaling_governor":                "powersave\n",
+		"devices/system/cpu/cpu0/thermal_throttle/core_throttle_count":    "3\n",
+		"devices/system/cpu/cpu0/thermal_throttle/package_throttle_count": "10\n",
+		"devices/system/cpu/cpu1/cpufreq/scaling_cur_freq":                "800000\n",
+		"devices/system/cpu/cpu1/cpufreq/scaling_governor":                "powersave\n",
+		"devices/system/cpu/cpu2/cpufreq/cpuinfo_cur_freq":                "3100000\n",
+		"devices/system/cpu/cpu3/online":                                  "0\n",
+		"devices/system/cpu/cpu4/cpufreq/scaling_cur_freq":                "1200000\n",
+	})
+
+	cores, err := ReadCPUCores(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CPUCore{
+		{CPU: 0, Type: CorePerformance, FreqMHz: 4800, MaxMHz: 5400, Governor: "powersave", Throttles: 13},
+		{CPU: 1, Type: CorePerformance, FreqMHz: 800, Governor: "powersave"},
+		{CPU: 2, Type: CoreEfficiency, FreqMHz: 3100},
+		{CPU: 4, Type: CoreEfficiency, FreqMHz: 1200},
+	}
+	if !reflect.DeepEqual(cores, want) {
+		t.Errorf("ReadCPUCores() =\n%+v\nwant\n%+v", cores, want)
+	}
+}
+
+func TestHybridTypesFromCapacity(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity map[int]int64
+		want     map[int]CoreType
+	}{
+		{"big.LITTLE", map[int]int64{0: 446, 1: 446, 2: 1024, 3: 1024},
+			map[int]CoreType{0: CoreEfficiency, 1: CoreEfficiency, 2: CorePerformance, 3: CorePerformance}},
+		{"uniform", map[int]int64{0: 1024, 1: 1024}, map[int]CoreType{}},
+		{"none", map[int]int64{}, map[int]CoreType{}},
+	}
+	for _, tt := range tests {
+		if got := hybridTypes(t.TempDir(), tt.capacity); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: hybridTypes() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	got, err := parseCPUList("0-3,8,10-11")
+	if want := []int{0, 1, 2, 3, 8, 10, 11}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCPUList() = %v, %v, want %v", got, err, want)
+	}
+	if _, err := parseCPUList("0-x"); err == nil {
+		t.Error("parseCPUList(\"0-x\") returned no error")
+	}
+}
@@ -0,0 +1,182 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// DiskUsage is the space usage of a mounted filesystem.
+type DiskUsage struct {
+	Mountpoint  string
+	Device      string
+	Fstype      string
+	Total       uint64
+	Used        uint64
+	Free        uint64 // Available to unprivileged users
+	UsedPercent float64
+}
+
+// GetDiskUsage returns the usage of every mounted block-device filesystem,
+// in mount order. Pseudo filesystems such as tmpfs and overlay are skipped.
+func GetDiskUsage() ([]DiskUsage, error) {
+	parts, err := disk.Partitions(false)
+	if err != nil {
+		return nil, err
+	}
+	var usage []DiskUsage
+	for _, p := range parts {
+		u, err := disk.Usage(p.Mountpoint)
+		if err != nil {
+			// Stale network mounts and permission-restricted mounts
+			continue
+		}
+		usage = append(usage, DiskUsage{
+			Mountpoint:  p.Mountpoint,
+			Device:      p.Device,
+			Fstype:      p.Fstype,
+			Total:       u.Total,
+			Used:        u.Used,
+			Free:        u.Free,
+			UsedPercent: u.UsedPercent,
+		})
+	}
+	return usage, nil
+}
+
+// DiskCounters is a snapshot of one block device's cumulative I/O counters.
+type DiskCounters struct {
+	Name       string
+	ReadBytes  uint64
+	WriteBytes uint64
+	Reads      uint64 // Completed read requests
+	Writes     uint64
+	IoTimeMs   uint64 // Time spent with I/O in flight
+}
+
+// GetDiskCounters returns the counters of every whole disk, ordered by name.
+// Partitions are left out so their traffic isn't counted twice.
+func GetDiskCounters() ([]DiskCounters, error) {
+	stats, err := disk.IOCounters()
+	if err != nil {
+		return nil, err
+	}
+	counters := make([]DiskCounters, 0, len(stats))
+	for name, s := range stats {
+		if !IsWholeDisk(SysfsRoot, name) {
+			continue
+		}
+		counters = append(counters, DiskCounters{
+			Name:       name,
+			ReadBytes:  s.ReadBytes,
+			WriteBytes: s.WriteBytes,
+			Reads:      s.ReadCount,
+			Writes:     s.WriteCount,
+			IoTimeMs:   s.IoTime,
+		})
+	}
+	sort.Slice(counters, func(i, j int) bool { return counters[i].Name < counters[j].Name })
+	return counters, nil
+}
+
+// IsWholeDisk reports whether a block device is a disk rather than a
+// partition, using a sysfs tree rooted at sysfs.
+func IsWholeDisk(sysfs, name string) bool {
+	_, err := os.Stat(filepath.Join(sysfs, "block", name))
+	return err == nil
+}
+
+// ReadDiskTemp reads a disk's temperature in Celsius from the hwmon device
+// its driver registers (nvme, or drivetemp for SATA), using a sysfs tree
+// rooted at sysfs. It returns 0 if the disk exposes none.
+func ReadDiskTemp(sysfs, name string) float64 {
+	dev := filepath.Join(sysfs, "block", name, "device")
+	patterns := []string{
+		"hwmon*/temp1_input",       // NVMe controller
+		"hwmon/hwmon*/temp1_input", // drivetemp on a SCSI device
+		"nvme*/hwmon*/temp1_input", // NVMe namespace behind a multipath subsystem
+	}
+	for _, p := range patterns {
+		matches, _ := filepath.Glob(filepath.Join(dev, p))
+		for _, m := range matches {
+			if milli, err := readInt(m); err == nil {
+				return float64(milli) / 1000
+			}
+		}
+	}
+	return 0
+}
+
+// CPUTimes is the cumulative time all CPUs spent in total and waiting for
+// I/O, in seconds.
+type CPUTimes struct {
+	Total  float64
+	Iowait float64
+}
+
+// GetCPUTimes returns cumulative CPU times summed over all CPUs.
+func GetCPUTimes() (CPUTimes, error) {
+	times, err := cpu.Times(false)
+	if err != nil {
+		return CPUTimes{}, err
+	}
+	if len(times) == 0 {
+		return CPUTimes{}, os.ErrNotExist
+	}
+	return CPUTimes{Total: times[0].Total(), Iowait: times[0].Iowait}, nil
+}
+
+// DiskRate is a block device's throughput between two samples.
+type DiskRate struct {
+	Name      string
+	ReadRate  float64 // Bytes per second
+	WriteRate float64
+	ReadIOPS  float64
+	WriteIOPS float64
+	BusyPct   float64 // Share of wall time with I/O in flight
+}
+
+// DiskSampler turns successive counter snapshots into rates.
+type DiskSampler struct {
+	prev  map[string]DiskCounters
+	times CPUTimes
+	at    time.Time
+}
+
+// NewDiskSampler creates a sampler with no previous sample.
+func NewDiskSampler() *DiskSampler {
+	return &DiskSampler{}
+}
+
+// Sample records counters and CPU times taken at time at. It returns each
+// disk's rate since the previous sample, in the order given, and the
+// percentage of CPU time spent waiting for I/O. Rates are zero on the first
+// sample, for new disks, and when a counter went backwards.
+func (s *DiskSampler) Sample(counters []DiskCounters, times CPUTimes, at time.Time) ([]DiskRate, float64) {
+	secs := at.Sub(s.at).Seconds()
+	rates := make([]DiskRate, len(counters))
+	next := make(map[string]DiskCounters, len(counters))
+	for i, c := range counters {
+		r := DiskRate{Name: c.Name}
+		if p, ok := s.prev[c.Name]; ok && secs > 0 {
+			r.ReadRate = counterRate(p.ReadBytes, c.ReadBytes, secs)
+			r.WriteRate = counterRate(p.WriteBytes, c.WriteBytes, secs)
+			r.ReadIOPS = counterRate(p.Reads, c.Reads, secs)
+			r.WriteIOPS = counterRate(p.Writes, c.Writes, secs)
+			r.BusyPct = min(counterRate(p.IoTimeMs, c.IoTimeMs, secs)/10, 100)
+		}
+		rates[i] = r
+		next[c.Name] = c
+	}
+
+	var iowait float64
+	if total := times.Total - s.times.Total; s.prev != nil && total > 0 && times.Iowait >= s.times.Iowait {
+		iowait = (times.Iowait - s.times.Iowait) / total * 100
+	}
+	s.prev, s.times, s.at = next, times, at
+	return rates, iowait
+}
@@ -0,0 +1,70 @@
+package sysinfo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiskSampler(t *testing.T) {
+	start := time.Unix(1000, 0)
+	s := NewDiskSampler()
+
+	rates, iowait := s.Sample([]DiskCounters{{Name: "nvme0n1", ReadBytes: 1 << 20}}, CPUTimes{Total: 100, Iowait: 5}, start)
+	if rates[0] != (DiskRate{Name: "nvme0n1"}) || iowait != 0 {
+		t.Errorf("first sample = %+v, iowait %v, want zero", rates[0], iowait)
+	}
+
+	rates, iowait = s.Sample([]DiskCounters{
+		{Name: "nvme0n1", ReadBytes: 5 << 20, WriteBytes: 2 << 20, Reads: 400, Writes: 100, IoTimeMs: 1500},
+		{Name: "sda", ReadBytes: 100},
+	}, CPUTimes{Total: 116, Iowait: 9}, start.Add(2*time.Second))
+	want := []DiskRate{
+		{Name: "nvme0n1", ReadRate: 2 << 20, WriteRate: 1 << 20, ReadIOPS: 200, WriteIOPS: 50, BusyPct: 75},
+		{Name: "sda"}, // New disk
+	}
+	for i := range want {
+		if rates[i] != want[i] {
+			t.Errorf("rates[%d] = %+v, want %+v", i, rates[i], want[i])
+		}
+	}
+	if iowait != 25 {
+		t.Errorf("iowait = %v, want 25", iowait)
+	}
+
+	// Busy time can't exceed wall time
+	rates, _ = s.Sample([]DiskCounters{{Name: "nvme0n1", IoTimeMs: 4000}}, CPUTimes{Total: 120, Iowait: 9}, start.Add(3*time.Second))
+	if rates[0].BusyPct != 100 || rates[0].ReadRate != 0 {
+		t.Errorf("after reset rates = %+v, want busy 100, read 0", rates[0])
+	}
+}
+
+func TestReadDiskTemp(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"block/nvme0n1/device/hwmon3/temp1_input":       "41850\n",
+		"block/sda/device/hwmon/hwmon5/temp1_input":     "33000\n",
+		"block/nvme1n1/device/nvme1/hwmon4/temp1_input": "52000\n",
+		"block/sdb/device/model":                        "Old SSD\n",
+		"block/nvme0n1/nvme0n1p1/partition":             "1\n",
+	})
+
+	tests := []struct {
+		name string
+		want float64
+	}{
+		{"nvme0n1", 41.85},
+		{"sda", 33},
+		{"nvme1n1", 52},
+		{"sdb", 0},
+		{"missing", 0},
+	}
+	for _, tt := range tests {
+		if got := ReadDiskTemp(root, tt.name); got != tt.want {
+			t.Errorf("ReadDiskTemp(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if !IsWholeDisk(root, "nvme0n1") || IsWholeDisk(root, "nvme0n1p1") {
+		t.Error("IsWholeDisk() confused a disk and its partition")
+	}
+}
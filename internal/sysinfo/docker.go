@@ -0,0 +1,237 @@
+package sysinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ContainerInfo is a running container's state and resource use, as
+// reported by the Docker or Podman API.
+type ContainerInfo struct {
+	ID           string
+	Name         string
+	Image        string
+	State        string // running, restarting, paused, ...
+	Health       string // healthy, unhealthy or starting, "" without a health check
+	RestartCount int
+	StartedAt    time.Time
+
+	HasStats bool   // The usage below could be read
+	CPUUsage uint64 // Nanoseconds
+	MemUsage uint64 // Excluding reclaimable page cache
+	MemLimit uint64 // The host's memory if the container has no limit
+}
+
+// Unhealthy reports whether the container failed its health check or is
+// restarting after exiting.
+func (c *ContainerInfo) Unhealthy() bool {
+	return c.Health == "unhealthy" || c.State == "restarting"
+}
+
+// DockerSocket returns the API socket to use: the socket of DOCKER_HOST if
+// it's a unix:// URL, else the first that exists of the Docker socket and
+// the rootful and rootless Podman sockets. It returns the Docker socket if
+// none exists.
+func DockerSocket() string {
+	if host, ok := strings.CutPrefix(os.Getenv("DOCKER_HOST"), "unix://"); ok {
+		return host
+	}
+	candidates := []string{"/var/run/docker.sock", "/run/podman/podman.sock"}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, "podman/podman.sock"))
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return candidates[0]
+}
+
+// DockerClient queries the Docker Engine API, or Podman's compatible API,
+// over a unix socket.
+type DockerClient struct {
+	http *http.Client
+}
+
+// NewDockerClient creates a client for the API listening on socket.
+func NewDockerClient(socket string) *DockerClient {
+	var d net.Dialer
+	return &DockerClient{http: &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}}}
+}
+
+// Containers returns the running containers, including restarting ones,
+// in the order the API lists them. A container that exits while being
+// queried is left out; one whose stats can't be read has no usage.
+func (c *DockerClient) Containers(ctx context.Context) ([]ContainerInfo, error) {
+	var list []struct {
+		ID    string `json:"Id"`
+		Image string
+	}
+	if err := c.get(ctx, "/containers/json", &list); err != nil {
+		return nil, err
+	}
+
+	var containers []ContainerInfo
+	for _, l := range list {
+		info, err := c.inspect(ctx, l.ID)
+		if errors.Is(err, errNoContainer) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		info.Image = l.Image
+		if err := c.stats(ctx, &info); err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		containers = append(containers, info)
+	}
+	return containers, nil
+}
+
+// inspect returns a container's name and state.
+func (c *DockerClient) inspect(ctx context.Context, id string) (ContainerInfo, error) {
+	var resp struct {
+		ID           string `json:"Id"`
+		Name         string
+		RestartCount int
+		State        struct {
+			Status    string
+			StartedAt time.Time
+			Health    *struct{ Status string }
+		}
+	}
+	if err := c.get(ctx, "/containers/"+url.PathEscape(id)+"/json", &resp); err != nil {
+		return ContainerInfo{}, err
+	}
+	info := ContainerInfo{
+		ID:           resp.ID,
+		Name:         strings.TrimPrefix(resp.Name, "/"),
+		State:        resp.State.Status,
+		RestartCount: resp.RestartCount,
+		StartedAt:    resp.State.StartedAt,
+	}
+	if resp.State.Health != nil {
+		info.Health = resp.State.Health.Status
+	}
+	return info, nil
+}
+
+// stats fills in a container's CPU and memory use from a single stats
+// sample. Memory usage leaves out inactive page cache, as docker stats
+// does; cgroup v1 hosts name it total_inactive_file. Containers that aren't
+// running report zeroed stats, which count as unavailable.
+func (c *DockerClient) stats(ctx context.Context, info *ContainerInfo) error {
+	var resp struct {
+		CPUStats struct {
+			CPUUsage struct {
+				TotalUsage uint64 `json:"total_usage"`
+			} `json:"cpu_usage"`
+		} `json:"cpu_stats"`
+		MemoryStats struct {
+			Usage uint64            `json:"usage"`
+			Limit uint64            `json:"limit"`
+			Stats map[string]uint64 `json:"stats"`
+		} `json:"memory_stats"`
+	}
+	path := "/containers/" + url.PathEscape(info.ID) + "/stats?stream=false&one-shot=true"
+	if err := c.get(ctx, path, &resp); err != nil {
+		return err
+	}
+	mem := resp.MemoryStats
+	cache, ok := mem.Stats["inactive_file"]
+	if !ok {
+		cache = mem.Stats["total_inactive_file"]
+	}
+	info.HasStats = resp.CPUStats.CPUUsage.TotalUsage > 0
+	info.CPUUsage = resp.CPUStats.CPUUsage.TotalUsage
+	info.MemUsage = mem.Usage - min(cache, mem.Usage)
+	info.MemLimit = mem.Limit
+	return nil
+}
+
+// errNoContainer is returned for requests about a container that is gone.
+var errNoContainer = errors.New("no such container")
+
+// get requests path from the API and decodes the JSON response into v.
+// Error responses are returned with the API's message.
+func (c *DockerClient) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct{ Message string }
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(body, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %s", errNoContainer, apiErr.Message)
+		}
+		return fmt.Errorf("container API: %s", apiErr.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// ContainerRate is a container's info with its CPU use between two
+// samples.
+type ContainerRate struct {
+	ContainerInfo
+	CPUPercent float64 // Share of one CPU
+	Restarted  bool    // The restart count rose since the previous sample
+}
+
+// ContainerSampler turns successive container snapshots into rates.
+type ContainerSampler struct {
+	prev map[string]ContainerInfo
+	at   time.Time
+}
+
+// NewContainerSampler creates a sampler with no previous sample.
+func NewContainerSampler() *ContainerSampler {
+	return &ContainerSampler{}
+}
+
+// Sample records containers listed at time at and returns each one's rates
+// since the previous sample, in the order given. CPU rates are zero on the
+// first sample, for new containers, and when either sample has no stats.
+func (s *ContainerSampler) Sample(containers []ContainerInfo, at time.Time) []ContainerRate {
+	secs := at.Sub(s.at).Seconds()
+	rates := make([]ContainerRate, len(containers))
+	next := make(map[string]ContainerInfo, len(containers))
+	for i, c := range containers {
+		r := ContainerRate{ContainerInfo: c}
+		if p, ok := s.prev[c.ID]; ok && secs > 0 {
+			if p.HasStats && c.HasStats {
+				r.CPUPercent = counterRate(p.CPUUsage, c.CPUUsage, secs) / 1e7
+			}
+			r.Restarted = c.RestartCount > p.RestartCount
+		}
+		rates[i] = r
+		next[c.ID] = c
+	}
+	s.prev, s.at = next, at
+	return rates
+}
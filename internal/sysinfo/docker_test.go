@@ -0,0 +1,143 @@
+package sysinfo
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newDockerServer serves canned API responses on a unix socket and returns
+// the socket's path.
+func newDockerServer(t *testing.T, responses map[string]string) string {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			body = `{"message": "No such container"}`
+		} else if strings.HasPrefix(body, "!") {
+			w.WriteHeader(http.StatusInternalServerError)
+			body = body[1:]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	srv.Listener.Close()
+	srv.Listener = l
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return socket
+}
+
+func TestDockerClient(t *testing.T) {
+	socket := newDockerServer(t, map[string]string{
+		"/containers/json": `[
+			{"Id": "aaa", "Names": ["/web"], "Image": "nginx:1.25", "State": "running"},
+			{"Id": "bbb", "Names": ["/db"], "Image": "postgres:16", "State": "restarting"},
+			{"Id": "gone", "Names": ["/job"], "Image": "busybox", "State": "running"}
+		]`,
+		"/containers/aaa/json": `{"Id": "aaa", "Name": "/web", "RestartCount": 0,
+			"State": {"Status": "running", "StartedAt": "2026-10-18T09:00:00.123456789Z", "Health": {"Status": "unhealthy"}}}`,
+		"/containers/bbb/json": `{"Id": "bbb", "Name": "/db", "RestartCount": 4,
+			"State": {"Status": "restarting", "StartedAt": "0001-01-01T00:00:00Z"}}`,
+		"/containers/aaa/stats": `{"cpu_stats": {"cpu_usage": {"total_usage": 5000000000}},
+			"memory_stats": {"usage": 209715200, "limit": 1073741824, "stats": {"inactive_file": 104857600}}}`,
+	})
+
+	containers, err := NewDockerClient(socket).Containers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 2 {
+		t.Fatalf("got %d containers, want 2 (the gone one left out): %+v", len(containers), containers)
+	}
+
+	web, db := containers[0], containers[1]
+	if web.Name != "web" || web.Image != "nginx:1.25" || web.Health != "unhealthy" || !web.Unhealthy() {
+		t.Errorf("web = %+v", web)
+	}
+	if !web.HasStats || web.CPUUsage != 5e9 || web.MemUsage != 100<<20 || web.MemLimit != 1<<30 {
+		t.Errorf("web usage = %d ns, %d/%d bytes", web.CPUUsage, web.MemUsage, web.MemLimit)
+	}
+	if !web.StartedAt.Equal(time.Date(2026, 10, 18, 9, 0, 0, 123456789, time.UTC)) {
+		t.Errorf("web started at %v", web.StartedAt)
+	}
+	// Stats of a restarting container aren't available
+	if db.State != "restarting" || db.RestartCount != 4 || db.Health != "" || !db.Unhealthy() || db.HasStats {
+		t.Errorf("db = %+v", db)
+	}
+}
+
+func TestDockerClientErrors(t *testing.T) {
+	socket := newDockerServer(t, map[string]string{
+		"/containers/json": `!{"message": "permission denied"}`,
+	})
+	_, err := NewDockerClient(socket).Containers(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Containers() error = %v, want the API's message", err)
+	}
+
+	_, err = NewDockerClient(filepath.Join(t.TempDir(), "missing.sock")).Containers(context.Background())
+	if err == nil {
+		t.Error("Containers() without a socket returned no error")
+	}
+}
+
+func TestContainerSampler(t *testing.T) {
+	s := NewContainerSampler()
+	at := time.Unix(1000, 0)
+	s.Sample([]ContainerInfo{{ID: "a", HasStats: true, CPUUsage: 1e9, RestartCount: 1}}, at)
+	rates := s.Sample([]ContainerInfo{{ID: "a", HasStats: true, CPUUsage: 4e9, RestartCount: 2}, {ID: "b", HasStats: true, CPUUsage: 9e9}}, at.Add(2*time.Second))
+
+	if rates[0].CPUPercent != 150 || !rates[0].Restarted {
+		t.Errorf("a = %.1f%% CPU, restarted %v, want 150%% and restarted", rates[0].CPUPercent, rates[0].Restarted)
+	}
+	if rates[1].CPUPercent != 0 || rates[1].Restarted {
+		t.Errorf("new container rates = %+v, want zero", rates[1])
+	}
+}
+
+func TestContainerSamplerStatsGap(t *testing.T) {
+	const list = `[{"Id": "aaa", "Image": "nginx"}]`
+	const inspect = `{"Id": "aaa", "Name": "/web", "State": {"Status": "running"}}`
+	phases := []struct {
+		name  string
+		stats string
+		want  float64
+	}{
+		{"first", `{"cpu_stats": {"cpu_usage": {"total_usage": 1000000000}}}`, 0},
+		{"stats fail", `!{"message": "cgroup gone"}`, 0},
+		{"recovered", `{"cpu_stats": {"cpu_usage": {"total_usage": 90000000000}}}`, 0},
+		{"steady", `{"cpu_stats": {"cpu_usage": {"total_usage": 91000000000}}}`, 100},
+	}
+
+	s := NewContainerSampler()
+	at := time.Unix(1000, 0)
+	for _, p := range phases {
+		socket := newDockerServer(t, map[string]string{
+			"/containers/json":      list,
+			"/containers/aaa/json":  inspect,
+			"/containers/aaa/stats": p.stats,
+		})
+		containers, err := NewDockerClient(socket).Containers(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", p.name, err)
+		}
+		at = at.Add(time.Second)
+		// A sample after a gap in stats must not count the container's
+		// lifetime CPU as one interval's
+		if rates := s.Sample(containers, at); len(rates) != 1 || rates[0].CPUPercent != p.want {
+			t.Errorf("%s: rates = %+v, want %.0f%% CPU", p.name, rates, p.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package sysinfo
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GPUStats is a readout of one GPU's sysfs and hwmon files. Cumulative
+// counters are turned into Busy and PowerW by a GPUSampler.
+type GPUStats struct {
+	Card      string // DRM card name, e.g. card0
+	Driver    string // Kernel driver: amdgpu, i915, xe...
+	Name      string // Product name where the driver exposes one
+	PCISlot   string
+	Busy      float64 // Utilization percent, -1 if not exposed
+	VRAMUsed  uint64  // Bytes, 0 if the GPU has no dedicated memory
+	VRAMTotal uint64
+	CoreMHz   int
+	MemMHz    int
+	PowerW    float64 // Current draw, 0 if unknown
+	PowerCapW float64 // Power limit, 0 if unknown
+	TempC     float64 // 0 if unavailable
+
+	EnergyUJ   uint64               // Cumulative energy for drivers without a power reading
+	EngineBusy map[EngineKey]uint64 // Cumulative busy time per client and engine, in ns
+}
+
+// EngineKey identifies one DRM client's use of one engine class.
+type EngineKey struct {
+	Client string
+	Engine string // render, video, copy...
+}
+
+// GetGPUs returns stats for every GPU.
+func GetGPUs() ([]GPUStats, error) {
+	return ReadGPUs(SysfsRoot, ProcfsRoot)
+}
+
+// cardName matches DRM card directories but not their connectors
+// (card0-DP-1) or render nodes.
+var cardName = regexp.MustCompile(`^card[0-9]+$`)
+
+// fdinfoDrivers are the drivers that report engine busy time in DRM fdinfo
+// rather than a busy percentage in sysfs.
+var fdinfoDrivers = map[string]bool{"i915": true, "xe": true}
+
+// ReadGPUs reads every DRM card from a sysfs tree rooted at sysfs, ordered
+// by card name. Drivers without a busy percentage in sysfs (i915, xe) get
+// per-engine busy counters from the DRM fdinfo of processes under procfs.
+func ReadGPUs(sysfs, procfs string) ([]GPUStats, error) {
+	dirs, err := filepath.Glob(filepath.Join(sysfs, "class/drm/card*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var gpus []GPUStats
+	needEngines := false
+	for _, dir := range dirs {
+		if !cardName.MatchString(filepath.Base(dir)) {
+			continue
+		}
+		g := readGPU(dir)
+		if g.Busy < 0 && fdinfoDrivers[g.Driver] {
+			needEngines = true
+		}
+		gpus = append(gpus, g)
+	}
+	if len(gpus) == 0 {
+		return nil, os.ErrNotExist
+	}
+	sort.Slice(gpus, func(i, j int) bool { return gpus[i].Card < gpus[j].Card })
+
+	if needEngines {
+		engines := readDRMEngines(procfs)
+		for i := range gpus {
+			if gpus[i].Busy < 0 && fdinfoDrivers[gpus[i].Driver] {
+				// An idle GPU has no clients but is still 0% busy
+				gpus[i].EngineBusy = engines[gpus[i].PCISlot]
+				if gpus[i].EngineBusy == nil {
+					gpus[i].EngineBusy = make(map[EngineKey]uint64)
+				}
+			}
+		}
+	}
+	return gpus, nil
+}
+
+// readGPU reads one card directory.
+func readGPU(dir string) GPUStats {
+	dev := filepath.Join(dir, "device")
+	g := GPUStats{Card: filepath.Base(dir), Busy: -1}
+
+	uevent := readKeyValues(filepath.Join(dev, "uevent"), "=")
+	g.Driver = uevent["DRIVER"]
+	g.PCISlot = uevent["PCI_SLOT_NAME"]
+	g.Name = readString(filepath.Join(dev, "product_name"))
+
+	// amdgpu
+	if busy, err := readInt(filepath.Join(dev, "gpu_busy_percent")); err == nil {
+		g.Busy = float64(busy)
+	}
+	if used, err := readInt(filepath.Join(dev, "mem_info_vram_used")); err == nil {
+		g.VRAMUsed = uint64(used)
+	}
+	if total, err := readInt(filepath.Join(dev, "mem_info_vram_total")); err == nil {
+		g.VRAMTotal = uint64(total)
+	}
+	g.CoreMHz = readDPMClock(filepath.Join(dev, "pp_dpm_sclk"))
+	g.MemMHz = readDPMClock(filepath.Join(dev, "pp_dpm_mclk"))
+
+	// i915 and xe report the actual GT frequency instead
+	if g.CoreMHz == 0 {
+		for _, p := range []string{"gt_act_freq_mhz", "device/tile0/gt0/freq0/act_freq"} {
+			if mhz, err := readInt(filepath.Join(dir, p)); err == nil {
+				g.CoreMHz = int(mhz)
+				break
+			}
+		}
+	}
+
+	// hwmon: power in microwatts, energy in microjoules, temperature in
+	// millidegrees
+	hwmons, _ := filepath.Glob(filepath.Join(dev, "hwmon/hwmon*"))
+	for _, h := range hwmons {
+		for _, p := range []string{"power1_average", "power1_input"} {
+			if uw, err := readInt(filepath.Join(h, p)); err == nil && g.PowerW == 0 {
+				g.PowerW = float64(uw) / 1e6
+			}
+		}
+		if uw, err := readInt(filepath.Join(h, "power1_cap")); err == nil && g.PowerCapW == 0 {
+			g.PowerCapW = float64(uw) / 1e6
+		}
+		if uj, err := readInt(filepath.Join(h, "energy1_input")); err == nil && g.EnergyUJ == 0 {
+			g.EnergyUJ = uint64(uj)
+		}
+		if milli, err := readInt(filepath.Join(h, "temp1_input")); err == nil && g.TempC == 0 {
+			g.TempC = float64(milli) / 1000
+		}
+	}
+	return g
+}
+
+// readDPMClock returns the active level of an amdgpu DPM clock table, whose
+// lines look like "1: 1800Mhz *".
+func readDPMClock(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasSuffix(strings.TrimSpace(line), "*") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		mhz, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(fields[1]), "mhz"))
+		if err == nil {
+			return mhz
+		}
+	}
+	return 0
+}
+
+// readDRMEngines sums the DRM fdinfo engine counters of every process under
+// procfs, keyed by PCI slot. Several file descriptors can share one client,
+// so counters are keyed by client ID rather than added up per fd.
+func readDRMEngines(procfs string) map[string]map[EngineKey]uint64 {
+	files, _ := filepath.Glob(filepath.Join(procfs, "[0-9]*/fdinfo/*"))
+	engines := make(map[string]map[EngineKey]uint64)
+	for _, f := range files {
+		info := readKeyValues(f, ":")
+		pdev, client := info["drm-pdev"], info["drm-client-id"]
+		if pdev == "" || client == "" {
+			continue
+		}
+		for k, v := range info {
+			engine, ok := strings.CutPrefix(k, "drm-engine-")
+			if !ok || strings.HasPrefix(engine, "capacity-") {
+				continue
+			}
+			ns, err := strconv.ParseUint(strings.TrimSuffix(v, " ns"), 10, 64)
+			if err != nil {
+				continue
+			}
+			if engines[pdev] == nil {
+				engines[pdev] = make(map[EngineKey]uint64)
+			}
+			engines[pdev][EngineKey{client, engine}] = ns
+		}
+	}
+	return engines
+}
+
+// readKeyValues reads a file of "key<sep>value" lines, as used by uevent and
+// fdinfo files. Unreadable files give an empty map.
+func readKeyValues(path, sep string) map[string]string {
+	values := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return values
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if k, v, ok := strings.Cut(sc.Text(), sep); ok {
+			values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return values
+}
+
+// readString reads a file with surrounding whitespace trimmed, or "".
+func readString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// GPUSampler derives utilization and power from cumulative GPU counters.
+type GPUSampler struct {
+	prev map[string]GPUStats
+	at   time.Time
+}
+
+// NewGPUSampler creates a sampler with no previous sample.
+func NewGPUSampler() *GPUSampler {
+	return &GPUSampler{}
+}
+
+// Sample records stats taken at time at and returns them with Busy set from
+// engine counters and PowerW from energy where the driver reports no direct
+// value. Busy is that of the busiest engine class; it stays -1 on the first
+// sample.
+func (s *GPUSampler) Sample(gpus []GPUStats, at time.Time) []GPUStats {
+	ns := float64(at.Sub(s.at).Nanoseconds())
+	out := make([]GPUStats, len(gpus))
+	next := make(map[string]GPUStats, len(gpus))
+	for i, g := range gpus {
+		p, ok := s.prev[g.Card]
+		if ok && ns > 0 {
+			if g.Busy < 0 && g.EngineBusy != nil {
+				g.Busy = engineBusy(p.EngineBusy, g.EngineBusy, ns)
+			}
+			if g.PowerW == 0 && g.EnergyUJ > 0 {
+				g.PowerW = counterRate(p.EnergyUJ, g.EnergyUJ, ns/1e9) / 1e6
+			}
+		}
+		out[i] = g
+		next[g.Card] = g
+	}
+	s.prev, s.at = next, at
+	return out
+}
+
+// engineBusy returns the busiest engine class's share of ns as a percentage.
+// Clients that appeared or went away since the previous sample are ignored.
+func engineBusy(prev, cur map[EngineKey]uint64, ns float64) float64 {
+	byEngine := make(map[string]uint64)
+	for k, v := range cur {
+		if p, ok := prev[k]; ok && v >= p {
+			byEngine[k.Engine] += v - p
+		}
+	}
+	var busiest float64
+	for _, v := range byEngine {
+		busiest = max(busiest, float64(v)/ns*100)
+	}
+	return min(busiest, 100)
+}
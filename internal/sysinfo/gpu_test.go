@@ -0,0 +1,96 @@
+package sysinfo
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeGPUs writes an amdgpu card0 and an i915 card1 with one DRM client
+// that has the Intel GPU open twice.
+func fakeGPUs(t *testing.T, root string, renderNs, energyUJ string) {
+	t.Helper()
+	writeFiles(t, root, map[string]string{
+		"sys/class/drm/card0/device/uevent":                      "DRIVER=amdgpu\nPCI_SLOT_NAME=0000:03:00.0\n",
+		"sys/class/drm/card0/device/product_name":                "Radeon RX 7800 XT\n",
+		"sys/class/drm/card0/device/gpu_busy_percent":            "37\n",
+		"sys/class/drm/card0/device/mem_info_vram_used":          "3221225472\n",
+		"sys/class/drm/card0/device/mem_info_vram_total":         "17163091968\n",
+		"sys/class/drm/card0/device/pp_dpm_sclk":                 "0: 500Mhz\n1: 2124Mhz *\n",
+		"sys/class/drm/card0/device/pp_dpm_mclk":                 "0: 96Mhz\n3: 1249Mhz *\n",
+		"sys/class/drm/card0/device/hwmon/hwmon4/power1_average": "85000000\n",
+		"sys/class/drm/card0/device/hwmon/hwmon4/power1_cap":     "263000000\n",
+		"sys/class/drm/card0/device/hwmon/hwmon4/temp1_input":    "52000\n",
+		"sys/class/drm/card0-DP-1/status":                        "connected\n",
+
+		"sys/class/drm/card1/device/uevent":                     "DRIVER=i915\nPCI_SLOT_NAME=0000:00:02.0\n",
+		"sys/class/drm/card1/gt_act_freq_mhz":                   "1300\n",
+		"sys/class/drm/card1/device/hwmon/hwmon6/energy1_input": energyUJ,
+
+		"proc/812/fdinfo/14": "pos:\t0\ndrm-driver:\ti915\ndrm-pdev:\t0000:00:02.0\ndrm-client-id:\t7\n" +
+			"drm-engine-render:\t" + renderNs + " ns\ndrm-engine-video:\t0 ns\ndrm-engine-capacity-video:\t2\n",
+		"proc/812/fdinfo/15": "pos:\t0\ndrm-pdev:\t0000:00:02.0\ndrm-client-id:\t7\ndrm-engine-render:\t" + renderNs + " ns\n",
+		"proc/812/fdinfo/3":  "pos:\t0\nflags:\t02000002\n",
+	})
+}
+
+func TestReadGPUs(t *testing.T) {
+	root := t.TempDir()
+	fakeGPUs(t, root, "1000000000", "5000000\n")
+
+	gpus, err := ReadGPUs(filepath.Join(root, "sys"), filepath.Join(root, "proc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gpus) != 2 {
+		t.Fatalf("got %d GPUs, want 2: %+v", len(gpus), gpus)
+	}
+
+	amd := gpus[0]
+	amd.EngineBusy = nil
+	want := GPUStats{
+		Card: "card0", Driver: "amdgpu", Name: "Radeon RX 7800 XT", PCISlot: "0000:03:00.0",
+		Busy: 37, VRAMUsed: 3 << 30, VRAMTotal: 17163091968, CoreMHz: 2124, MemMHz: 1249,
+		PowerW: 85, PowerCapW: 263, TempC: 52,
+	}
+	if !reflect.DeepEqual(amd, want) {
+		t.Errorf("amdgpu = %+v\nwant %+v", amd, want)
+	}
+
+	intel := gpus[1]
+	if intel.Driver != "i915" || intel.Busy != -1 || intel.CoreMHz != 1300 || intel.EnergyUJ != 5000000 {
+		t.Errorf("i915 = %+v", intel)
+	}
+	if len(intel.EngineBusy) != 2 || intel.EngineBusy[EngineKey{"7", "render"}] != 1e9 {
+		t.Errorf("i915 engines = %v", intel.EngineBusy)
+	}
+}
+
+func TestGPUSampler(t *testing.T) {
+	root := t.TempDir()
+	sys, proc := filepath.Join(root, "sys"), filepath.Join(root, "proc")
+	start := time.Unix(1000, 0)
+	s := NewGPUSampler()
+
+	fakeGPUs(t, root, "1000000000", "5000000\n")
+	gpus, _ := ReadGPUs(sys, proc)
+	gpus = s.Sample(gpus, start)
+	if gpus[0].Busy != 37 || gpus[1].Busy != -1 || gpus[1].PowerW != 0 {
+		t.Errorf("first sample busy = %v, %v, power %v", gpus[0].Busy, gpus[1].Busy, gpus[1].PowerW)
+	}
+
+	// 1.5s of render time and 24J over 2s
+	fakeGPUs(t, root, "2500000000", "29000000\n")
+	gpus, _ = ReadGPUs(sys, proc)
+	gpus = s.Sample(gpus, start.Add(2*time.Second))
+	if gpus[1].Busy != 75 {
+		t.Errorf("i915 busy = %v, want 75", gpus[1].Busy)
+	}
+	if gpus[1].PowerW != 12 {
+		t.Errorf("i915 power = %v, want 12", gpus[1].PowerW)
+	}
+	if gpus[0].PowerW != 85 {
+		t.Errorf("amdgpu power = %v, want direct reading 85", gpus[0].PowerW)
+	}
+}
@@ -0,0 +1,186 @@
+package sysinfo
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// MemEventKind is the kind of a MemEvent.
+type MemEventKind int
+
+const (
+	MemEventOOMKill MemEventKind = iota // The OOM killer killed processes
+	MemEventGrowth                      // A process group's memory grew suddenly
+)
+
+// MemEvent is an OOM kill or a sudden memory growth.
+type MemEvent struct {
+	Time   time.Time
+	Kind   MemEventKind
+	Name   string // cgroup of an OOM kill ("" if unknown), or the process group that grew
+	Count  uint64 // Processes killed
+	Growth uint64 // Bytes the group grew by within the growth window
+	RSS    uint64 // The group's RSS after growing
+}
+
+// MemWatcher detects OOM kills and process groups whose memory balloons.
+// OOM kills are counted by /proc/vmstat and attributed to cgroups by their
+// memory.events files, which are only read after a kill.
+type MemWatcher struct {
+	// A group growing by GrowthBytes within GrowthWindow is reported, at
+	// most once per window.
+	GrowthBytes  uint64
+	GrowthWindow time.Duration
+
+	procfs   string
+	cgroupfs string
+
+	started     bool
+	oomKills    uint64
+	cgroupKills map[string]uint64
+	rss         map[string][]rssSample
+	reported    map[string]time.Time
+}
+
+// rssSample is a process group's RSS at one time.
+type rssSample struct {
+	at  time.Time
+	rss uint64
+}
+
+// NewMemWatcher creates a watcher reporting growth of 1 GiB within 30s.
+func NewMemWatcher() *MemWatcher {
+	return &MemWatcher{
+		GrowthBytes:  1 << 30,
+		GrowthWindow: 30 * time.Second,
+		procfs:       ProcfsRoot,
+		cgroupfs:     filepath.Join(SysfsRoot, "fs/cgroup"),
+		rss:          make(map[string][]rssSample),
+		reported:     make(map[string]time.Time),
+	}
+}
+
+// Check looks for OOM kills since the previous check and for groups, as
+// returned by GetTopProcesses, that grew within the growth window. The
+// first check only records a baseline.
+func (w *MemWatcher) Check(groups []ProcessMemInfo, at time.Time) []MemEvent {
+	var events []MemEvent
+	kills, err := strconv.ParseUint(readKeyValues(filepath.Join(w.procfs, "vmstat"), " ")["oom_kill"], 10, 64)
+	switch {
+	case err != nil:
+		// Kernel without the counter
+	case !w.started:
+		w.cgroupKills = w.readCgroupKills()
+	case kills > w.oomKills:
+		events = append(events, w.oomEvents(kills-w.oomKills, at)...)
+	}
+	w.oomKills = kills
+	w.started = true
+
+	return append(events, w.growthEvents(groups, at)...)
+}
+
+// oomEvents attributes n new OOM kills to the cgroups whose kill counters
+// rose, with any remainder reported without a cgroup.
+func (w *MemWatcher) oomEvents(n uint64, at time.Time) []MemEvent {
+	kills := w.readCgroupKills()
+	var events []MemEvent
+	var attributed uint64
+	for path, count := range kills {
+		if prev := w.cgroupKills[path]; count > prev {
+			events = append(events, MemEvent{Time: at, Kind: MemEventOOMKill, Name: path, Count: count - prev})
+			attributed += count - prev
+		}
+	}
+	w.cgroupKills = kills
+	sort.Slice(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+	if attributed < n {
+		events = append(events, MemEvent{Time: at, Kind: MemEventOOMKill, Count: n - attributed})
+	}
+	return events
+}
+
+// readCgroupKills returns the OOM kill count of every cgroup with kills,
+// keyed by path relative to the cgroup root. Kills are counted in the
+// cgroup the process was in, from memory.events.local; without it,
+// memory.events also counts descendants' kills, so only cgroups whose
+// descendants don't account for them all are kept.
+func (w *MemWatcher) readCgroupKills() map[string]uint64 {
+	kills := make(map[string]uint64)
+	hierarchical := make(map[string]uint64)
+	filepath.WalkDir(w.cgroupfs, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(w.cgroupfs, path)
+		if v, ok := readKeyValues(filepath.Join(path, "memory.events.local"), " ")["oom_kill"]; ok {
+			if n, _ := strconv.ParseUint(v, 10, 64); n > 0 {
+				kills[rel] = n
+			}
+		} else if v, ok := readKeyValues(filepath.Join(path, "memory.events"), " ")["oom_kill"]; ok {
+			if n, _ := strconv.ParseUint(v, 10, 64); n > 0 {
+				hierarchical[rel] = n
+			}
+		}
+		return nil
+	})
+
+	// Subtract the kills of each cgroup's children, which include their
+	// own descendants'
+	children := make(map[string]uint64)
+	for path, n := range hierarchical {
+		if parent := filepath.Dir(path); parent != path {
+			children[parent] += n
+		}
+	}
+	for path, n := range hierarchical {
+		if n > children[path] {
+			kills[path] = n - children[path]
+		}
+	}
+	return kills
+}
+
+// growthEvents records each group's RSS and reports those that grew by
+// GrowthBytes within GrowthWindow.
+func (w *MemWatcher) growthEvents(groups []ProcessMemInfo, at time.Time) []MemEvent {
+	var events []MemEvent
+	seen := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		seen[g.Name] = true
+		samples := append(w.rss[g.Name], rssSample{at, g.RSS})
+		for len(samples) > 1 && at.Sub(samples[0].at) > w.GrowthWindow {
+			samples = samples[1:]
+		}
+		w.rss[g.Name] = samples
+
+		low := g.RSS
+		for _, s := range samples {
+			low = min(low, s.rss)
+		}
+		if g.RSS-low < w.GrowthBytes {
+			continue
+		}
+		if last, ok := w.reported[g.Name]; ok && at.Sub(last) < w.GrowthWindow {
+			continue
+		}
+		w.reported[g.Name] = at
+		events = append(events, MemEvent{Time: at, Kind: MemEventGrowth, Name: g.Name, Growth: g.RSS - low, RSS: g.RSS})
+	}
+
+	// Forget groups that are gone
+	for name := range w.rss {
+		if !seen[name] {
+			delete(w.rss, name)
+		}
+	}
+	for name, last := range w.reported {
+		if at.Sub(last) >= w.GrowthWindow {
+			delete(w.reported, name)
+		}
+	}
+	return events
+}
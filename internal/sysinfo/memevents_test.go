@@ -0,0 +1,80 @@
+package sysinfo
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemWatcherOOM(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"proc/vmstat":                                        "pgfault 100\noom_kill 2\n",
+		"cgroup/user.slice/memory.events":                    "low 0\nhigh 0\nmax 4\noom 2\noom_kill 2\n",
+		"cgroup/user.slice/user-1000.slice/memory.events":    "oom 2\noom_kill 2\n",
+		"cgroup/system.slice/memory.events.local":            "oom 0\noom_kill 0\n",
+		"cgroup/system.slice/postgres.service/memory.events": "oom 0\noom_kill 0\n",
+	})
+	w := NewMemWatcher()
+	w.procfs, w.cgroupfs = filepath.Join(root, "proc"), filepath.Join(root, "cgroup")
+	start := time.Unix(1000, 0)
+
+	if events := w.Check(nil, start); len(events) != 0 {
+		t.Errorf("baseline check = %+v, want no events", events)
+	}
+
+	// One kill in a nested cgroup with only hierarchical counters, one in a
+	// cgroup with local counters, and one outside any cgroup
+	writeFiles(t, root, map[string]string{
+		"proc/vmstat":                                     "pgfault 100\noom_kill 5\n",
+		"cgroup/user.slice/memory.events":                 "oom_kill 3\n",
+		"cgroup/user.slice/user-1000.slice/memory.events": "oom_kill 3\n",
+		"cgroup/system.slice/memory.events.local":         "oom_kill 1\n",
+	})
+	events := w.Check(nil, start.Add(time.Second))
+	want := []MemEvent{
+		{Kind: MemEventOOMKill, Name: "system.slice", Count: 1},
+		{Kind: MemEventOOMKill, Name: "user.slice/user-1000.slice", Count: 1},
+		{Kind: MemEventOOMKill, Count: 1},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v, want %+v", events, want)
+	}
+	for i := range want {
+		want[i].Time = start.Add(time.Second)
+		if events[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+
+	if events := w.Check(nil, start.Add(2*time.Second)); len(events) != 0 {
+		t.Errorf("unchanged check = %+v, want no events", events)
+	}
+}
+
+func TestMemWatcherGrowth(t *testing.T) {
+	w := NewMemWatcher()
+	w.procfs = t.TempDir() // No vmstat
+	w.GrowthBytes = 100
+	w.GrowthWindow = 10 * time.Second
+	start := time.Unix(1000, 0)
+
+	steps := []struct {
+		secs int
+		rss  uint64
+		want bool
+	}{
+		{0, 1000, false},
+		{5, 1050, false},
+		{8, 1120, true},   // +120 since 0s
+		{9, 1300, false},  // Already reported within the window
+		{25, 1350, false}, // Low point of the window is now 1300
+		{30, 1500, true},
+	}
+	for _, s := range steps {
+		events := w.Check([]ProcessMemInfo{{Name: "gopls", RSS: s.rss}, {Name: "bash", RSS: 10}}, start.Add(time.Duration(s.secs)*time.Second))
+		if got := len(events) == 1 && events[0].Kind == MemEventGrowth && events[0].Name == "gopls"; got != s.want || len(events) > 1 {
+			t.Errorf("at %ds: events = %+v, want growth %v", s.secs, events, s.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package sysinfo
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PSI is a pressure stall information reading: the percentage of time some
+// or all non-idle tasks were stalled on a resource, averaged over 10, 60
+// and 300 seconds, and the total stall time.
+type PSI struct {
+	Some10, Some60, Some300 float64
+	Full10, Full60, Full300 float64
+	SomeTotal, FullTotal    uint64 // Microseconds
+}
+
+// GetMemoryPressure returns the memory PSI of the whole system.
+func GetMemoryPressure() (*PSI, error) {
+	return ReadPSI(filepath.Join(ProcfsRoot, "pressure/memory"))
+}
+
+// ReadPSI parses a PSI file such as /proc/pressure/memory or a cgroup's
+// memory.pressure.
+func ReadPSI(path string) (*PSI, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	psi := &PSI{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var avg10, avg60, avg300 *float64
+		var total *uint64
+		switch fields[0] {
+		case "some":
+			avg10, avg60, avg300, total = &psi.Some10, &psi.Some60, &psi.Some300, &psi.SomeTotal
+		case "full":
+			avg10, avg60, avg300, total = &psi.Full10, &psi.Full60, &psi.Full300, &psi.FullTotal
+		default:
+			continue
+		}
+		for _, field := range fields[1:] {
+			k, v, _ := strings.Cut(field, "=")
+			switch k {
+			case "avg10":
+				*avg10, _ = strconv.ParseFloat(v, 64)
+			case "avg60":
+				*avg60, _ = strconv.ParseFloat(v, 64)
+			case "avg300":
+				*avg300, _ = strconv.ParseFloat(v, 64)
+			case "total":
+				*total, _ = strconv.ParseUint(v, 10, 64)
+			}
+		}
+	}
+	return psi, sc.Err()
+}
+
+// readMemDetails fills the page cache, zram, zswap, hugepage and pressure
+// fields of info from the procfs and sysfs trees rooted at procfs and sysfs.
+func readMemDetails(info *MemInfo, procfs, sysfs string) {
+	meminfo := readKeyValues(filepath.Join(procfs, "meminfo"), ":")
+	kb := func(key string) uint64 {
+		v, _ := strconv.ParseUint(strings.TrimSuffix(meminfo[key], " kB"), 10, 64)
+		return v * 1024
+	}
+	info.Cached = kb("Cached")
+	info.Buffers = kb("Buffers")
+	info.Dirty = kb("Dirty")
+	info.Writeback = kb("Writeback")
+	info.ZswapPool = kb("Zswap")
+	info.ZswapStored = kb("Zswapped")
+
+	// Hugepage counts are in pages of Hugepagesize
+	pages := func(key string) uint64 {
+		v, _ := strconv.ParseUint(meminfo[key], 10, 64)
+		return v
+	}
+	size := kb("Hugepagesize")
+	info.HugeTotal = pages("HugePages_Total") * size
+	info.HugeUsed = (pages("HugePages_Total") - min(pages("HugePages_Free"), pages("HugePages_Total"))) * size
+
+	// mm_stat starts with the original and compressed data sizes and the
+	// memory used to store them
+	stats, _ := filepath.Glob(filepath.Join(sysfs, "block/zram*/mm_stat"))
+	for _, path := range stats {
+		fields := strings.Fields(readString(path))
+		if len(fields) < 3 {
+			continue
+		}
+		orig, _ := strconv.ParseUint(fields[0], 10, 64)
+		compr, _ := strconv.ParseUint(fields[1], 10, 64)
+		used, _ := strconv.ParseUint(fields[2], 10, 64)
+		info.ZramOrig += orig
+		info.ZramCompr += compr
+		info.ZramUsed += used
+	}
+
+	info.Pressure, _ = ReadPSI(filepath.Join(procfs, "pressure/memory"))
+}
+
+// ZramRatio returns the compression ratio of data stored in zram, or 0
+// without zram data.
+func (m *MemInfo) ZramRatio() float64 {
+	if m.ZramCompr == 0 {
+		return 0
+	}
+	return float64(m.ZramOrig) / float64(m.ZramCompr)
+}
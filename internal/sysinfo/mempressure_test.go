@@ -0,0 +1,59 @@
+package sysinfo
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadMemDetails(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"proc/meminfo": "MemTotal:       32768000 kB\n" +
+			"Buffers:          102400 kB\n" +
+			"Cached:          4096000 kB\n" +
+			"Dirty:              512 kB\n" +
+			"Writeback:            0 kB\n" +
+			"Zswap:            20480 kB\n" +
+			"Zswapped:         81920 kB\n" +
+			"HugePages_Total:      8\n" +
+			"HugePages_Free:       3\n" +
+			"Hugepagesize:      2048 kB\n",
+		"proc/pressure/memory": "some avg10=12.50 avg60=4.00 avg300=1.00 total=987654\n" +
+			"full avg10=2.25 avg60=0.50 avg300=0.10 total=12345\n",
+		"sys/block/zram0/mm_stat": "3000000 1000000 1200000 0 1200000 10 0 0 0\n",
+		"sys/block/zram1/mm_stat": "600000 200000 300000 0 300000 0 0 0 0\n",
+	})
+
+	var info MemInfo
+	readMemDetails(&info, filepath.Join(root, "proc"), filepath.Join(root, "sys"))
+	want := MemInfo{
+		Buffers:     102400 << 10,
+		Cached:      4096000 << 10,
+		Dirty:       512 << 10,
+		ZswapPool:   20480 << 10,
+		ZswapStored: 81920 << 10,
+		HugeTotal:   16 << 20,
+		HugeUsed:    10 << 20,
+		ZramOrig:    3600000,
+		ZramCompr:   1200000,
+		ZramUsed:    1500000,
+	}
+	psi := info.Pressure
+	info.Pressure = nil
+	if info != want {
+		t.Errorf("readMemDetails() =\n%+v\nwant\n%+v", info, want)
+	}
+	if psi == nil || *psi != (PSI{Some10: 12.5, Some60: 4, Some300: 1, Full10: 2.25, Full60: 0.5, Full300: 0.1, SomeTotal: 987654, FullTotal: 12345}) {
+		t.Errorf("Pressure = %+v", psi)
+	}
+	if r := want.ZramRatio(); r != 3 {
+		t.Errorf("ZramRatio() = %v, want 3", r)
+	}
+
+	// Kernels without PSI or zram
+	info = MemInfo{}
+	readMemDetails(&info, t.TempDir(), t.TempDir())
+	if info.Pressure != nil || info.ZramRatio() != 0 {
+		t.Errorf("empty tree gave %+v", info)
+	}
+}
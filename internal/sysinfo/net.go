@@ -0,0 +1,209 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// NetCounters is a snapshot of one interface's cumulative counters.
+type NetCounters struct {
+	Name      string
+	BytesRecv uint64
+	BytesSent uint64
+	ErrsRecv  uint64
+	ErrsSent  uint64
+}
+
+// GetNetCounters returns the counters of every network interface.
+func GetNetCounters() ([]NetCounters, error) {
+	stats, err := net.IOCounters(true)
+	if err != nil {
+		return nil, err
+	}
+	counters := make([]NetCounters, len(stats))
+	for i, s := range stats {
+		counters[i] = NetCounters{
+			Name:      s.Name,
+			BytesRecv: s.BytesRecv,
+			BytesSent: s.BytesSent,
+			ErrsRecv:  s.Errin,
+			ErrsSent:  s.Errout,
+		}
+	}
+	return counters, nil
+}
+
+// NetInterface describes a network interface's configuration.
+type NetInterface struct {
+	Name      string
+	Up        bool // Administratively up
+	Loopback  bool
+	Link      string   // Operational state: up, down, dormant, unknown...
+	SpeedMbps int      // Link speed, 0 if unknown
+	Addrs     []string // Addresses in CIDR form, IPv4 first
+}
+
+// GetNetInterfaces returns all network interfaces with link state read
+// from sysfs where available.
+func GetNetInterfaces() ([]NetInterface, error) {
+	stats, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	ifaces := make([]NetInterface, len(stats))
+	for i, s := range stats {
+		iface := NetInterface{Name: s.Name}
+		for _, f := range s.Flags {
+			switch f {
+			case "up":
+				iface.Up = true
+			case "loopback":
+				iface.Loopback = true
+			}
+		}
+		for _, a := range s.Addrs {
+			iface.Addrs = append(iface.Addrs, a.Addr)
+		}
+		sort.SliceStable(iface.Addrs, func(i, j int) bool {
+			return !strings.Contains(iface.Addrs[i], ":") && strings.Contains(iface.Addrs[j], ":")
+		})
+		iface.Link, iface.SpeedMbps = ReadNetLink(SysfsRoot, s.Name)
+		if iface.Link == "" {
+			iface.Link = "down"
+			if iface.Up {
+				iface.Link = "up"
+			}
+		}
+		ifaces[i] = iface
+	}
+	return ifaces, nil
+}
+
+// ReadNetLink reads an interface's operational state and speed from a sysfs
+// tree rooted at sysfs. It returns an empty state if sysfs has none.
+func ReadNetLink(sysfs, name string) (state string, speedMbps int) {
+	dir := filepath.Join(sysfs, "class/net", name)
+	if data, err := os.ReadFile(filepath.Join(dir, "operstate")); err == nil {
+		state = strings.TrimSpace(string(data))
+	}
+	// Reading speed fails with EINVAL while the link is down
+	if speed, err := readInt(filepath.Join(dir, "speed")); err == nil && speed > 0 {
+		speedMbps = int(speed)
+	}
+	return state, speedMbps
+}
+
+// NetRate is an interface's throughput between two samples.
+type NetRate struct {
+	Name    string
+	RxRate  float64 // Bytes per second
+	TxRate  float64
+	RxTotal uint64 // Bytes since boot
+	TxTotal uint64
+	Errors  uint64 // New receive and transmit errors
+}
+
+// NetSampler turns successive counter snapshots into rates.
+type NetSampler struct {
+	prev map[string]NetCounters
+	at   time.Time
+}
+
+// NewNetSampler creates a sampler with no previous sample.
+func NewNetSampler() *NetSampler {
+	return &NetSampler{}
+}
+
+// Sample records counters taken at time at and returns each interface's
+// rate since the previous sample, in the order given. Rates are zero on the
+// first sample, for new interfaces, and when a counter went backwards
+// (driver reset or wrap).
+func (s *NetSampler) Sample(counters []NetCounters, at time.Time) []NetRate {
+	secs := at.Sub(s.at).Seconds()
+	rates := make([]NetRate, len(counters))
+	next := make(map[string]NetCounters, len(counters))
+	for i, c := range counters {
+		r := NetRate{Name: c.Name, RxTotal: c.BytesRecv, TxTotal: c.BytesSent}
+		if p, ok := s.prev[c.Name]; ok && secs > 0 {
+			r.RxRate = counterRate(p.BytesRecv, c.BytesRecv, secs)
+			r.TxRate = counterRate(p.BytesSent, c.BytesSent, secs)
+			if c.ErrsRecv+c.ErrsSent >= p.ErrsRecv+p.ErrsSent {
+				r.Errors = c.ErrsRecv + c.ErrsSent - p.ErrsRecv - p.ErrsSent
+			}
+		}
+		rates[i] = r
+		next[c.Name] = c
+	}
+	s.prev, s.at = next, at
+	return rates
+}
+
+// counterRate returns the per-second increase of a counter.
+func counterRate(prev, cur uint64, secs float64) float64 {
+	if cur < prev {
+		return 0
+	}
+	return float64(cur-prev) / secs
+}
+
+// NetTalker is a process group with open network connections.
+type NetTalker struct {
+	Name  string
+	Conns int // Established TCP connections and connected UDP sockets
+}
+
+// GetTopTalkers returns the process groups with the most network
+// connections. Per-process byte counts aren't exposed by the kernel without
+// eBPF, so connection count stands in for traffic. Processes of other users
+// are only visible when running as root.
+func GetTopTalkers(n int) ([]NetTalker, error) {
+	conns, err := net.ConnectionsWithoutUids("inet")
+	if err != nil {
+		return nil, err
+	}
+
+	byPID := make(map[int32]int)
+	for _, c := range conns {
+		if c.Pid == 0 || c.Raddr.Port == 0 {
+			continue
+		}
+		if c.Status != "ESTABLISHED" && c.Status != "NONE" {
+			continue
+		}
+		byPID[c.Pid]++
+	}
+
+	groups := make(map[string]int)
+	for pid, count := range byPID {
+		p, err := process.NewProcess(pid)
+		if err != nil {
+			continue
+		}
+		name, err := p.Name()
+		if err != nil {
+			continue
+		}
+		groups[getProcessGroup(name)] += count
+	}
+
+	talkers := make([]NetTalker, 0, len(groups))
+	for name, count := range groups {
+		talkers = append(talkers, NetTalker{Name: name, Conns: count})
+	}
+	sort.Slice(talkers, func(i, j int) bool {
+		if talkers[i].Conns != talkers[j].Conns {
+			return talkers[i].Conns > talkers[j].Conns
+		}
+		return talkers[i].Name < talkers[j].Name
+	})
+	if len(talkers) > n {
+		talkers = talkers[:n]
+	}
+	return talkers, nil
+}
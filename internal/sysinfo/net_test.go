@@ -0,0 +1,62 @@
+package sysinfo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNetSampler(t *testing.T) {
+	start := time.Unix(1000, 0)
+	s := NewNetSampler()
+
+	rates := s.Sample([]NetCounters{{Name: "eth0", BytesRecv: 1000, BytesSent: 500}}, start)
+	if rates[0].RxRate != 0 || rates[0].TxRate != 0 {
+		t.Errorf("first sample rates = %+v, want zero", rates[0])
+	}
+
+	rates = s.Sample([]NetCounters{
+		{Name: "eth0", BytesRecv: 5000, BytesSent: 700, ErrsRecv: 2},
+		{Name: "wg0", BytesRecv: 100},
+	}, start.Add(2*time.Second))
+	want := []NetRate{
+		{Name: "eth0", RxRate: 2000, TxRate: 100, RxTotal: 5000, TxTotal: 700, Errors: 2},
+		{Name: "wg0", RxTotal: 100}, // New interface
+	}
+	for i := range want {
+		if rates[i] != want[i] {
+			t.Errorf("rates[%d] = %+v, want %+v", i, rates[i], want[i])
+		}
+	}
+
+	// Counter reset after a driver reload
+	rates = s.Sample([]NetCounters{{Name: "eth0", BytesRecv: 10, BytesSent: 800}}, start.Add(3*time.Second))
+	if rates[0].RxRate != 0 || rates[0].TxRate != 100 {
+		t.Errorf("after reset rates = %+v, want rx 0, tx 100", rates[0])
+	}
+}
+
+func TestReadNetLink(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"class/net/eth0/operstate":  "up\n",
+		"class/net/eth0/speed":      "1000\n",
+		"class/net/wlan0/operstate": "dormant\n",
+		"class/net/wlan0/speed":     "-1\n",
+	})
+
+	tests := []struct {
+		name  string
+		state string
+		speed int
+	}{
+		{"eth0", "up", 1000},
+		{"wlan0", "dormant", 0},
+		{"missing", "", 0},
+	}
+	for _, tt := range tests {
+		state, speed := ReadNetLink(root, tt.name)
+		if state != tt.state || speed != tt.speed {
+			t.Errorf("ReadNetLink(%s) = %q, %d, want %q, %d", tt.name, state, speed, tt.state, tt.speed)
+		}
+	}
+}
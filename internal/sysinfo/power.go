@@ -0,0 +1,224 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Battery is a readout of one system battery.
+type Battery struct {
+	Name     string  // power_supply name, e.g. BAT0
+	Model    string  // Model name where the driver exposes one
+	Status   string  // Charging, Discharging, Full or Not charging
+	Capacity float64 // Charge percent
+
+	EnergyWh      float64       // Energy stored now
+	FullWh        float64       // Energy stored when full
+	DesignWh      float64       // Energy stored when full, as designed; 0 if unknown
+	PowerW        float64       // Charge or discharge rate, 0 if unknown
+	TimeRemaining time.Duration // To empty when discharging, to full when charging; 0 if unknown
+}
+
+// Health returns the full capacity as a percentage of the design capacity,
+// or 0 if unknown.
+func (b *Battery) Health() float64 {
+	if b.DesignWh == 0 {
+		return 0
+	}
+	return b.FullWh / b.DesignWh * 100
+}
+
+// PowerSupplies are a machine's batteries and external power.
+type PowerSupplies struct {
+	Batteries []Battery // Ordered by name
+	HasAC     bool      // A mains or USB supply is present
+	ACOnline  bool      // Running on external power
+}
+
+// GetPowerSupplies returns the batteries and AC state.
+func GetPowerSupplies() (PowerSupplies, error) {
+	return ReadPowerSupplies(SysfsRoot)
+}
+
+// ReadPowerSupplies reads the power supply class of a sysfs tree rooted at
+// sysfs. Batteries of peripherals such as mice are skipped. It returns
+// os.ErrNotExist when there is no battery or AC supply, as on desktops
+// without a UPS driver.
+func ReadPowerSupplies(sysfs string) (PowerSupplies, error) {
+	dirs, err := filepath.Glob(filepath.Join(sysfs, "class/power_supply/*"))
+	if err != nil {
+		return PowerSupplies{}, err
+	}
+
+	var ps PowerSupplies
+	for _, dir := range dirs {
+		switch readString(filepath.Join(dir, "type")) {
+		case "Mains", "USB":
+			ps.HasAC = true
+			if online, err := readInt(filepath.Join(dir, "online")); err == nil && online == 1 {
+				ps.ACOnline = true
+			}
+		case "Battery":
+			if readString(filepath.Join(dir, "scope")) == "Device" {
+				continue
+			}
+			ps.Batteries = append(ps.Batteries, readBattery(dir))
+		}
+	}
+	if len(ps.Batteries) == 0 && !ps.HasAC {
+		return ps, os.ErrNotExist
+	}
+	sort.Slice(ps.Batteries, func(i, j int) bool { return ps.Batteries[i].Name < ps.Batteries[j].Name })
+	return ps, nil
+}
+
+// readBattery reads one battery directory. Drivers report energy in µWh
+// and power in µW, or charge in µAh and current in µA, which are converted
+// with the voltage.
+func readBattery(dir string) Battery {
+	b := Battery{
+		Name:   filepath.Base(dir),
+		Model:  readString(filepath.Join(dir, "model_name")),
+		Status: readString(filepath.Join(dir, "status")),
+	}
+	micro := func(name string) float64 {
+		v, err := readInt(filepath.Join(dir, name))
+		if err != nil {
+			return 0
+		}
+		// Some drivers report a negative current while discharging
+		return max(float64(v), -float64(v)) / 1e6
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "energy_now")); err == nil {
+		b.EnergyWh, b.FullWh, b.DesignWh = micro("energy_now"), micro("energy_full"), micro("energy_full_design")
+		b.PowerW = micro("power_now")
+	} else {
+		volts := micro("voltage_now")
+		if volts == 0 {
+			volts = micro("voltage_min_design")
+		}
+		b.EnergyWh, b.FullWh, b.DesignWh = micro("charge_now")*volts, micro("charge_full")*volts, micro("charge_full_design")*volts
+		b.PowerW = micro("current_now") * micro("voltage_now")
+	}
+
+	if capacity, err := readInt(filepath.Join(dir, "capacity")); err == nil {
+		b.Capacity = float64(capacity)
+	} else if b.FullWh > 0 {
+		b.Capacity = min(b.EnergyWh/b.FullWh*100, 100)
+	}
+
+	if b.PowerW > 0 {
+		hours := 0.0
+		switch b.Status {
+		case "Discharging":
+			hours = b.EnergyWh / b.PowerW
+		case "Charging":
+			hours = max(b.FullWh-b.EnergyWh, 0) / b.PowerW
+		}
+		b.TimeRemaining = time.Duration(hours * float64(time.Hour)).Round(time.Minute)
+	}
+	return b
+}
+
+// RAPLZone is a readout of one RAPL power domain: a CPU package or a part
+// of it, such as its cores, uncore (integrated GPU) or DRAM.
+type RAPLZone struct {
+	Name     string // Domain name, e.g. package-0 or core
+	Path     string // Powercap zone directory, identifying the zone across samples
+	EnergyUJ uint64 // Cumulative energy, wrapping at MaxUJ
+	MaxUJ    uint64
+	LimitW   float64 // Long-term power limit (PL1), 0 if unknown
+}
+
+// GetRAPL returns the RAPL zones.
+func GetRAPL() ([]RAPLZone, error) {
+	return ReadRAPL(SysfsRoot)
+}
+
+// ReadRAPL reads the intel-rapl powercap zones of a sysfs tree rooted at
+// sysfs, used on both Intel and AMD, packages first with their subzones
+// after each. Energy counters are only readable by root on recent kernels;
+// unreadable zones are skipped, and os.ErrNotExist returned if none is
+// left.
+func ReadRAPL(sysfs string) ([]RAPLZone, error) {
+	packages, err := filepath.Glob(filepath.Join(sysfs, "class/powercap/intel-rapl:[0-9]"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(packages)
+
+	var zones []RAPLZone
+	for _, pkg := range packages {
+		subzones, _ := filepath.Glob(pkg + ":[0-9]*")
+		sort.Strings(subzones)
+		for _, dir := range append([]string{pkg}, subzones...) {
+			energy, err := readInt(filepath.Join(dir, "energy_uj"))
+			if err != nil {
+				continue
+			}
+			z := RAPLZone{Name: readString(filepath.Join(dir, "name")), Path: dir, EnergyUJ: uint64(energy)}
+			if maxUJ, err := readInt(filepath.Join(dir, "max_energy_range_uj")); err == nil {
+				z.MaxUJ = uint64(maxUJ)
+			}
+			if strings.HasPrefix(readString(filepath.Join(dir, "constraint_0_name")), "long_term") {
+				if limit, err := readInt(filepath.Join(dir, "constraint_0_power_limit_uw")); err == nil {
+					z.LimitW = float64(limit) / 1e6
+				}
+			}
+			zones = append(zones, z)
+		}
+	}
+	if len(zones) == 0 {
+		return nil, os.ErrNotExist
+	}
+	return zones, nil
+}
+
+// RAPLPower is a RAPL zone's average power between two samples.
+type RAPLPower struct {
+	Name   string
+	Watts  float64
+	LimitW float64
+}
+
+// RAPLSampler turns successive RAPL readouts into power.
+type RAPLSampler struct {
+	prev map[string]uint64
+	at   time.Time
+}
+
+// NewRAPLSampler creates a sampler with no previous sample.
+func NewRAPLSampler() *RAPLSampler {
+	return &RAPLSampler{}
+}
+
+// Sample records zones read at time at and returns each zone's power since
+// the previous sample, in the order given. Power is zero on the first
+// sample and for new zones.
+func (s *RAPLSampler) Sample(zones []RAPLZone, at time.Time) []RAPLPower {
+	secs := at.Sub(s.at).Seconds()
+	power := make([]RAPLPower, len(zones))
+	next := make(map[string]uint64, len(zones))
+	for i, z := range zones {
+		p := RAPLPower{Name: z.Name, LimitW: z.LimitW}
+		if prev, ok := s.prev[z.Path]; ok && secs > 0 {
+			var used uint64
+			switch {
+			case z.EnergyUJ >= prev:
+				used = z.EnergyUJ - prev
+			case z.MaxUJ > prev:
+				// The counter wrapped
+				used = z.MaxUJ - prev + z.EnergyUJ
+			}
+			p.Watts = float64(used) / 1e6 / secs
+		}
+		power[i] = p
+		next[z.Path] = z.EnergyUJ
+	}
+	s.prev, s.at = next, at
+	return power
+}
@@ -0,0 +1,105 @@
+package sysinfo
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReadPowerSupplies(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		// Energy-reporting battery, discharging
+		"class/power_supply/BAT0/type":               "Battery\n",
+		"class/power_supply/BAT0/status":             "Discharging\n",
+		"class/power_supply/BAT0/model_name":         "5B10W13930\n",
+		"class/power_supply/BAT0/capacity":           "40\n",
+		"class/power_supply/BAT0/energy_now":         "20000000\n",
+		"class/power_supply/BAT0/energy_full":        "50000000\n",
+		"class/power_supply/BAT0/energy_full_design": "57000000\n",
+		"class/power_supply/BAT0/power_now":          "8000000\n",
+		// Charge-reporting battery with a negative current, charging
+		"class/power_supply/BAT1/type":               "Battery\n",
+		"class/power_supply/BAT1/status":             "Charging\n",
+		"class/power_supply/BAT1/charge_now":         "1000000\n",
+		"class/power_supply/BAT1/charge_full":        "4000000\n",
+		"class/power_supply/BAT1/charge_full_design": "4000000\n",
+		"class/power_supply/BAT1/current_now":        "-2000000\n",
+		"class/power_supply/BAT1/voltage_now":        "12000000\n",
+		// A wireless mouse
+		"class/power_supply/hidpp_battery_0/type":               "Battery\n",
+		"class/power_supply/hidpp_battery_0/scope":              "Device\n",
+		"class/power_supply/AC/type":                            "Mains\n",
+		"class/power_supply/AC/online":                          "0\n",
+		"class/power_supply/ucsi-source-psy-USBC000:001/type":   "USB\n",
+		"class/power_supply/ucsi-source-psy-USBC000:001/online": "0\n",
+	})
+
+	ps, err := ReadPowerSupplies(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ps.HasAC || ps.ACOnline || len(ps.Batteries) != 2 {
+		t.Fatalf("supplies = %+v", ps)
+	}
+
+	bat0 := ps.Batteries[0]
+	if bat0.Capacity != 40 || bat0.PowerW != 8 || bat0.TimeRemaining != 2*time.Hour+30*time.Minute {
+		t.Errorf("BAT0 = %.0f%%, %.1f W, %v left", bat0.Capacity, bat0.PowerW, bat0.TimeRemaining)
+	}
+	if h := bat0.Health(); h < 87.7 || h > 87.8 {
+		t.Errorf("BAT0 health = %.2f%%, want 87.72%%", h)
+	}
+
+	bat1 := ps.Batteries[1]
+	if bat1.Capacity != 25 || bat1.EnergyWh != 12 || bat1.PowerW != 24 || bat1.TimeRemaining != 90*time.Minute {
+		t.Errorf("BAT1 = %.0f%%, %.1f Wh, %.1f W, %v to full", bat1.Capacity, bat1.EnergyWh, bat1.PowerW, bat1.TimeRemaining)
+	}
+
+	if _, err := ReadPowerSupplies(t.TempDir()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadPowerSupplies() without supplies error = %v, want ErrNotExist", err)
+	}
+}
+
+func TestRAPL(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"class/powercap/intel-rapl:0/name":                        "package-0\n",
+		"class/powercap/intel-rapl:0/energy_uj":                   "262000000\n",
+		"class/powercap/intel-rapl:0/max_energy_range_uj":         "262143328850\n",
+		"class/powercap/intel-rapl:0/constraint_0_name":           "long_term\n",
+		"class/powercap/intel-rapl:0/constraint_0_power_limit_uw": "28000000\n",
+		"class/powercap/intel-rapl:0:0/name":                      "core\n",
+		"class/powercap/intel-rapl:0:0/energy_uj":                 "100000000\n",
+		"class/powercap/intel-rapl:0:0/max_energy_range_uj":       "262143328850\n",
+		// Unreadable for unprivileged users on recent kernels
+		"class/powercap/intel-rapl:0:1/name": "uncore\n",
+	})
+
+	zones, err := ReadRAPL(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != 2 || zones[0].Name != "package-0" || zones[0].LimitW != 28 || zones[1].Name != "core" {
+		t.Fatalf("zones = %+v", zones)
+	}
+
+	s := NewRAPLSampler()
+	at := time.Unix(1000, 0)
+	s.Sample(zones, at)
+	zones[0].EnergyUJ += 30000000
+	zones[1].EnergyUJ = 5000000 // Wrapped
+	power := s.Sample(zones, at.Add(2*time.Second))
+	if power[0].Watts != 15 || power[0].LimitW != 28 {
+		t.Errorf("package = %.2f W of %.0f, want 15 of 28", power[0].Watts, power[0].LimitW)
+	}
+	wrapped := (262143328850 - 100000000 + 5000000) / 1e6 / 2
+	if power[1].Watts != wrapped {
+		t.Errorf("core after wrap = %.2f W, want %.2f", power[1].Watts, wrapped)
+	}
+
+	if _, err := ReadRAPL(t.TempDir()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadRAPL() without zones error = %v, want ErrNotExist", err)
+	}
+}
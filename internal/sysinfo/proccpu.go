@@ -0,0 +1,43 @@
+package sysinfo
+
+import "sort"
+
+// ProcessCPUInfo holds CPU usage for a process group.
+type ProcessCPUInfo struct {
+	Name    string
+	Percent float64 // Share of one CPU, so a group can exceed 100
+	Count   int
+}
+
+// GetTopCPUProcesses returns the top N process groups by CPU usage since
+// the previous process scan. Usage is zero on the first scan.
+func GetTopCPUProcesses(n int) ([]ProcessCPUInfo, error) {
+	return defaultGrouper.TopCPU(n)
+}
+
+// groupByCPU aggregates process CPU usage by group, busiest first. groups
+// holds the group of each process.
+func groupByCPU(groups []string, procs []ProcStat) []ProcessCPUInfo {
+	byGroup := make(map[string]*ProcessCPUInfo)
+	for i, p := range procs {
+		g, ok := byGroup[groups[i]]
+		if !ok {
+			g = &ProcessCPUInfo{Name: groups[i]}
+			byGroup[groups[i]] = g
+		}
+		g.Percent += p.CPUPercent
+		g.Count++
+	}
+
+	result := make([]ProcessCPUInfo, 0, len(byGroup))
+	for _, g := range byGroup {
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Percent != result[j].Percent {
+			return result[i].Percent > result[j].Percent
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,25 @@
+package sysinfo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupByCPU(t *testing.T) {
+	got := groupByCPU([]string{"systemd", "gopls", "chrome", "chrome", "go"}, []ProcStat{
+		{PID: 1, Name: "systemd"},
+		{PID: 10, Name: "gopls", CPUPercent: 150},
+		{PID: 20, Name: "chrome", CPUPercent: 25},
+		{PID: 21, Name: "chromium", CPUPercent: 25},
+		{PID: 30, Name: "go", CPUPercent: 30},
+	})
+	want := []ProcessCPUInfo{
+		{Name: "gopls", Percent: 150, Count: 1},
+		{Name: "chrome", Percent: 50, Count: 2},
+		{Name: "go", Percent: 30, Count: 1},
+		{Name: "systemd", Percent: 0, Count: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupByCPU() = %+v, want %+v", got, want)
+	}
+}
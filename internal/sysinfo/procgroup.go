@@ -0,0 +1,359 @@
+package sysinfo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// GroupBy selects what a ProcessGrouper groups processes by.
+type GroupBy int
+
+const (
+	GroupByName      GroupBy = iota // Group rules, then the process name
+	GroupByUnit                     // systemd unit, e.g. "user@1000.service/app-firefox"
+	GroupByCgroup                   // cgroup v2 path
+	GroupByContainer                // Container runtime and ID, e.g. "docker: 3f4e5a6b7c8d"
+)
+
+// GroupRule names the group of processes matching its patterns. A rule
+// matches when all of its non-empty patterns match.
+type GroupRule struct {
+	Name    string // Group name; may use $1-style submatches of the last pattern
+	Comm    string // Regexp matched against the process name
+	Exe     string // Regexp matched against the executable path
+	Cmdline string // Regexp matched against the arguments, joined by spaces
+}
+
+// DefaultGroupRules fold the processes of common multi-process programs
+// into one group. Configured rules are tried before these.
+var DefaultGroupRules = []GroupRule{
+	{Name: "chrome", Comm: `^(chrome|chromium|Chrome|Chromium)$`},
+	{Name: "firefox", Comm: `^(firefox|Firefox|firefox-esr)$`},
+	{Name: "code", Comm: `^(code|Code|code-oss)$`},
+	{Name: "electron", Comm: `^(electron|Electron)$`},
+	{Name: "slack", Comm: `^(slack|Slack)$`},
+	{Name: "discord", Comm: `^(discord|Discord)$`},
+	{Name: "spotify", Comm: `^(spotify|Spotify)$`},
+	{Name: "cursor", Comm: `^(cursor|Cursor)$`},
+	{Name: "crush", Comm: `^(crush|Crush)$`},
+	{Name: "node", Comm: `^(node|nodejs|Node)$`},
+	{Name: "python", Comm: `^(python|python3|Python)$`},
+	{Name: "java", Comm: `^(java|Java)$`},
+	{Name: "rust-analyzer", Comm: `^rust-analyzer$`},
+	{Name: "gopls", Comm: `^gopls$`},
+	{Name: "docker", Comm: `^(docker|dockerd|containerd)$`},
+	{Name: "gnome", Comm: `^gnome-`},
+	{Name: "systemd", Comm: `^systemd`},
+}
+
+// groupRule is a GroupRule with compiled patterns.
+type groupRule struct {
+	name               string
+	comm, exe, cmdline *regexp.Regexp
+}
+
+// compileRules compiles rules, naming the offending rule on error.
+func compileRules(rules []GroupRule) ([]groupRule, error) {
+	compiled := make([]groupRule, 0, len(rules))
+	for i, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("group rule %d: missing name", i)
+		}
+		if r.Comm == "" && r.Exe == "" && r.Cmdline == "" {
+			return nil, fmt.Errorf("group rule %q: needs a comm, exe or cmdline pattern", r.Name)
+		}
+		c := groupRule{name: r.Name}
+		for _, p := range []struct {
+			field   string
+			pattern string
+			re      **regexp.Regexp
+		}{{"comm", r.Comm, &c.comm}, {"exe", r.Exe, &c.exe}, {"cmdline", r.Cmdline, &c.cmdline}} {
+			if p.pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(p.pattern)
+			if err != nil {
+				return nil, fmt.Errorf("group rule %q: %s: %w", r.Name, p.field, err)
+			}
+			*p.re = re
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// mustCompileRules is like compileRules but panics on error, for rules
+// built into the program.
+func mustCompileRules(rules []GroupRule) []groupRule {
+	compiled, err := compileRules(rules)
+	if err != nil {
+		panic("sysinfo: " + err.Error())
+	}
+	return compiled
+}
+
+// defaultRules are DefaultGroupRules compiled.
+var defaultRules = mustCompileRules(DefaultGroupRules)
+
+// getProcessGroup returns the group of a process from its name alone, using
+// the default rules.
+func getProcessGroup(name string) string {
+	for _, r := range defaultRules {
+		if r.exe != nil || r.cmdline != nil {
+			continue
+		}
+		if m := r.comm.FindStringSubmatch(name); m != nil {
+			return expandGroupName(r.name, m)
+		}
+	}
+	return name
+}
+
+// ProcessGrouper assigns processes to groups: by group rules tried in order
+// and then by name, or by systemd unit, cgroup or container, with processes
+// outside any unit, cgroup or container grouped by rules and name. Groups
+// are cached for each process's lifetime, so the executable, command line
+// and cgroup are read once per process. It is safe for concurrent use.
+type ProcessGrouper struct {
+	// ContainerName, if set, turns a container ID into a display name. It
+	// returns "" for unknown containers, which are shown by short ID.
+	ContainerName func(runtime, id string) string
+
+	by     GroupBy
+	rules  []groupRule
+	procfs string // ProcfsRoot, read when a process is grouped, if empty
+
+	mu    sync.Mutex
+	cache map[procKey]string
+}
+
+// NewProcessGrouper creates a grouper trying rules before DefaultGroupRules.
+func NewProcessGrouper(by GroupBy, rules []GroupRule) (*ProcessGrouper, error) {
+	compiled, err := compileRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	return newProcessGrouper(by, append(compiled, defaultRules...)), nil
+}
+
+// newProcessGrouper creates a grouper trying compiled rules.
+func newProcessGrouper(by GroupBy, rules []groupRule) *ProcessGrouper {
+	return &ProcessGrouper{
+		by:    by,
+		rules: rules,
+		cache: make(map[procKey]string),
+	}
+}
+
+// defaultGrouper groups by the default rules and name.
+var defaultGrouper = newProcessGrouper(GroupByName, defaultRules)
+
+// TopMemory returns the top N process groups by memory usage.
+func (g *ProcessGrouper) TopMemory(n int) ([]ProcessMemInfo, error) {
+	procs, err := SampleProcesses()
+	if err != nil {
+		return nil, err
+	}
+	total, err := memTotal()
+	if err != nil {
+		return nil, err
+	}
+	result := groupByMemory(g.groups(procs), procs, total)
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result, nil
+}
+
+// TopCPU returns the top N process groups by CPU usage since the previous
+// process scan. Usage is zero on the first scan.
+func (g *ProcessGrouper) TopCPU(n int) ([]ProcessCPUInfo, error) {
+	procs, err := SampleProcesses()
+	if err != nil {
+		return nil, err
+	}
+	result := groupByCPU(g.groups(procs), procs)
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result, nil
+}
+
+// groups returns the group of each process, forgetting processes that
+// exited.
+func (g *ProcessGrouper) groups(procs []ProcStat) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	groups := make([]string, len(procs))
+	cache := make(map[procKey]string, len(procs))
+	for i, p := range procs {
+		key := procKey{p.PID, p.StartTime}
+		group, ok := g.cache[key]
+		if !ok {
+			group = g.group(p)
+		}
+		groups[i], cache[key] = group, group
+	}
+	g.cache = cache
+	return groups
+}
+
+// group works out the group of one process.
+func (g *ProcessGrouper) group(p ProcStat) string {
+	procfs := g.procfs
+	if procfs == "" {
+		procfs = ProcfsRoot
+	}
+	if g.by != GroupByName {
+		if group := g.cgroupGroup(readCgroup(procfs, p.PID)); group != "" {
+			return group
+		}
+	}
+
+	pid := fmt.Sprint(p.PID)
+	var exe, cmdline *string // Read on first use
+	for _, r := range g.rules {
+		var m []string
+		if r.comm != nil {
+			if m = r.comm.FindStringSubmatch(p.Name); m == nil {
+				continue
+			}
+		}
+		if r.exe != nil {
+			if exe == nil {
+				s, _ := os.Readlink(filepath.Join(procfs, pid, "exe"))
+				exe = &s
+			}
+			if m = r.exe.FindStringSubmatch(*exe); m == nil {
+				continue
+			}
+		}
+		if r.cmdline != nil {
+			if cmdline == nil {
+				s := readCmdline(procfs, pid)
+				cmdline = &s
+			}
+			if m = r.cmdline.FindStringSubmatch(*cmdline); m == nil {
+				continue
+			}
+		}
+		return expandGroupName(r.name, m)
+	}
+	return p.Name
+}
+
+// expandGroupName replaces $1-style references in name with submatches.
+func expandGroupName(name string, m []string) string {
+	if !strings.Contains(name, "$") {
+		return name
+	}
+	return os.Expand(name, func(ref string) string {
+		var i int
+		if _, err := fmt.Sscan(ref, &i); err == nil && i < len(m) {
+			return m[i]
+		}
+		return ""
+	})
+}
+
+// cgroupGroup returns the group of a process in the given cgroup v2 path by
+// the grouper's mode, or "" if the process isn't in a unit or container.
+func (g *ProcessGrouper) cgroupGroup(cgroup string) string {
+	switch g.by {
+	case GroupByUnit:
+		return systemdUnit(cgroup)
+	case GroupByCgroup:
+		return strings.TrimPrefix(cgroup, "/")
+	case GroupByContainer:
+		runtime, id := containerID(cgroup)
+		if id == "" {
+			return ""
+		}
+		name := ""
+		if g.ContainerName != nil {
+			name = g.ContainerName(runtime, id)
+		}
+		if name == "" {
+			name = id[:12]
+		}
+		return runtime + ": " + name
+	}
+	return ""
+}
+
+// readCgroup returns a process's cgroup v2 path, "" if unknown.
+func readCgroup(procfs string, pid int) string {
+	data, err := os.ReadFile(filepath.Join(procfs, fmt.Sprint(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return path
+		}
+	}
+	return ""
+}
+
+// readCmdline returns a process's arguments joined by spaces.
+func readCmdline(procfs, pid string) string {
+	data, err := os.ReadFile(filepath.Join(procfs, pid, "cmdline"))
+	if err != nil {
+		return ""
+	}
+	return string(bytes.TrimRight(bytes.ReplaceAll(data, []byte{0}, []byte{' '}), " "))
+}
+
+// unitInstance matches the instance or PID suffix of transient unit names,
+// as in app-firefox@1a2b3c or app-gnome-firefox-4123.
+var unitInstance = regexp.MustCompile(`(@.*|-[0-9]+)$`)
+
+// systemdUnit returns the systemd unit of a cgroup path. Units under a
+// user manager are given as the manager and the app unit without its
+// instance suffix, e.g. "user@1000.service/app-firefox".
+func systemdUnit(cgroup string) string {
+	var units []string
+	for _, seg := range strings.Split(cgroup, "/") {
+		if strings.HasSuffix(seg, ".service") || strings.HasSuffix(seg, ".scope") {
+			units = append(units, seg)
+		}
+	}
+	switch {
+	case len(units) == 0:
+		return ""
+	case len(units) > 1 && strings.HasPrefix(units[0], "user@"):
+		app := units[len(units)-1]
+		app = strings.TrimSuffix(strings.TrimSuffix(app, ".service"), ".scope")
+		return units[0] + "/" + unitInstance.ReplaceAllString(app, "")
+	}
+	return units[0]
+}
+
+// containerCgroup matches the cgroup path components of containers, with
+// the runtime prefix and the 64-digit container ID.
+var containerCgroup = regexp.MustCompile(`(docker|libpod|cri-containerd|crio|containerd)[-/]([0-9a-f]{64})(\.scope)?(/|$)`)
+
+// containerRuntimes maps cgroup prefixes to runtime names.
+var containerRuntimes = map[string]string{
+	"docker":         "docker",
+	"libpod":         "podman",
+	"cri-containerd": "containerd",
+	"containerd":     "containerd",
+	"crio":           "crio",
+}
+
+// containerID returns the runtime and ID of the container of a cgroup
+// path, or empty strings outside a container.
+func containerID(cgroup string) (runtime, id string) {
+	m := containerCgroup.FindStringSubmatch(cgroup)
+	if m == nil {
+		return "", ""
+	}
+	return containerRuntimes[m[1]], m[2]
+}
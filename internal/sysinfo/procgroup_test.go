@@ -0,0 +1,111 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProcessGrouper(t *testing.T) {
+	dockerID := strings.Repeat("ab12", 16)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"1/cgroup":   "0::/init.scope\n",
+		"2/cgroup":   "0::/\n",
+		"10/cgroup":  "0::/user.slice/user-1000.slice/user@1000.service/app.slice/app-firefox@1a2b.scope\n",
+		"11/cgroup":  "0::/user.slice/user-1000.slice/user@1000.service/app.slice/app-gnome-code-4123.scope\n",
+		"20/cgroup":  "0::/system.slice/docker-" + dockerID + ".scope\n",
+		"20/cmdline": "postgres\x00-D\x00/var/lib/postgresql/data\x00",
+		"30/cgroup":  "0::/system.slice/nginx.service\n",
+		"40/cmdline": "/usr/bin/python3\x00-m\x00http.server\x008000\x00",
+	})
+	if err := os.Symlink("/opt/tools/bin/helper", filepath.Join(root, "30/exe")); err != nil {
+		t.Fatal(err)
+	}
+
+	procs := []ProcStat{
+		{PID: 1, Name: "systemd"},
+		{PID: 2, Name: "kthreadd"},
+		{PID: 10, Name: "firefox"},
+		{PID: 11, Name: "code"},
+		{PID: 20, Name: "postgres"},
+		{PID: 30, Name: "nginx"},
+		{PID: 40, Name: "python3"},
+	}
+	rules := []GroupRule{
+		{Name: "py: $1", Comm: `^python`, Cmdline: ` -m ([\w.]+)`},
+		{Name: "tools", Exe: `^/opt/tools/`},
+	}
+
+	tests := []struct {
+		by   GroupBy
+		want []string
+	}{
+		{GroupByName, []string{"systemd", "kthreadd", "firefox", "code", "postgres", "tools", "py: http.server"}},
+		{GroupByUnit, []string{
+			"init.scope", "kthreadd", "user@1000.service/app-firefox", "user@1000.service/app-gnome-code",
+			"docker-" + dockerID + ".scope", "nginx.service", "py: http.server",
+		}},
+		{GroupByCgroup, []string{
+			"init.scope", "kthreadd", "user.slice/user-1000.slice/user@1000.service/app.slice/app-firefox@1a2b.scope",
+			"user.slice/user-1000.slice/user@1000.service/app.slice/app-gnome-code-4123.scope",
+			"system.slice/docker-" + dockerID + ".scope", "system.slice/nginx.service", "py: http.server",
+		}},
+		{GroupByContainer, []string{"systemd", "kthreadd", "firefox", "code", "docker: ab12ab12ab12", "tools", "py: http.server"}},
+	}
+	for _, tt := range tests {
+		g, err := NewProcessGrouper(tt.by, rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.procfs = root
+		got := g.groups(procs)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("GroupBy %d: groups =\n%q\nwant\n%q", tt.by, got, tt.want)
+		}
+	}
+
+	// Container names come from ContainerName when it knows them
+	g, _ := NewProcessGrouper(GroupByContainer, nil)
+	g.procfs = root
+	g.ContainerName = func(runtime, id string) string {
+		if runtime == "docker" && id == dockerID {
+			return "postgres"
+		}
+		return ""
+	}
+	if got := g.groups(procs[4:5]); got[0] != "docker: postgres" {
+		t.Errorf("named container group = %q, want %q", got[0], "docker: postgres")
+	}
+}
+
+func TestCompileRules(t *testing.T) {
+	tests := []struct {
+		rule GroupRule
+		err  string
+	}{
+		{GroupRule{Name: "ok", Comm: "^x$"}, ""},
+		{GroupRule{Comm: "^x$"}, "missing name"},
+		{GroupRule{Name: "empty"}, "needs a comm, exe or cmdline pattern"},
+		{GroupRule{Name: "bad", Exe: "("}, `group rule "bad": exe:`},
+	}
+	for _, tt := range tests {
+		_, err := compileRules([]GroupRule{tt.rule})
+		if (err == nil) != (tt.err == "") || (err != nil && !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("compileRules(%+v) error = %v, want %q", tt.rule, err, tt.err)
+		}
+	}
+}
+
+func TestMustCompileRules(t *testing.T) {
+	if len(defaultRules) != len(DefaultGroupRules) {
+		t.Errorf("compiled %d default rules, want %d", len(defaultRules), len(DefaultGroupRules))
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("mustCompileRules() of a bad rule did not panic")
+		}
+	}()
+	mustCompileRules([]GroupRule{{Name: "bad", Comm: "("}})
+}
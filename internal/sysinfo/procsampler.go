@@ -0,0 +1,219 @@
+package sysinfo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// userHZ is the kernel's USER_HZ, the unit of CPU times in /proc. It is 100
+// on every Linux architecture.
+const userHZ = 100
+
+// commLen is the length at which the kernel truncates process names.
+const commLen = 15
+
+// ProcStat is one process's state from /proc.
+type ProcStat struct {
+	PID        int
+	Name       string  // Process name, completed from cmdline when the kernel truncated it
+	StartTime  uint64  // Clock ticks after boot; with PID, identifies the process
+	CPUTicks   uint64  // User plus system time in USER_HZ ticks
+	RSS        uint64  // Resident set size in bytes, statm's resident count as reported in stat
+	CPUPercent float64 // Share of one CPU since the previous scan, 0 on the first
+}
+
+// procKey identifies a process across scans, telling apart reused PIDs.
+type procKey struct {
+	pid   int
+	start uint64
+}
+
+// procInfo is what ProcSampler remembers about a process between scans.
+type procInfo struct {
+	name  string
+	ticks uint64
+}
+
+// ProcSampler reads every process from procfs in one pass. It remembers
+// each process's name and CPU time between scans, so only new processes
+// cost more than a single read of /proc/[pid]/stat, and CPU usage comes from
+// the difference with the previous scan. It is safe for concurrent use, and
+// scans closer together than MinInterval share one result so several
+// monitors can use the same sampler.
+type ProcSampler struct {
+	// MinInterval is the minimum time between scans.
+	MinInterval time.Duration
+
+	procfs   string
+	pageSize uint64
+	now      func() time.Time
+
+	mu    sync.Mutex
+	procs map[procKey]procInfo
+	at    time.Time
+	last  []ProcStat
+}
+
+// NewProcSampler creates a sampler reading the procfs tree rooted at procfs,
+// or at ProcfsRoot as it is at each scan if procfs is empty.
+func NewProcSampler(procfs string) *ProcSampler {
+	return &ProcSampler{
+		MinInterval: 500 * time.Millisecond,
+		procfs:      procfs,
+		pageSize:    uint64(os.Getpagesize()),
+		now:         time.Now,
+	}
+}
+
+// sharedProcSampler is the sampler behind SampleProcesses.
+var sharedProcSampler = NewProcSampler("")
+
+// SampleProcesses scans /proc with a sampler shared by every caller, so CPU
+// usage is measured since whichever caller scanned last.
+func SampleProcesses() ([]ProcStat, error) {
+	return sharedProcSampler.Sample()
+}
+
+// Sample returns every process, reusing the previous scan if it is more
+// recent than MinInterval. The result must not be modified.
+func (s *ProcSampler) Sample() ([]ProcStat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.last != nil && now.Sub(s.at) < s.MinInterval {
+		return s.last, nil
+	}
+
+	procfs := s.procfs
+	if procfs == "" {
+		procfs = ProcfsRoot
+	}
+	f, err := os.Open(procfs)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.Readdirnames(-1)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	secs := now.Sub(s.at).Seconds()
+	first := s.procs == nil
+	procs := make(map[procKey]procInfo, len(names))
+	result := make([]ProcStat, 0, len(names))
+	var buf []byte
+	for _, name := range names {
+		pid, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		buf, err = readFileInto(filepath.Join(procfs, name, "stat"), buf)
+		if err != nil {
+			continue // Exited since the directory listing
+		}
+		p, comm, ok := parseProcStat(buf)
+		if !ok {
+			continue
+		}
+		p.PID = pid
+		p.RSS *= s.pageSize
+
+		key := procKey{pid, p.StartTime}
+		prev, seen := s.procs[key]
+		if seen {
+			p.Name = prev.name
+		} else {
+			p.Name = fullName(procfs, name, comm)
+		}
+		if !first && secs > 0 {
+			ticks := p.CPUTicks
+			if seen && prev.ticks <= ticks {
+				ticks -= prev.ticks
+			}
+			p.CPUPercent = float64(ticks) / userHZ / secs * 100
+		}
+		procs[key] = procInfo{name: p.Name, ticks: p.CPUTicks}
+		result = append(result, p)
+	}
+
+	s.procs, s.at, s.last = procs, now, result
+	return result, nil
+}
+
+// fullName returns a process's name, completing a comm the kernel
+// truncated from the first argument of its command line.
+func fullName(procfs, pid, comm string) string {
+	if len(comm) < commLen {
+		return comm
+	}
+	cmdline, err := os.ReadFile(filepath.Join(procfs, pid, "cmdline"))
+	if err != nil {
+		return comm
+	}
+	arg0, _, _ := bytes.Cut(cmdline, []byte{0})
+	if base := filepath.Base(string(arg0)); strings.HasPrefix(base, comm) {
+		return base
+	}
+	return comm
+}
+
+// parseProcStat parses a /proc/[pid]/stat line, returning the comm and the
+// CPU time, start time and resident page count. The comm is parenthesized
+// and may itself contain spaces and parentheses, so fields are counted from
+// its last closing parenthesis.
+func parseProcStat(line []byte) (ProcStat, string, bool) {
+	open := bytes.IndexByte(line, '(')
+	end := bytes.LastIndexByte(line, ')')
+	if open < 0 || end < open {
+		return ProcStat{}, "", false
+	}
+	comm := string(line[open+1 : end])
+
+	// Fields after the comm, starting with state (field 3)
+	fields := strings.Fields(string(line[end+1:]))
+	if len(fields) < 22 {
+		return ProcStat{}, "", false
+	}
+	utime, err1 := strconv.ParseUint(fields[11], 10, 64)
+	stime, err2 := strconv.ParseUint(fields[12], 10, 64)
+	start, err3 := strconv.ParseUint(fields[19], 10, 64)
+	rss, err4 := strconv.ParseInt(fields[21], 10, 64)
+	if errors.Join(err1, err2, err3, err4) != nil {
+		return ProcStat{}, "", false
+	}
+	return ProcStat{CPUTicks: utime + stime, StartTime: start, RSS: uint64(max(rss, 0))}, comm, true
+}
+
+// readFileInto reads a small procfs file, reusing buf. procfs files report
+// a zero size, so the file is read until EOF.
+func readFileInto(path string, buf []byte) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return buf, err
+	}
+	defer f.Close()
+
+	buf = buf[:0]
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		n, err := f.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return buf, err
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package sysinfo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// statLine formats a /proc/[pid]/stat line with the fields ProcSampler reads.
+func statLine(pid int, comm string, utime, stime, start, rss uint64) string {
+	return fmt.Sprintf("%d (%s) S 1 1 1 0 -1 4194304 100 0 0 0 %d %d 0 0 20 0 1 0 %d 1000000 %d 18446744073709551615\n",
+		pid, comm, utime, stime, start, rss)
+}
+
+func TestProcSampler(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"1/stat":     statLine(1, "systemd", 40, 10, 1, 3),
+		"42/stat":    statLine(42, "rust-analyzer-p", 500, 100, 900, 1000),
+		"42/cmdline": "/usr/bin/rust-analyzer-proc-macro-srv\x00--flag\x00",
+		"77/stat":    statLine(77, "evil) R (name", 0, 0, 950, 1),
+		"self":       "",
+		"uptime":     "12.5 3.1\n",
+	})
+
+	s := NewProcSampler(root)
+	s.pageSize = 4096
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+
+	procs, err := s.Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(procs, func(i, j int) bool { return procs[i].PID < procs[j].PID })
+	want := []ProcStat{
+		{PID: 1, Name: "systemd", StartTime: 1, CPUTicks: 50, RSS: 3 * 4096},
+		{PID: 42, Name: "rust-analyzer-proc-macro-srv", StartTime: 900, CPUTicks: 600, RSS: 1000 * 4096},
+		{PID: 77, Name: "evil) R (name", StartTime: 950, CPUTicks: 0, RSS: 4096},
+	}
+	if fmt.Sprint(procs) != fmt.Sprint(want) {
+		t.Errorf("first Sample() =\n%+v\nwant\n%+v", procs, want)
+	}
+
+	// Within MinInterval the previous scan is reused
+	writeFiles(t, root, map[string]string{"1/stat": statLine(1, "systemd", 240, 10, 1, 3)})
+	now = now.Add(100 * time.Millisecond)
+	if again, _ := s.Sample(); again[0].CPUTicks != procs[0].CPUTicks {
+		t.Errorf("Sample() within MinInterval rescanned")
+	}
+
+	// Two seconds later: 2 CPU-seconds for systemd, 3 for rust-analyzer, whose
+	// name comes from the cache; PID 77 was reused by a new process
+	now = now.Add(1900 * time.Millisecond)
+	writeFiles(t, root, map[string]string{
+		"42/stat":    statLine(42, "rust-analyzer-p", 700, 200, 900, 1000),
+		"42/cmdline": "",
+		"77/stat":    statLine(77, "sleep", 10, 0, 1100, 1),
+	})
+	procs, err = s.Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(procs, func(i, j int) bool { return procs[i].PID < procs[j].PID })
+	wantPct := map[int]float64{1: 100, 42: 150, 77: 5}
+	for _, p := range procs {
+		if p.CPUPercent != wantPct[p.PID] {
+			t.Errorf("pid %d CPUPercent = %v, want %v", p.PID, p.CPUPercent, wantPct[p.PID])
+		}
+	}
+	if procs[1].Name != "rust-analyzer-proc-macro-srv" || procs[2].Name != "sleep" {
+		t.Errorf("names = %q, %q, want cached name and new process name", procs[1].Name, procs[2].Name)
+	}
+}
+
+func TestProcSamplerDefaultRoot(t *testing.T) {
+	// Created before ProcfsRoot is pointed elsewhere, like the shared sampler
+	s := NewProcSampler("")
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"7/stat": statLine(7, "init", 1, 1, 1, 1)})
+	defer func(orig string) { ProcfsRoot = orig }(ProcfsRoot)
+	ProcfsRoot = root
+
+	procs, err := s.Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(procs) != 1 || procs[0].Name != "init" {
+		t.Errorf("Sample() = %+v, want the process under the overridden root", procs)
+	}
+}
+
+func TestParseProcStat(t *testing.T) {
+	tests := []struct {
+		line string
+		ok   bool
+	}{
+		{statLine(1, "bash", 1, 2, 3, 4), true},
+		{statLine(1, "a b (c)", 1, 2, 3, 4), true},
+		{"1 (truncated) S 1 1", false},
+		{"garbage", false},
+	}
+	for _, tt := range tests {
+		p, _, ok := parseProcStat([]byte(tt.line))
+		if ok != tt.ok {
+			t.Errorf("parseProcStat(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+		}
+		if ok && (p.CPUTicks != 3 || p.StartTime != 3 || p.RSS != 4) {
+			t.Errorf("parseProcStat(%q) = %+v", tt.line, p)
+		}
+	}
+}
+
+// gopsutilTopProcesses is GetTopProcesses as it was before ProcSampler, for
+// comparison.
+func gopsutilTopProcesses(n int) ([]ProcessMemInfo, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	memInfo, err := mem.VirtualMemory()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*ProcessMemInfo)
+	for _, p := range procs {
+		name, err := p.Name()
+		if err != nil {
+			continue
+		}
+		meminfo, err := p.MemoryInfo()
+		if err != nil {
+			continue
+		}
+		group := getProcessGroup(name)
+		if g, ok := groups[group]; ok {
+			g.RSS += meminfo.RSS
+			g.Count++
+		} else {
+			groups[group] = &ProcessMemInfo{Name: group, RSS: meminfo.RSS, Count: 1}
+		}
+	}
+
+	result := make([]ProcessMemInfo, 0, len(groups))
+	for _, g := range groups {
+		g.Percent = float64(g.RSS) / float64(memInfo.Total) * 100
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].RSS > result[j].RSS })
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result, nil
+}
+
+func BenchmarkGopsutilTopProcesses(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := gopsutilTopProcesses(5); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProcSamplerTopProcesses(b *testing.B) {
+	if _, err := os.Stat(filepath.Join(ProcfsRoot, "self/stat")); err != nil {
+		b.Skip("no procfs")
+	}
+	s := NewProcSampler(ProcfsRoot)
+	s.MinInterval = 0
+	g, _ := NewProcessGrouper(GroupByName, nil)
+	for i := 0; i < b.N; i++ {
+		procs, err := s.Sample()
+		if err != nil {
+			b.Fatal(err)
+		}
+		groupByMemory(g.groups(procs), procs, 1<<34)
+	}
+}
@@ -0,0 +1,203 @@
+package sysinfo
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SensorKind is the quantity a sensor measures.
+type SensorKind int
+
+const (
+	SensorTemp    SensorKind = iota // Celsius
+	SensorFan                       // RPM
+	SensorVoltage                   // Volts
+)
+
+// Sensor is one hwmon reading.
+type Sensor struct {
+	Chip  string // hwmon chip name, suffixed with -2, -3... when repeated
+	Name  string // Channel file prefix, e.g. temp1
+	Label string // Driver label, or Name when the driver gives none
+	Kind  SensorKind
+	Value float64
+	Min   float64 // Limits from the driver, 0 if not exposed
+	Max   float64
+	Crit  float64
+}
+
+// ID returns the sensor's selector name, "chip/label".
+func (s Sensor) ID() string {
+	return s.Chip + "/" + s.Label
+}
+
+// Match reports whether the sensor matches a selector glob, tried against
+// both "chip/label" and "chip/name" so unlabeled channels can be picked.
+func (s Sensor) Match(selector string) bool {
+	if ok, _ := path.Match(selector, s.ID()); ok {
+		return true
+	}
+	ok, _ := path.Match(selector, s.Chip+"/"+s.Name)
+	return ok
+}
+
+// GetSensors returns every hwmon temperature, fan and voltage sensor.
+func GetSensors() ([]Sensor, error) {
+	return ReadSensors(SysfsRoot)
+}
+
+// sensorInput matches hwmon channel input files and captures the kind and
+// channel number.
+var sensorInput = regexp.MustCompile(`^(temp|fan|in)([0-9]+)_input$`)
+
+// sensorKinds maps hwmon channel types to kinds and the divisor from the
+// file's unit (millidegrees, RPM, millivolts).
+var sensorKinds = map[string]struct {
+	kind  SensorKind
+	scale float64
+}{
+	"temp": {SensorTemp, 1000},
+	"fan":  {SensorFan, 1},
+	"in":   {SensorVoltage, 1000},
+}
+
+// ReadSensors reads all hwmon sensors from a sysfs tree rooted at sysfs,
+// ordered by chip and then by kind and channel. Chips that share a name,
+// such as several NVMe drives, are numbered in hwmon order.
+func ReadSensors(sysfs string) ([]Sensor, error) {
+	dirs, err := filepath.Glob(filepath.Join(sysfs, "class/hwmon/hwmon*"))
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, os.ErrNotExist
+	}
+	sort.Slice(dirs, func(i, j int) bool { return hwmonIndex(dirs[i]) < hwmonIndex(dirs[j]) })
+
+	var sensors []Sensor
+	chips := make(map[string]int)
+	for _, dir := range dirs {
+		chip := readString(filepath.Join(dir, "name"))
+		if chip == "" {
+			chip = filepath.Base(dir)
+		}
+		chips[chip]++
+		if n := chips[chip]; n > 1 {
+			chip = fmt.Sprintf("%s-%d", chip, n)
+		}
+		sensors = append(sensors, readChip(dir, chip)...)
+	}
+	return sensors, nil
+}
+
+// hwmonIndex returns N from a hwmonN directory so hwmon10 sorts after hwmon9.
+func hwmonIndex(dir string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "hwmon"))
+	return n
+}
+
+// readChip reads the sensors of one hwmon directory.
+func readChip(dir, chip string) []Sensor {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	type channel struct {
+		typ string
+		n   int
+	}
+	var channels []channel
+	for _, e := range entries {
+		if m := sensorInput.FindStringSubmatch(e.Name()); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			channels = append(channels, channel{m[1], n})
+		}
+	}
+	order := map[string]int{"temp": 0, "fan": 1, "in": 2}
+	sort.Slice(channels, func(i, j int) bool {
+		if channels[i].typ != channels[j].typ {
+			return order[channels[i].typ] < order[channels[j].typ]
+		}
+		return channels[i].n < channels[j].n
+	})
+
+	var sensors []Sensor
+	for _, c := range channels {
+		name := fmt.Sprintf("%s%d", c.typ, c.n)
+		k := sensorKinds[c.typ]
+		read := func(suffix string) float64 {
+			v, err := readInt(filepath.Join(dir, name+"_"+suffix))
+			if err != nil {
+				return 0
+			}
+			return float64(v) / k.scale
+		}
+		raw, err := readInt(filepath.Join(dir, name+"_input"))
+		if err != nil {
+			// Disconnected fan headers and sleeping drives fail to read
+			continue
+		}
+		s := Sensor{
+			Chip:  chip,
+			Name:  name,
+			Label: readString(filepath.Join(dir, name+"_label")),
+			Kind:  k.kind,
+			Value: float64(raw) / k.scale,
+			Min:   read("min"),
+			Max:   read("max"),
+			Crit:  read("crit"),
+		}
+		if s.Label == "" {
+			s.Label = name
+		}
+		sensors = append(sensors, s)
+	}
+	return sensors
+}
+
+// SelectSensor returns the first sensor of the given kind matching
+// selector. See Sensor.Match.
+func SelectSensor(sensors []Sensor, kind SensorKind, selector string) (Sensor, bool) {
+	for _, s := range sensors {
+		if s.Kind == kind && s.Match(selector) {
+			return s, true
+		}
+	}
+	return Sensor{}, false
+}
+
+// cpuTempSelectors are tried in order to find the CPU temperature: the
+// package or die reading of each CPU driver first, then any of its channels.
+var cpuTempSelectors = []string{
+	"coretemp/Package id 0",
+	"k10temp/Tdie",
+	"k10temp/Tctl",
+	"zenpower/Tdie",
+	"coretemp/*",
+	"k10temp/*",
+	"zenpower/*",
+	"cpu_thermal/*",
+}
+
+// CPUTemp returns the CPU temperature from sensors. An empty selector picks
+// the package sensor of a known CPU driver; sensors of other devices are
+// never used in that case, so a board without one reports false rather than
+// a drive or Wi-Fi temperature.
+func CPUTemp(sensors []Sensor, selector string) (float64, bool) {
+	selectors := cpuTempSelectors
+	if selector != "" {
+		selectors = []string{selector}
+	}
+	for _, sel := range selectors {
+		if s, ok := SelectSensor(sensors, SensorTemp, sel); ok {
+			return s.Value, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,79 @@
+package sysinfo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadSensors(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"class/hwmon/hwmon0/name":        "nvme\n",
+		"class/hwmon/hwmon0/temp1_input": "38850\n",
+		"class/hwmon/hwmon0/temp1_label": "Composite\n",
+		"class/hwmon/hwmon0/temp1_crit":  "84850\n",
+
+		"class/hwmon/hwmon2/name":        "k10temp\n",
+		"class/hwmon/hwmon2/temp1_input": "61250\n",
+		"class/hwmon/hwmon2/temp1_label": "Tctl\n",
+		"class/hwmon/hwmon2/temp3_input": "48000\n",
+		"class/hwmon/hwmon2/temp3_label": "Tccd1\n",
+
+		"class/hwmon/hwmon10/name":       "nct6798\n",
+		"class/hwmon/hwmon10/fan2_input": "1180\n",
+		"class/hwmon/hwmon10/fan2_min":   "300\n",
+		"class/hwmon/hwmon10/in0_input":  "1032\n",
+		"class/hwmon/hwmon10/in0_max":    "1744\n",
+		"class/hwmon/hwmon10/in0_label":  "Vcore\n",
+
+		"class/hwmon/hwmon3/name":        "nvme\n",
+		"class/hwmon/hwmon3/temp1_input": "45000\n",
+		"class/hwmon/hwmon3/temp1_label": "Composite\n",
+	})
+
+	sensors, err := ReadSensors(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Sensor{
+		{Chip: "nvme", Name: "temp1", Label: "Composite", Kind: SensorTemp, Value: 38.85, Crit: 84.85},
+		{Chip: "k10temp", Name: "temp1", Label: "Tctl", Kind: SensorTemp, Value: 61.25},
+		{Chip: "k10temp", Name: "temp3", Label: "Tccd1", Kind: SensorTemp, Value: 48},
+		{Chip: "nvme-2", Name: "temp1", Label: "Composite", Kind: SensorTemp, Value: 45},
+		{Chip: "nct6798", Name: "fan2", Label: "fan2", Kind: SensorFan, Value: 1180, Min: 300},
+		{Chip: "nct6798", Name: "in0", Label: "Vcore", Kind: SensorVoltage, Value: 1.032, Max: 1.744},
+	}
+	if !reflect.DeepEqual(sensors, want) {
+		t.Errorf("ReadSensors() =\n%+v\nwant\n%+v", sensors, want)
+	}
+}
+
+func TestCPUTemp(t *testing.T) {
+	nvme := Sensor{Chip: "nvme", Name: "temp1", Label: "Composite", Value: 38}
+	wifi := Sensor{Chip: "iwlwifi_1", Name: "temp1", Label: "temp1", Value: 44}
+	tctl := Sensor{Chip: "k10temp", Name: "temp1", Label: "Tctl", Value: 61}
+	tdie := Sensor{Chip: "k10temp", Name: "temp2", Label: "Tdie", Value: 51}
+	fan := Sensor{Chip: "k10temp", Name: "fan1", Label: "Tdie", Kind: SensorFan, Value: 900}
+
+	tests := []struct {
+		name     string
+		sensors  []Sensor
+		selector string
+		want     float64
+		wantOK   bool
+	}{
+		{"no CPU sensor", []Sensor{nvme, wifi}, "", 0, false},
+		{"Tdie preferred", []Sensor{nvme, tctl, tdie}, "", 51, true},
+		{"any channel", []Sensor{nvme, tctl}, "", 61, true},
+		{"selector by label", []Sensor{nvme, tctl, tdie}, "k10temp/Tctl", 61, true},
+		{"selector by name", []Sensor{nvme, wifi}, "iwlwifi*/temp1", 44, true},
+		{"selector skips fans", []Sensor{fan, tdie}, "*/Tdie", 51, true},
+		{"selector without match", []Sensor{nvme, tctl}, "coretemp/*", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := CPUTemp(tt.sensors, tt.selector)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: CPUTemp() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
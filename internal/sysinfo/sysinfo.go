@@ -2,14 +2,11 @@
 package sysinfo
 
 import (
-	"regexp"
 	"sort"
 
 	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/load"
 	"github.com/shirou/gopsutil/v3/mem"
-	"github.com/shirou/gopsutil/v3/process"
 )
 
 // CPUInfo holds CPU information.
@@ -20,8 +17,11 @@ type CPUInfo struct {
 	Load1     float64
 	Load5     float64
 	Load15    float64
-	Temp      float64 // Celsius, 0 if unavailable
+	Temp      float64  // Celsius, 0 if unavailable
+	Sensors   []Sensor // hwmon sensors Temp was picked from, nil if unavailable
 	CoreCount int
+	Cores     []CPUCore // Live per-CPU state ordered like PerCPU, nil if unavailable
+	Governor  string    // cpufreq governor of the first CPU
 }
 
 // GetCPUInfo returns current CPU information.
@@ -42,10 +42,22 @@ func GetCPUInfo() (*CPUInfo, error) {
 		info.Overall = overall[0]
 	}
 
-	// Frequency
-	freqs, err := cpu.Info()
-	if err == nil && len(freqs) > 0 {
-		info.Freq = freqs[0].Mhz / 1000.0
+	// Frequency: the live average when cpufreq is available, otherwise the
+	// nominal frequency
+	if cores, err := ReadCPUCores(SysfsRoot); err == nil && len(cores) == len(perCPU) {
+		info.Cores = cores
+		info.Governor = cores[0].Governor
+		var total float64
+		for _, c := range cores {
+			total += c.FreqMHz
+		}
+		info.Freq = total / float64(len(cores)) / 1000.0
+	}
+	if info.Freq == 0 {
+		freqs, err := cpu.Info()
+		if err == nil && len(freqs) > 0 {
+			info.Freq = freqs[0].Mhz / 1000.0
+		}
 	}
 
 	// Load averages
@@ -57,20 +69,9 @@ func GetCPUInfo() (*CPUInfo, error) {
 	}
 
 	// Temperature
-	temps, err := host.SensorsTemperatures()
-	if err == nil {
-		for _, t := range temps {
-			// Look for CPU temp sensors
-			if t.SensorKey == "coretemp" || t.SensorKey == "k10temp" ||
-				t.SensorKey == "cpu_thermal" || t.SensorKey == "zenpower" {
-				info.Temp = t.Temperature
-				break
-			}
-		}
-		// Fallback to first sensor if no CPU sensor found
-		if info.Temp == 0 && len(temps) > 0 {
-			info.Temp = temps[0].Temperature
-		}
+	if sensors, err := GetSensors(); err == nil {
+		info.Sensors = sensors
+		info.Temp, _ = CPUTemp(sensors, "")
 	}
 
 	return info, nil
@@ -85,6 +86,26 @@ type MemInfo struct {
 	SwapTotal   uint64
 	SwapUsed    uint64
 	SwapPercent float64
+
+	// Page cache
+	Cached    uint64
+	Buffers   uint64
+	Dirty     uint64
+	Writeback uint64
+
+	// Compressed swap: data stored in zram devices, its compressed size and
+	// the memory used to hold it, and the zswap pool and the pages in it
+	ZramOrig    uint64
+	ZramCompr   uint64
+	ZramUsed    uint64
+	ZswapPool   uint64
+	ZswapStored uint64
+
+	// Reserved hugepages and those in use
+	HugeTotal uint64
+	HugeUsed  uint64
+
+	Pressure *PSI // Memory pressure, nil without PSI support
 }
 
 // GetMemInfo returns current memory information.
@@ -110,6 +131,8 @@ func GetMemInfo() (*MemInfo, error) {
 		}
 	}
 
+	readMemDetails(info, ProcfsRoot, SysfsRoot)
+
 	return info, nil
 }
 
@@ -121,93 +144,45 @@ type ProcessMemInfo struct {
 	Count   int
 }
 
-// Process grouping patterns
-var processGroups = map[string]*regexp.Regexp{
-	"chrome":        regexp.MustCompile(`^(chrome|chromium|Chrome|Chromium)$`),
-	"firefox":       regexp.MustCompile(`^(firefox|Firefox|firefox-esr)$`),
-	"code":          regexp.MustCompile(`^(code|Code|code-oss)$`),
-	"electron":      regexp.MustCompile(`^(electron|Electron)$`),
-	"slack":         regexp.MustCompile(`^(slack|Slack)$`),
-	"discord":       regexp.MustCompile(`^(discord|Discord)$`),
-	"spotify":       regexp.MustCompile(`^(spotify|Spotify)$`),
-	"cursor":        regexp.MustCompile(`^(cursor|Cursor)$`),
-	"crush":         regexp.MustCompile(`^(crush|Crush)$`),
-	"node":          regexp.MustCompile(`^(node|nodejs|Node)$`),
-	"python":        regexp.MustCompile(`^(python|python3|Python)$`),
-	"java":          regexp.MustCompile(`^(java|Java)$`),
-	"rust-analyzer": regexp.MustCompile(`^rust-analyzer$`),
-	"gopls":         regexp.MustCompile(`^gopls$`),
-	"docker":        regexp.MustCompile(`^(docker|dockerd|containerd)$`),
-	"gnome":         regexp.MustCompile(`^gnome-`),
-	"systemd":       regexp.MustCompile(`^systemd`),
-}
-
-func getProcessGroup(name string) string {
-	for group, pattern := range processGroups {
-		if pattern.MatchString(name) {
-			return group
-		}
-	}
-	return name
-}
-
-// GetTopProcesses returns the top N processes by memory usage.
+// GetTopProcesses returns the top N process groups by memory usage.
 func GetTopProcesses(n int) ([]ProcessMemInfo, error) {
-	procs, err := process.Processes()
-	if err != nil {
-		return nil, err
-	}
+	return defaultGrouper.TopMemory(n)
+}
 
-	// Aggregate by group
-	groups := make(map[string]*ProcessMemInfo)
-	
+// memTotal returns the total RAM in bytes.
+func memTotal() (uint64, error) {
 	memInfo, err := mem.VirtualMemory()
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	totalMem := memInfo.Total
-
-	for _, p := range procs {
-		name, err := p.Name()
-		if err != nil {
-			continue
-		}
-		
-		meminfo, err := p.MemoryInfo()
-		if err != nil {
-			continue
-		}
+	return memInfo.Total, nil
+}
 
-		group := getProcessGroup(name)
-		if g, ok := groups[group]; ok {
-			g.RSS += meminfo.RSS
+// groupByMemory aggregates process RSS by group, largest first. groups
+// holds the group of each process.
+func groupByMemory(groups []string, procs []ProcStat, totalMem uint64) []ProcessMemInfo {
+	byGroup := make(map[string]*ProcessMemInfo)
+	for i, p := range procs {
+		if g, ok := byGroup[groups[i]]; ok {
+			g.RSS += p.RSS
 			g.Count++
 		} else {
-			groups[group] = &ProcessMemInfo{
-				Name:  group,
-				RSS:   meminfo.RSS,
-				Count: 1,
-			}
+			byGroup[groups[i]] = &ProcessMemInfo{Name: groups[i], RSS: p.RSS, Count: 1}
 		}
 	}
 
-	// Calculate percentages and convert to slice
-	result := make([]ProcessMemInfo, 0, len(groups))
-	for _, g := range groups {
+	result := make([]ProcessMemInfo, 0, len(byGroup))
+	for _, g := range byGroup {
 		g.Percent = float64(g.RSS) / float64(totalMem) * 100
 		result = append(result, *g)
 	}
-
-	// Sort by RSS
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].RSS > result[j].RSS
+		if result[i].RSS != result[j].RSS {
+			return result[i].RSS > result[j].RSS
+		}
+		return result[i].Name < result[j].Name
 	})
-
-	if len(result) > n {
-		result = result[:n]
-	}
-
-	return result, nil
+	return result
 }
 
 // FormatBytes formats bytes to human-readable string.
@@ -0,0 +1,186 @@
+package sysinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// UnitStatus is the state of a systemd unit.
+type UnitStatus struct {
+	Name        string
+	Description string
+	LoadState   string // loaded, not-found, masked, ...
+	ActiveState string // active, inactive, failed, activating, deactivating or reloading
+	SubState    string // Unit type specific, e.g. running or exited
+}
+
+// SystemdBackend reads unit states from a systemd manager, the system's or
+// a user's.
+type SystemdBackend interface {
+	// Units returns the units the manager has loaded.
+	Units(ctx context.Context) ([]UnitStatus, error)
+	// Restarts returns how often a service was restarted automatically
+	// since it was loaded, 0 for other units and units not loaded.
+	Restarts(ctx context.Context, unit string) (int, error)
+	// Close releases the backend's connection.
+	Close() error
+}
+
+// OpenSystemd connects to the system manager, or with user set the
+// manager of the user running the program. It talks D-Bus, and falls back
+// to running systemctl when the bus can't be reached.
+func OpenSystemd(user bool) (SystemdBackend, error) {
+	b, busErr := dialSystemdBus(user)
+	if busErr == nil {
+		return b, nil
+	}
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return nil, fmt.Errorf("systemd: %w, and no systemctl: %w", busErr, err)
+	}
+	return &systemctlBackend{user: user, run: runSystemctl}, nil
+}
+
+const (
+	systemdDest    = "org.freedesktop.systemd1"
+	systemdPath    = dbus.ObjectPath("/org/freedesktop/systemd1")
+	systemdManager = "org.freedesktop.systemd1.Manager"
+)
+
+// dbusBackend reads unit states over D-Bus.
+type dbusBackend struct {
+	conn *dbus.Conn
+}
+
+// dialSystemdBus connects to the system bus, or the user's session bus.
+func dialSystemdBus(user bool) (*dbusBackend, error) {
+	connect := dbus.ConnectSystemBus
+	if user {
+		connect = dbus.ConnectSessionBus
+	}
+	conn, err := connect()
+	if err != nil {
+		return nil, err
+	}
+	return &dbusBackend{conn: conn}, nil
+}
+
+func (b *dbusBackend) Units(ctx context.Context) ([]UnitStatus, error) {
+	// ListUnits returns (name, description, load state, active state, sub
+	// state, followed unit, object path, job ID, job type, job path)
+	var listed []struct {
+		Name, Description, LoadState, ActiveState, SubState, Following string
+		Path                                                           dbus.ObjectPath
+		JobID                                                          uint32
+		JobType                                                        string
+		JobPath                                                        dbus.ObjectPath
+	}
+	err := b.conn.Object(systemdDest, systemdPath).CallWithContext(ctx, systemdManager+".ListUnits", 0).Store(&listed)
+	if err != nil {
+		return nil, err
+	}
+	units := make([]UnitStatus, len(listed))
+	for i, u := range listed {
+		units[i] = UnitStatus{u.Name, u.Description, u.LoadState, u.ActiveState, u.SubState}
+	}
+	return units, nil
+}
+
+func (b *dbusBackend) Restarts(ctx context.Context, unit string) (int, error) {
+	if !strings.HasSuffix(unit, ".service") {
+		return 0, nil
+	}
+	var path dbus.ObjectPath
+	err := b.conn.Object(systemdDest, systemdPath).CallWithContext(ctx, systemdManager+".GetUnit", 0, unit).Store(&path)
+	var dbusErr dbus.Error
+	if errors.As(err, &dbusErr) && dbusErr.Name == "org.freedesktop.systemd1.NoSuchUnit" {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var v dbus.Variant
+	err = b.conn.Object(systemdDest, path).CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0,
+		"org.freedesktop.systemd1.Service", "NRestarts").Store(&v)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := v.Value().(uint32)
+	return int(n), nil
+}
+
+func (b *dbusBackend) Close() error {
+	return b.conn.Close()
+}
+
+// systemctlBackend reads unit states from systemctl's JSON output, which
+// needs systemd 246 or later.
+type systemctlBackend struct {
+	user bool
+	run  func(ctx context.Context, args ...string) ([]byte, error) // Runs systemctl
+}
+
+// runSystemctl runs systemctl and returns its output.
+func runSystemctl(ctx context.Context, args ...string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, "systemctl", args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return nil, fmt.Errorf("systemctl: %s", strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return out, err
+}
+
+// systemctl runs systemctl for the backend's manager.
+func (b *systemctlBackend) systemctl(ctx context.Context, args ...string) ([]byte, error) {
+	if b.user {
+		args = append([]string{"--user"}, args...)
+	}
+	return b.run(ctx, args...)
+}
+
+func (b *systemctlBackend) Units(ctx context.Context) ([]UnitStatus, error) {
+	out, err := b.systemctl(ctx, "list-units", "--all", "--output=json", "--no-pager")
+	if err != nil {
+		return nil, err
+	}
+	return parseSystemctlUnits(out)
+}
+
+// parseSystemctlUnits parses the output of systemctl list-units
+// --output=json.
+func parseSystemctlUnits(data []byte) ([]UnitStatus, error) {
+	var listed []struct {
+		Unit, Load, Active, Sub, Description string
+	}
+	if err := json.Unmarshal(data, &listed); err != nil {
+		return nil, fmt.Errorf("systemctl list-units: %w", err)
+	}
+	units := make([]UnitStatus, len(listed))
+	for i, u := range listed {
+		units[i] = UnitStatus{u.Unit, u.Description, u.Load, u.Active, u.Sub}
+	}
+	return units, nil
+}
+
+func (b *systemctlBackend) Restarts(ctx context.Context, unit string) (int, error) {
+	if !strings.HasSuffix(unit, ".service") {
+		return 0, nil
+	}
+	out, err := b.systemctl(ctx, "show", "--property=NRestarts", "--value", "--", unit)
+	if err != nil {
+		return 0, err
+	}
+	// Units that aren't loaded have no value
+	n, _ := strconv.Atoi(strings.TrimSpace(string(out)))
+	return n, nil
+}
+
+func (b *systemctlBackend) Close() error {
+	return nil
+}
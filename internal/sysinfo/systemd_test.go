@@ -0,0 +1,56 @@
+package sysinfo
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSystemctlBackend(t *testing.T) {
+	var calls []string
+	b := &systemctlBackend{user: true, run: func(_ context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		switch args[1] {
+		case "list-units":
+			return []byte(`[
+				{"unit": "pipewire.service", "load": "loaded", "active": "active", "sub": "running", "description": "PipeWire Multimedia Service"},
+				{"unit": "syncthing.service", "load": "loaded", "active": "failed", "sub": "failed", "description": "Syncthing"}
+			]`), nil
+		case "show":
+			return []byte("3\n"), nil
+		}
+		return nil, nil
+	}}
+
+	units, err := b.Units(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []UnitStatus{
+		{"pipewire.service", "PipeWire Multimedia Service", "loaded", "active", "running"},
+		{"syncthing.service", "Syncthing", "loaded", "failed", "failed"},
+	}
+	if !reflect.DeepEqual(units, want) {
+		t.Errorf("Units() =\n%+v\nwant\n%+v", units, want)
+	}
+
+	if n, err := b.Restarts(context.Background(), "syncthing.service"); n != 3 || err != nil {
+		t.Errorf("Restarts() = %d, %v, want 3", n, err)
+	}
+	if n, _ := b.Restarts(context.Background(), "default.target"); n != 0 {
+		t.Errorf("Restarts() of a target = %d, want 0", n)
+	}
+
+	wantCalls := []string{
+		"--user list-units --all --output=json --no-pager",
+		"--user show --property=NRestarts --value -- syncthing.service",
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("systemctl calls =\n%q\nwant\n%q", calls, wantCalls)
+	}
+
+	if _, err := parseSystemctlUnits([]byte("UNIT LOAD ACTIVE SUB")); err == nil {
+		t.Error("parseSystemctlUnits() of table output returned no error")
+	}
+}
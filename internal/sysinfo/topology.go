@@ -0,0 +1,68 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mount points read by the sysfs- and procfs-based collectors. They can be
+// pointed elsewhere, such as the host's trees mounted into a container,
+// before the collectors are used.
+var (
+	SysfsRoot  = "/sys"
+	ProcfsRoot = "/proc"
+)
+
+// CPUTopology describes where a logical CPU sits in the machine.
+type CPUTopology struct {
+	CPU     int // Logical CPU number
+	Core    int // Physical core ID within the package
+	Package int // Physical package (socket) ID
+}
+
+// GetCPUTopology returns the topology of each logical CPU.
+func GetCPUTopology() ([]CPUTopology, error) {
+	return ReadCPUTopology(SysfsRoot)
+}
+
+// ReadCPUTopology reads CPU topology from a sysfs tree rooted at sysfs,
+// ordered by logical CPU number.
+func ReadCPUTopology(sysfs string) ([]CPUTopology, error) {
+	dirs, err := filepath.Glob(filepath.Join(sysfs, "devices/system/cpu/cpu[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, os.ErrNotExist
+	}
+
+	var topo []CPUTopology
+	for _, dir := range dirs {
+		n, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "cpu"))
+		if err != nil {
+			continue
+		}
+		core, err := readInt(filepath.Join(dir, "topology/core_id"))
+		if err != nil {
+			// Offline CPUs have no topology
+			continue
+		}
+		pkg, _ := readInt(filepath.Join(dir, "topology/physical_package_id"))
+		topo = append(topo, CPUTopology{CPU: n, Core: int(core), Package: int(pkg)})
+	}
+
+	sort.Slice(topo, func(i, j int) bool { return topo[i].CPU < topo[j].CPU })
+	return topo, nil
+}
+
+// readInt reads a file containing a single integer.
+func readInt(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,55 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files under root from a map of relative path to contents.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadCPUTopology(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"devices/system/cpu/cpu0/topology/core_id":              "0\n",
+		"devices/system/cpu/cpu0/topology/physical_package_id":  "0\n",
+		"devices/system/cpu/cpu1/topology/core_id":              "0\n",
+		"devices/system/cpu/cpu1/topology/physical_package_id":  "1\n",
+		"devices/system/cpu/cpu10/topology/core_id":             "4\n",
+		"devices/system/cpu/cpu10/topology/physical_package_id": "0\n",
+		// Offline CPU without topology
+		"devices/system/cpu/cpu2/online": "0\n",
+		// Not a CPU directory
+		"devices/system/cpu/cpufreq/boost": "1\n",
+	})
+
+	got, err := ReadCPUTopology(root)
+	if err != nil {
+		t.Fatalf("ReadCPUTopology() error = %v", err)
+	}
+	want := []CPUTopology{
+		{CPU: 0, Core: 0, Package: 0},
+		{CPU: 1, Core: 0, Package: 1},
+		{CPU: 10, Core: 4, Package: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadCPUTopology() = %+v, want %+v", got, want)
+	}
+
+	if _, err := ReadCPUTopology(t.TempDir()); err == nil {
+		t.Error("ReadCPUTopology() on empty tree returned no error")
+	}
+}